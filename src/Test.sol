@@ -5,6 +5,7 @@ import "./forge/Registry.sol";
 import "./suavelib/Suave.sol";
 import "forge-std/Test.sol";
 import "./forge/ContextConnector.sol";
+import "./forge/Backend.sol";
 
 interface ConfidentialInputsWrapperI {
     function setConfidentialInputs(bytes memory) external;
@@ -20,14 +21,20 @@ contract SuaveEnabled is Test {
     ContextConnector constant ctx = ContextConnector(Suave.CONTEXT_GET);
 
     function setUp() public {
-        string[] memory inputs = new string[](2);
-        inputs[0] = "suave-geth";
-        inputs[1] = "version";
-
-        try vm.ffi(inputs) returns (bytes memory) {
-            // TODO: validate versions
-        } catch (bytes memory reason) {
-            revert(detectErrorMessage(reason));
+        // the version is only checked if the precompiles run in suave-geth (directly or traced by
+        // forge-tracer), the other backends (i.e. tools/forge-backend) do not require suave-geth
+        string memory config = ForgeBackend.foundryToml();
+        string memory binary = ForgeBackend.tracedBinary(ForgeBackend.binary(config), config);
+        if (ForgeBackend.isSuaveGeth(binary)) {
+            string[] memory inputs = new string[](2);
+            inputs[0] = binary;
+            inputs[1] = "version";
+
+            try vm.ffi(inputs) returns (bytes memory output) {
+                validateSuaveGethVersion(output);
+            } catch (bytes memory reason) {
+                revert(detectErrorMessage(reason));
+            }
         }
 
         Registry.enable();
@@ -36,6 +43,70 @@ contract SuaveEnabled is Test {
         resetConfidentialStore();
     }
 
+    // validateSuaveGethVersion checks that the installed suave-geth was built from the same commit that
+    // Suave.sol was synced from. The check is skipped if any of the commits is not known or if the
+    // SUAVE_SKIP_VERSION_CHECK environment variable is set to true.
+    function validateSuaveGethVersion(bytes memory output) internal {
+        bytes memory expected = bytes(Registry.suaveGethCommit);
+        if (expected.length == 0 || vm.envOr("SUAVE_SKIP_VERSION_CHECK", false)) {
+            return;
+        }
+
+        // 'suave-geth version' prints the commit in a 'Git Commit: <commit>' line
+        bytes memory commit = lineValue(output, "Git Commit: ");
+        if (commit.length == 0) {
+            return;
+        }
+
+        // any of the commits might be abbreviated or in upper case
+        uint256 length = commit.length < expected.length ? commit.length : expected.length;
+        for (uint256 i = 0; i < length; i++) {
+            if (toLower(commit[i]) != toLower(expected[i])) {
+                revert(
+                    string.concat(
+                        "suave-geth is built from commit ",
+                        string(commit),
+                        " but Suave.sol was synced from commit ",
+                        string(expected),
+                        ". Install the matching suave-geth or set SUAVE_SKIP_VERSION_CHECK=true to skip this check"
+                    )
+                );
+            }
+        }
+    }
+
+    // lineValue returns the rest of the first line in data that starts with prefix, or an empty value.
+    function lineValue(bytes memory data, bytes memory prefix) internal pure returns (bytes memory) {
+        uint256 start = 0;
+        while (start < data.length) {
+            uint256 end = start;
+            while (end < data.length && data[end] != "\n") {
+                end++;
+            }
+
+            bytes memory line = new bytes(end - start);
+            for (uint256 i = start; i < end; i++) {
+                line[i - start] = data[i];
+            }
+            if (isPrefix(prefix, line)) {
+                bytes memory value = new bytes(line.length - prefix.length);
+                for (uint256 i = prefix.length; i < line.length; i++) {
+                    value[i - prefix.length] = line[i];
+                }
+                return value;
+            }
+            start = end + 1;
+        }
+        return "";
+    }
+
+    function toLower(bytes1 char) internal pure returns (bytes1) {
+        if (char >= "A" && char <= "Z") {
+            return bytes1(uint8(char) + 32);
+        }
+        return char;
+    }
+
     function detectErrorMessage(bytes memory reason) internal pure returns (string memory) {
         // Errors from cheatcodes are reported as 'CheatcodeError(string)' events
         // 'eeaa9e6f' is the signature of the event. If the error is not a CheatcodeError, return the reason as is
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity ^0.8.8;
+
+interface connectorVmSafeRef {
+    struct FfiResult {
+        int32 exitCode;
+        bytes stdout;
+        bytes stderr;
+    }
+
+    function projectRoot() external view returns (string memory path);
+
+    function envOr(string calldata name, string calldata defaultValue) external view returns (string memory value);
+
+    function readFile(string calldata path) external view returns (string memory data);
+
+    function parseTomlString(string calldata toml, string calldata key) external pure returns (string memory);
+
+    function tryFfi(string[] calldata commandInput) external returns (FfiResult memory result);
+
+    function load(address target, bytes32 slot) external view returns (bytes32 data);
+
+    function store(address target, bytes32 slot, bytes32 value) external;
+
+    function toString(bytes32 value) external pure returns (string memory stringifiedValue);
+
+    function toString(uint256 value) external pure returns (string memory stringifiedValue);
+}
+
+// ForgeBackend selects the binary that runs the precompiles and manages the backend session of each test.
+library ForgeBackend {
+    connectorVmSafeRef internal constant vmSafe = connectorVmSafeRef(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+    // sessionAddr holds the id (in the slot 0) and the seed (in the slot 1) of the backend session
+    // of the running test. It is shared by the connectors of all the precompiles and reset by forge
+    // for each test.
+    address internal constant sessionAddr = address(uint160(uint256(keccak256("suave-std.forge.session"))));
+
+    // log_named_bytes32 is the forge-std log event, forge shows it in the logs of the test (with -vv).
+    event log_named_bytes32(string key, bytes32 val);
+
+    function foundryToml() internal view returns (string memory) {
+        return string.concat(vmSafe.projectRoot(), "/", "foundry.toml");
+    }
+
+    // binary returns the binary that runs the precompiles. It is suave-geth unless another
+    // binary (i.e. tools/forge-backend) is set with the SUAVE_FORGE_BACKEND environment variable
+    // or with the 'backend' key of the [profile.suave] section in foundry.toml.
+    function binary(string memory config) internal view returns (string memory) {
+        return setting(config, "SUAVE_FORGE_BACKEND", ".profile.suave.backend", "suave-geth");
+    }
+
+    // tracedBinary returns the binary that runs the precompile calls of bin. It is bin itself unless
+    // bin is tools/forge-tracer in record mode, which runs the calls with the SUAVE_TRACE_BACKEND
+    // environment variable or the 'trace_backend' key (suave-geth by default). In replay mode,
+    // forge-tracer serves the calls from the trace and it does not run any binary.
+    function tracedBinary(string memory bin, string memory config) internal view returns (string memory) {
+        if (keccak256(baseName(bin)) != keccak256("forge-tracer")) {
+            return bin;
+        }
+        string memory mode = setting(config, "SUAVE_TRACE_MODE", ".profile.suave.trace_mode", "record");
+        if (keccak256(bytes(mode)) == keccak256("replay")) {
+            return bin;
+        }
+        return setting(config, "SUAVE_TRACE_BACKEND", ".profile.suave.trace_backend", "suave-geth");
+    }
+
+    // setting returns the value of the environment variable, or of the key in foundry.toml
+    // if the variable is not set, or the default value if none of them is set.
+    function setting(string memory config, string memory env, string memory key, string memory defaultValue)
+        internal
+        view
+        returns (string memory)
+    {
+        string memory value = vmSafe.envOr(env, string(""));
+        if (bytes(value).length != 0) {
+            return value;
+        }
+
+        // foundry.toml cannot be read if it is not allowed in the fs_permissions
+        try vmSafe.readFile(config) returns (string memory data) {
+            try vmSafe.parseTomlString(data, key) returns (string memory found) {
+                if (bytes(found).length != 0) {
+                    return found;
+                }
+            } catch {}
+        } catch {}
+
+        return defaultValue;
+    }
+
+    // isSuaveGeth returns true if the binary (or the path to the binary) is suave-geth.
+    function isSuaveGeth(string memory bin) internal pure returns (bool) {
+        return keccak256(baseName(bin)) == keccak256("suave-geth");
+    }
+
+    // baseName returns the last element of the path to a binary.
+    function baseName(string memory bin) internal pure returns (bytes memory name) {
+        bytes memory path = bytes(bin);
+        uint256 start = 0;
+        for (uint256 i = 0; i < path.length; i++) {
+            if (path[i] == "/") {
+                start = i + 1;
+            }
+        }
+
+        name = new bytes(path.length - start);
+        for (uint256 i = start; i < path.length; i++) {
+            name[i - start] = path[i];
+        }
+    }
+
+    // supportsSessions returns false for suave-geth, which does not support sessions.
+    function supportsSessions(string memory bin) internal pure returns (bool) {
+        return !isSuaveGeth(bin);
+    }
+
+    // seed returns the seed of the backend session of the running test, or zero if there is no session.
+    function seed() internal view returns (bytes32) {
+        return vmSafe.load(sessionAddr, bytes32(uint256(1)));
+    }
+
+    // session returns the id of the backend session of the running test. The session is created
+    // in the first call to the backend, its seed is logged to replay the test with SUAVE_RANDOM_SEED.
+    // A session created in setUp is shared by all the runs of a fuzz test, which start from the state
+    // after setUp, so setUp must not call the precompiles that use the session (i.e. randomBytes)
+    // for the runs to be replayed.
+    function session(string memory bin, string memory config) internal returns (bytes32 id) {
+        id = vmSafe.load(sessionAddr, bytes32(0));
+        if (id != bytes32(0)) {
+            return id;
+        }
+
+        string[] memory inputs = new string[](4);
+        inputs[0] = bin;
+        inputs[1] = "session";
+        inputs[2] = "--config";
+        inputs[3] = config;
+
+        connectorVmSafeRef.FfiResult memory result = vmSafe.tryFfi(inputs);
+        if (result.exitCode != 0) {
+            revert(string.concat("Failed to create the backend session: ", string(result.stderr)));
+        }
+
+        bytes32 sessionSeed;
+        (id, sessionSeed) = abi.decode(result.stdout, (bytes32, bytes32));
+        vmSafe.store(sessionAddr, bytes32(0), id);
+        vmSafe.store(sessionAddr, bytes32(uint256(1)), sessionSeed);
+
+        emit log_named_bytes32("Backend session seed", sessionSeed);
+    }
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity ^0.8.8;
+
+import "./Backend.sol";
+
+// Clock controls the time (in milliseconds) returned by Suave.getInsecureTime in the running test,
+// like vm.warp does for block.timestamp. It requires the forge-backend (tools/forge-backend).
+// The clock follows the wall time until it is set, frozen or advanced, and it is reset for each test.
+library Clock {
+    connectorVmSafeRef private constant vmSafe = connectorVmSafeRef(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+    // set sets the time of the clock. The clock keeps running (unless frozen) from the new time.
+    function set(uint256 time) internal returns (uint256) {
+        return run("set", vmSafe.toString(time));
+    }
+
+    // advance moves the clock forward by the duration (in milliseconds).
+    function advance(uint256 duration) internal returns (uint256) {
+        return run("advance", vmSafe.toString(duration));
+    }
+
+    // freeze stops the clock at its current time.
+    function freeze() internal returns (uint256) {
+        return run("freeze", "");
+    }
+
+    // unfreeze restarts the clock from the time it was frozen at.
+    function unfreeze() internal returns (uint256) {
+        return run("unfreeze", "");
+    }
+
+    // reset makes the clock follow the wall time again.
+    function reset() internal returns (uint256) {
+        return run("reset", "");
+    }
+
+    // run updates the clock of the backend session and returns the new time of the clock.
+    function run(string memory op, string memory arg) private returns (uint256) {
+        string memory foundryToml = ForgeBackend.foundryToml();
+        string memory binary = ForgeBackend.binary(foundryToml);
+        require(
+            ForgeBackend.supportsSessions(binary),
+            "Clock requires the forge-backend, set it with SUAVE_FORGE_BACKEND or in foundry.toml"
+        );
+
+        string[] memory inputs = new string[](bytes(arg).length == 0 ? 7 : 8);
+        inputs[0] = binary;
+        inputs[1] = "clock";
+        inputs[2] = "--config";
+        inputs[3] = foundryToml;
+        inputs[4] = "--session";
+        inputs[5] = vmSafe.toString(ForgeBackend.session(binary, foundryToml));
+        inputs[6] = op;
+        if (bytes(arg).length != 0) {
+            inputs[7] = arg;
+        }
+
+        connectorVmSafeRef.FfiResult memory result = vmSafe.tryFfi(inputs);
+        if (result.exitCode != 0) {
+            revert(string.concat("Clock: ", string(result.stderr)));
+        }
+        return abi.decode(result.stdout, (uint256));
+    }
+}
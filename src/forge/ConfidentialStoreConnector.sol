@@ -1,9 +1,11 @@
 // SPDX-License-Identifier: UNLICENSED
+// DO NOT edit this file. Code generated by forge-gen.
 pragma solidity ^0.8.8;
 
-import "./ConfidentialStore.sol";
 import "../suavelib/Suave.sol";
 
+// ConfidentialStoreConnector forwards the calls to the confidential store precompiles
+// to the ConfidentialStore contract deployed by the Registry.
 contract ConfidentialStoreConnector {
     fallback() external {
         address confidentialStoreAddr = 0x0101010101010101010101010101010101010101;
@@ -11,20 +13,23 @@ contract ConfidentialStoreConnector {
         address addr = address(this);
         bytes memory input;
 
-        if (addr == Suave.CONFIDENTIAL_STORE) {
-            (Suave.DataId dataId, string memory key, bytes memory val) =
-                abi.decode(msg.data, (Suave.DataId, string, bytes));
-
-            input =
-                abi.encodeWithSignature("confidentialStore(bytes16,string,bytes,address)", dataId, key, val, msg.sender);
-        } else if (addr == Suave.CONFIDENTIAL_RETRIEVE) {
+        if (addr == Suave.CONFIDENTIAL_RETRIEVE) {
+            bytes4 selector = bytes4(keccak256("confidentialRetrieve(bytes16,string,address)"));
             (Suave.DataId dataId, string memory key) = abi.decode(msg.data, (Suave.DataId, string));
 
-            input = abi.encodeWithSignature("confidentialRetrieve(bytes16,string,address)", dataId, key, msg.sender);
+            input = abi.encodeWithSelector(selector, dataId, key, msg.sender);
+        } else if (addr == Suave.CONFIDENTIAL_STORE) {
+            bytes4 selector = bytes4(keccak256("confidentialStore(bytes16,string,bytes,address)"));
+            (Suave.DataId dataId, string memory key, bytes memory value) =
+                abi.decode(msg.data, (Suave.DataId, string, bytes));
+
+            input = abi.encodeWithSelector(selector, dataId, key, value, msg.sender);
         } else if (addr == Suave.FETCH_DATA_RECORDS) {
-            input = abi.encodePacked(ConfidentialStore.fetchDataRecords.selector, msg.data);
+            bytes4 selector = bytes4(keccak256("fetchDataRecords(uint64,string)"));
+            input = abi.encodePacked(selector, msg.data);
         } else if (addr == Suave.NEW_DATA_RECORD) {
-            input = abi.encodePacked(ConfidentialStore.newDataRecord.selector, msg.data);
+            bytes4 selector = bytes4(keccak256("newDataRecord(uint64,address[],address[],string)"));
+            input = abi.encodePacked(selector, msg.data);
         } else {
             revert("function signature not found in the confidential store");
         }
@@ -35,9 +40,7 @@ contract ConfidentialStoreConnector {
         }
 
         if (addr == Suave.CONFIDENTIAL_RETRIEVE) {
-            // special case we have to unroll the value from the abi
-            // since it comes encoded as tuple() but we return the value normally
-            // this was a special case that was not fixed yet in suave-geth.
+            // the precompile returns the raw output but the method returns it abi encoded
             output = abi.decode(output, (bytes));
         }
 
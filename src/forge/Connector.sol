@@ -2,37 +2,32 @@
 pragma solidity ^0.8.8;
 
 import "forge-std/Test.sol";
-
-interface connectorVmSafeRef {
-    struct FfiResult {
-        int32 exitCode;
-        bytes stdout;
-        bytes stderr;
-    }
-
-    function projectRoot() external view returns (string memory path);
-
-    function tryFfi(string[] calldata commandInput) external returns (FfiResult memory result);
-}
+import "./Backend.sol";
 
 contract Connector is Test {
     connectorVmSafeRef internal constant vmSafe = connectorVmSafeRef(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
 
     function forgeIt(bytes memory addr, bytes memory data) internal returns (bytes memory) {
-        string memory root = vmSafe.projectRoot();
-        string memory foundryToml = string.concat(root, "/", "foundry.toml");
+        string memory foundryToml = ForgeBackend.foundryToml();
 
         string memory addrHex = iToHex(addr);
         string memory dataHex = iToHex(data);
 
-        string[] memory inputs = new string[](7);
-        inputs[0] = "suave-geth";
+        string memory binary = ForgeBackend.binary(foundryToml);
+        bool withSession = ForgeBackend.supportsSessions(binary);
+
+        string[] memory inputs = new string[](withSession ? 9 : 7);
+        inputs[0] = binary;
         inputs[1] = "forge";
         inputs[2] = "--local";
         inputs[3] = "--config";
         inputs[4] = foundryToml;
-        inputs[5] = addrHex;
-        inputs[6] = dataHex;
+        if (withSession) {
+            inputs[5] = "--session";
+            inputs[6] = vmSafe.toString(ForgeBackend.session(binary, foundryToml));
+        }
+        inputs[inputs.length - 2] = addrHex;
+        inputs[inputs.length - 1] = dataHex;
 
         connectorVmSafeRef.FfiResult memory result = vmSafe.tryFfi(inputs);
         if (result.exitCode == 0) {
@@ -40,6 +35,9 @@ contract Connector is Test {
         }
 
         console.log(string.concat("Precompile reverted: ", string(result.stderr)));
+        if (withSession) {
+            console.log(string.concat("Backend session seed: ", vmSafe.toString(ForgeBackend.seed())));
+        }
         revert(string(result.stderr));
     }
 
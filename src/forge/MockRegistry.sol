@@ -0,0 +1,729 @@
+// SPDX-License-Identifier: UNLICENSED
+// DO NOT edit this file. Code generated by forge-gen.
+pragma solidity ^0.8.8;
+
+import "../suavelib/Suave.sol";
+import "./PrecompileMock.sol";
+
+interface mockRegistryVM {
+    function etch(address, bytes calldata) external;
+}
+
+// MockRegistry replaces each precompile with a mock that returns the responses
+// programmed by the test. Unlike Registry, it does not require suave-geth or ffi.
+library MockRegistry {
+    mockRegistryVM constant vm = mockRegistryVM(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+    function enable() public {
+        vm.etch(Suave.IS_CONFIDENTIAL_ADDR, type(IsConfidentialMock).runtimeCode);
+        vm.etch(Suave.AES_DECRYPT, type(AesDecryptMock).runtimeCode);
+        vm.etch(Suave.AES_ENCRYPT, type(AesEncryptMock).runtimeCode);
+        vm.etch(Suave.BUILD_ETH_BLOCK, type(BuildEthBlockMock).runtimeCode);
+        vm.etch(Suave.BUILD_ETH_BLOCK_TO, type(BuildEthBlockToMock).runtimeCode);
+        vm.etch(Suave.CONFIDENTIAL_RETRIEVE, type(ConfidentialRetrieveMock).runtimeCode);
+        vm.etch(Suave.CONFIDENTIAL_STORE, type(ConfidentialStoreMock).runtimeCode);
+        vm.etch(Suave.CONTEXT_GET, type(ContextGetMock).runtimeCode);
+        vm.etch(Suave.DO_HTTPREQUEST, type(DoHTTPRequestMock).runtimeCode);
+        vm.etch(Suave.DO_HTTPREQUEST2, type(DoHTTPRequest2Mock).runtimeCode);
+        vm.etch(Suave.ETHCALL, type(EthcallMock).runtimeCode);
+        vm.etch(Suave.EXTRACT_HINT, type(ExtractHintMock).runtimeCode);
+        vm.etch(Suave.FETCH_DATA_RECORDS, type(FetchDataRecordsMock).runtimeCode);
+        vm.etch(Suave.FILL_MEV_SHARE_BUNDLE, type(FillMevShareBundleMock).runtimeCode);
+        vm.etch(Suave.GET_INSECURE_TIME, type(GetInsecureTimeMock).runtimeCode);
+        vm.etch(Suave.NEW_BUILDER, type(NewBuilderMock).runtimeCode);
+        vm.etch(Suave.NEW_DATA_RECORD, type(NewDataRecordMock).runtimeCode);
+        vm.etch(Suave.PRIVATE_KEY_GEN, type(PrivateKeyGenMock).runtimeCode);
+        vm.etch(Suave.RANDOM_BYTES, type(RandomBytesMock).runtimeCode);
+        vm.etch(Suave.SIGN_ETH_TRANSACTION, type(SignEthTransactionMock).runtimeCode);
+        vm.etch(Suave.SIGN_MESSAGE, type(SignMessageMock).runtimeCode);
+        vm.etch(Suave.SIMULATE_BUNDLE, type(SimulateBundleMock).runtimeCode);
+        vm.etch(Suave.SIMULATE_TRANSACTION, type(SimulateTransactionMock).runtimeCode);
+        vm.etch(Suave.SUBMIT_BUNDLE_JSON_RPC, type(SubmitBundleJsonRPCMock).runtimeCode);
+        vm.etch(Suave.SUBMIT_ETH_BLOCK_TO_RELAY, type(SubmitEthBlockToRelayMock).runtimeCode);
+        isConfidential().mockReturn(true);
+    }
+
+    function isConfidential() internal pure returns (IsConfidentialMock) {
+        return IsConfidentialMock(Suave.IS_CONFIDENTIAL_ADDR);
+    }
+
+    function aesDecrypt() internal pure returns (AesDecryptMock) {
+        return AesDecryptMock(Suave.AES_DECRYPT);
+    }
+
+    function aesEncrypt() internal pure returns (AesEncryptMock) {
+        return AesEncryptMock(Suave.AES_ENCRYPT);
+    }
+
+    function buildEthBlock() internal pure returns (BuildEthBlockMock) {
+        return BuildEthBlockMock(Suave.BUILD_ETH_BLOCK);
+    }
+
+    function buildEthBlockTo() internal pure returns (BuildEthBlockToMock) {
+        return BuildEthBlockToMock(Suave.BUILD_ETH_BLOCK_TO);
+    }
+
+    function confidentialRetrieve() internal pure returns (ConfidentialRetrieveMock) {
+        return ConfidentialRetrieveMock(Suave.CONFIDENTIAL_RETRIEVE);
+    }
+
+    function confidentialStore() internal pure returns (ConfidentialStoreMock) {
+        return ConfidentialStoreMock(Suave.CONFIDENTIAL_STORE);
+    }
+
+    function contextGet() internal pure returns (ContextGetMock) {
+        return ContextGetMock(Suave.CONTEXT_GET);
+    }
+
+    function doHTTPRequest() internal pure returns (DoHTTPRequestMock) {
+        return DoHTTPRequestMock(Suave.DO_HTTPREQUEST);
+    }
+
+    function doHTTPRequest2() internal pure returns (DoHTTPRequest2Mock) {
+        return DoHTTPRequest2Mock(Suave.DO_HTTPREQUEST2);
+    }
+
+    function ethcall() internal pure returns (EthcallMock) {
+        return EthcallMock(Suave.ETHCALL);
+    }
+
+    function extractHint() internal pure returns (ExtractHintMock) {
+        return ExtractHintMock(Suave.EXTRACT_HINT);
+    }
+
+    function fetchDataRecords() internal pure returns (FetchDataRecordsMock) {
+        return FetchDataRecordsMock(Suave.FETCH_DATA_RECORDS);
+    }
+
+    function fillMevShareBundle() internal pure returns (FillMevShareBundleMock) {
+        return FillMevShareBundleMock(Suave.FILL_MEV_SHARE_BUNDLE);
+    }
+
+    function getInsecureTime() internal pure returns (GetInsecureTimeMock) {
+        return GetInsecureTimeMock(Suave.GET_INSECURE_TIME);
+    }
+
+    function newBuilder() internal pure returns (NewBuilderMock) {
+        return NewBuilderMock(Suave.NEW_BUILDER);
+    }
+
+    function newDataRecord() internal pure returns (NewDataRecordMock) {
+        return NewDataRecordMock(Suave.NEW_DATA_RECORD);
+    }
+
+    function privateKeyGen() internal pure returns (PrivateKeyGenMock) {
+        return PrivateKeyGenMock(Suave.PRIVATE_KEY_GEN);
+    }
+
+    function randomBytes() internal pure returns (RandomBytesMock) {
+        return RandomBytesMock(Suave.RANDOM_BYTES);
+    }
+
+    function signEthTransaction() internal pure returns (SignEthTransactionMock) {
+        return SignEthTransactionMock(Suave.SIGN_ETH_TRANSACTION);
+    }
+
+    function signMessage() internal pure returns (SignMessageMock) {
+        return SignMessageMock(Suave.SIGN_MESSAGE);
+    }
+
+    function simulateBundle() internal pure returns (SimulateBundleMock) {
+        return SimulateBundleMock(Suave.SIMULATE_BUNDLE);
+    }
+
+    function simulateTransaction() internal pure returns (SimulateTransactionMock) {
+        return SimulateTransactionMock(Suave.SIMULATE_TRANSACTION);
+    }
+
+    function submitBundleJsonRPC() internal pure returns (SubmitBundleJsonRPCMock) {
+        return SubmitBundleJsonRPCMock(Suave.SUBMIT_BUNDLE_JSON_RPC);
+    }
+
+    function submitEthBlockToRelay() internal pure returns (SubmitEthBlockToRelayMock) {
+        return SubmitEthBlockToRelayMock(Suave.SUBMIT_ETH_BLOCK_TO_RELAY);
+    }
+}
+
+contract IsConfidentialMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "IS_CONFIDENTIAL_ADDR";
+    }
+
+    function mockReturn(bool b) public {
+        setDefaultResponse(abi.encode(b));
+    }
+
+    function mockReturnAt(uint256 callIndex, bool b) public {
+        setResponse(callIndex, abi.encode(b));
+    }
+
+    function expectCall() public {
+        addExpectedCall(abi.encode());
+    }
+}
+
+contract AesDecryptMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "AES_DECRYPT";
+    }
+
+    function mockReturn(bytes memory message) public {
+        setDefaultResponse(abi.encode(message));
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory message) public {
+        setResponse(callIndex, abi.encode(message));
+    }
+
+    function expectCall(bytes memory key, bytes memory ciphertext) public {
+        addExpectedCall(abi.encode(key, ciphertext));
+    }
+
+    function callAt(uint256 callIndex) public view returns (bytes memory key, bytes memory ciphertext) {
+        (key, ciphertext) = abi.decode(inputAt(callIndex), (bytes, bytes));
+    }
+}
+
+contract AesEncryptMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "AES_ENCRYPT";
+    }
+
+    function mockReturn(bytes memory ciphertext) public {
+        setDefaultResponse(abi.encode(ciphertext));
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory ciphertext) public {
+        setResponse(callIndex, abi.encode(ciphertext));
+    }
+
+    function expectCall(bytes memory key, bytes memory message) public {
+        addExpectedCall(abi.encode(key, message));
+    }
+
+    function callAt(uint256 callIndex) public view returns (bytes memory key, bytes memory message) {
+        (key, message) = abi.decode(inputAt(callIndex), (bytes, bytes));
+    }
+}
+
+contract BuildEthBlockMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "BUILD_ETH_BLOCK";
+    }
+
+    function mockReturn(bytes memory blockBid, bytes memory executionPayload) public {
+        setDefaultResponse(abi.encode(blockBid, executionPayload));
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory blockBid, bytes memory executionPayload) public {
+        setResponse(callIndex, abi.encode(blockBid, executionPayload));
+    }
+
+    function expectCall(Suave.BuildBlockArgs memory blockArgs, Suave.DataId dataId, string memory relayUrl) public {
+        addExpectedCall(abi.encode(blockArgs, dataId, relayUrl));
+    }
+
+    function callAt(uint256 callIndex)
+        public
+        view
+        returns (Suave.BuildBlockArgs memory blockArgs, Suave.DataId dataId, string memory relayUrl)
+    {
+        (blockArgs, dataId, relayUrl) = abi.decode(inputAt(callIndex), (Suave.BuildBlockArgs, Suave.DataId, string));
+    }
+}
+
+contract BuildEthBlockToMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "BUILD_ETH_BLOCK_TO";
+    }
+
+    function mockReturn(bytes memory blockBid, bytes memory executionPayload) public {
+        setDefaultResponse(abi.encode(blockBid, executionPayload));
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory blockBid, bytes memory executionPayload) public {
+        setResponse(callIndex, abi.encode(blockBid, executionPayload));
+    }
+
+    function expectCall(
+        string memory executionNodeURL,
+        Suave.BuildBlockArgs memory blockArgs,
+        Suave.DataId dataId,
+        string memory relayUrl
+    ) public {
+        addExpectedCall(abi.encode(executionNodeURL, blockArgs, dataId, relayUrl));
+    }
+
+    function callAt(uint256 callIndex)
+        public
+        view
+        returns (
+            string memory executionNodeURL,
+            Suave.BuildBlockArgs memory blockArgs,
+            Suave.DataId dataId,
+            string memory relayUrl
+        )
+    {
+        (executionNodeURL, blockArgs, dataId, relayUrl) =
+            abi.decode(inputAt(callIndex), (string, Suave.BuildBlockArgs, Suave.DataId, string));
+    }
+}
+
+contract ConfidentialRetrieveMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "CONFIDENTIAL_RETRIEVE";
+    }
+
+    function mockReturn(bytes memory value) public {
+        setDefaultResponse(value);
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory value) public {
+        setResponse(callIndex, value);
+    }
+
+    function expectCall(Suave.DataId dataId, string memory key) public {
+        addExpectedCall(abi.encode(dataId, key));
+    }
+
+    function callAt(uint256 callIndex) public view returns (Suave.DataId dataId, string memory key) {
+        (dataId, key) = abi.decode(inputAt(callIndex), (Suave.DataId, string));
+    }
+}
+
+contract ConfidentialStoreMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "CONFIDENTIAL_STORE";
+    }
+
+    function hasOutputs() internal pure override returns (bool) {
+        return false;
+    }
+
+    function expectCall(Suave.DataId dataId, string memory key, bytes memory value) public {
+        addExpectedCall(abi.encode(dataId, key, value));
+    }
+
+    function callAt(uint256 callIndex)
+        public
+        view
+        returns (Suave.DataId dataId, string memory key, bytes memory value)
+    {
+        (dataId, key, value) = abi.decode(inputAt(callIndex), (Suave.DataId, string, bytes));
+    }
+}
+
+contract ContextGetMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "CONTEXT_GET";
+    }
+
+    function mockReturn(bytes memory value) public {
+        setDefaultResponse(abi.encode(value));
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory value) public {
+        setResponse(callIndex, abi.encode(value));
+    }
+
+    function expectCall(string memory key) public {
+        addExpectedCall(abi.encode(key));
+    }
+
+    function callAt(uint256 callIndex) public view returns (string memory key) {
+        (key) = abi.decode(inputAt(callIndex), (string));
+    }
+}
+
+contract DoHTTPRequestMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "DO_HTTPREQUEST";
+    }
+
+    function mockReturn(bytes memory httpResponse) public {
+        setDefaultResponse(abi.encode(httpResponse));
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory httpResponse) public {
+        setResponse(callIndex, abi.encode(httpResponse));
+    }
+
+    function expectCall(Suave.HttpRequest memory request) public {
+        addExpectedCall(abi.encode(request));
+    }
+
+    function callAt(uint256 callIndex) public view returns (Suave.HttpRequest memory request) {
+        (request) = abi.decode(inputAt(callIndex), (Suave.HttpRequest));
+    }
+}
+
+contract DoHTTPRequest2Mock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "DO_HTTPREQUEST2";
+    }
+
+    function mockReturn(Suave.HttpResponse memory httpResponse) public {
+        setDefaultResponse(abi.encode(httpResponse));
+    }
+
+    function mockReturnAt(uint256 callIndex, Suave.HttpResponse memory httpResponse) public {
+        setResponse(callIndex, abi.encode(httpResponse));
+    }
+
+    function expectCall(Suave.HttpRequest memory request) public {
+        addExpectedCall(abi.encode(request));
+    }
+
+    function callAt(uint256 callIndex) public view returns (Suave.HttpRequest memory request) {
+        (request) = abi.decode(inputAt(callIndex), (Suave.HttpRequest));
+    }
+}
+
+contract EthcallMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "ETHCALL";
+    }
+
+    function mockReturn(bytes memory callOutput) public {
+        setDefaultResponse(abi.encode(callOutput));
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory callOutput) public {
+        setResponse(callIndex, abi.encode(callOutput));
+    }
+
+    function expectCall(address contractAddr, bytes memory input1) public {
+        addExpectedCall(abi.encode(contractAddr, input1));
+    }
+
+    function callAt(uint256 callIndex) public view returns (address contractAddr, bytes memory input1) {
+        (contractAddr, input1) = abi.decode(inputAt(callIndex), (address, bytes));
+    }
+}
+
+contract ExtractHintMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "EXTRACT_HINT";
+    }
+
+    function mockReturn(bytes memory hints) public {
+        setDefaultResponse(hints);
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory hints) public {
+        setResponse(callIndex, hints);
+    }
+
+    function expectCall(bytes memory bundleData) public {
+        addExpectedCall(abi.encode(bundleData));
+    }
+
+    function callAt(uint256 callIndex) public view returns (bytes memory bundleData) {
+        (bundleData) = abi.decode(inputAt(callIndex), (bytes));
+    }
+}
+
+contract FetchDataRecordsMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "FETCH_DATA_RECORDS";
+    }
+
+    function mockReturn(Suave.DataRecord[] memory dataRecords) public {
+        setDefaultResponse(abi.encode(dataRecords));
+    }
+
+    function mockReturnAt(uint256 callIndex, Suave.DataRecord[] memory dataRecords) public {
+        setResponse(callIndex, abi.encode(dataRecords));
+    }
+
+    function expectCall(uint64 cond, string memory namespace) public {
+        addExpectedCall(abi.encode(cond, namespace));
+    }
+
+    function callAt(uint256 callIndex) public view returns (uint64 cond, string memory namespace) {
+        (cond, namespace) = abi.decode(inputAt(callIndex), (uint64, string));
+    }
+}
+
+contract FillMevShareBundleMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "FILL_MEV_SHARE_BUNDLE";
+    }
+
+    function mockReturn(bytes memory encodedBundle) public {
+        setDefaultResponse(encodedBundle);
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory encodedBundle) public {
+        setResponse(callIndex, encodedBundle);
+    }
+
+    function expectCall(Suave.DataId dataId) public {
+        addExpectedCall(abi.encode(dataId));
+    }
+
+    function callAt(uint256 callIndex) public view returns (Suave.DataId dataId) {
+        (dataId) = abi.decode(inputAt(callIndex), (Suave.DataId));
+    }
+}
+
+contract GetInsecureTimeMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "GET_INSECURE_TIME";
+    }
+
+    function mockReturn(uint256 time) public {
+        setDefaultResponse(abi.encode(time));
+    }
+
+    function mockReturnAt(uint256 callIndex, uint256 time) public {
+        setResponse(callIndex, abi.encode(time));
+    }
+
+    function expectCall() public {
+        addExpectedCall(abi.encode());
+    }
+}
+
+contract NewBuilderMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "NEW_BUILDER";
+    }
+
+    function mockReturn(string memory sessionid) public {
+        setDefaultResponse(abi.encode(sessionid));
+    }
+
+    function mockReturnAt(uint256 callIndex, string memory sessionid) public {
+        setResponse(callIndex, abi.encode(sessionid));
+    }
+
+    function expectCall() public {
+        addExpectedCall(abi.encode());
+    }
+}
+
+contract NewDataRecordMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "NEW_DATA_RECORD";
+    }
+
+    function mockReturn(Suave.DataRecord memory dataRecord) public {
+        setDefaultResponse(abi.encode(dataRecord));
+    }
+
+    function mockReturnAt(uint256 callIndex, Suave.DataRecord memory dataRecord) public {
+        setResponse(callIndex, abi.encode(dataRecord));
+    }
+
+    function expectCall(
+        uint64 decryptionCondition,
+        address[] memory allowedPeekers,
+        address[] memory allowedStores,
+        string memory dataType
+    ) public {
+        addExpectedCall(abi.encode(decryptionCondition, allowedPeekers, allowedStores, dataType));
+    }
+
+    function callAt(uint256 callIndex)
+        public
+        view
+        returns (
+            uint64 decryptionCondition,
+            address[] memory allowedPeekers,
+            address[] memory allowedStores,
+            string memory dataType
+        )
+    {
+        (decryptionCondition, allowedPeekers, allowedStores, dataType) =
+            abi.decode(inputAt(callIndex), (uint64, address[], address[], string));
+    }
+}
+
+contract PrivateKeyGenMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "PRIVATE_KEY_GEN";
+    }
+
+    function mockReturn(string memory privateKey) public {
+        setDefaultResponse(abi.encode(privateKey));
+    }
+
+    function mockReturnAt(uint256 callIndex, string memory privateKey) public {
+        setResponse(callIndex, abi.encode(privateKey));
+    }
+
+    function expectCall(Suave.CryptoSignature crypto) public {
+        addExpectedCall(abi.encode(crypto));
+    }
+
+    function callAt(uint256 callIndex) public view returns (Suave.CryptoSignature crypto) {
+        (crypto) = abi.decode(inputAt(callIndex), (Suave.CryptoSignature));
+    }
+}
+
+contract RandomBytesMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "RANDOM_BYTES";
+    }
+
+    function mockReturn(bytes memory value) public {
+        setDefaultResponse(abi.encode(value));
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory value) public {
+        setResponse(callIndex, abi.encode(value));
+    }
+
+    function expectCall(uint8 numBytes) public {
+        addExpectedCall(abi.encode(numBytes));
+    }
+
+    function callAt(uint256 callIndex) public view returns (uint8 numBytes) {
+        (numBytes) = abi.decode(inputAt(callIndex), (uint8));
+    }
+}
+
+contract SignEthTransactionMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "SIGN_ETH_TRANSACTION";
+    }
+
+    function mockReturn(bytes memory signedTxn) public {
+        setDefaultResponse(abi.encode(signedTxn));
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory signedTxn) public {
+        setResponse(callIndex, abi.encode(signedTxn));
+    }
+
+    function expectCall(bytes memory txn, string memory chainId, string memory signingKey) public {
+        addExpectedCall(abi.encode(txn, chainId, signingKey));
+    }
+
+    function callAt(uint256 callIndex)
+        public
+        view
+        returns (bytes memory txn, string memory chainId, string memory signingKey)
+    {
+        (txn, chainId, signingKey) = abi.decode(inputAt(callIndex), (bytes, string, string));
+    }
+}
+
+contract SignMessageMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "SIGN_MESSAGE";
+    }
+
+    function mockReturn(bytes memory signature) public {
+        setDefaultResponse(abi.encode(signature));
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory signature) public {
+        setResponse(callIndex, abi.encode(signature));
+    }
+
+    function expectCall(bytes memory digest, Suave.CryptoSignature crypto, string memory signingKey) public {
+        addExpectedCall(abi.encode(digest, crypto, signingKey));
+    }
+
+    function callAt(uint256 callIndex)
+        public
+        view
+        returns (bytes memory digest, Suave.CryptoSignature crypto, string memory signingKey)
+    {
+        (digest, crypto, signingKey) = abi.decode(inputAt(callIndex), (bytes, Suave.CryptoSignature, string));
+    }
+}
+
+contract SimulateBundleMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "SIMULATE_BUNDLE";
+    }
+
+    function mockReturn(uint64 effectiveGasPrice) public {
+        setDefaultResponse(abi.encode(effectiveGasPrice));
+    }
+
+    function mockReturnAt(uint256 callIndex, uint64 effectiveGasPrice) public {
+        setResponse(callIndex, abi.encode(effectiveGasPrice));
+    }
+
+    function expectCall(bytes memory bundleData) public {
+        addExpectedCall(abi.encode(bundleData));
+    }
+
+    function callAt(uint256 callIndex) public view returns (bytes memory bundleData) {
+        (bundleData) = abi.decode(inputAt(callIndex), (bytes));
+    }
+}
+
+contract SimulateTransactionMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "SIMULATE_TRANSACTION";
+    }
+
+    function mockReturn(Suave.SimulateTransactionResult memory simulationResult) public {
+        setDefaultResponse(abi.encode(simulationResult));
+    }
+
+    function mockReturnAt(uint256 callIndex, Suave.SimulateTransactionResult memory simulationResult) public {
+        setResponse(callIndex, abi.encode(simulationResult));
+    }
+
+    function expectCall(string memory sessionid, bytes memory txn) public {
+        addExpectedCall(abi.encode(sessionid, txn));
+    }
+
+    function callAt(uint256 callIndex) public view returns (string memory sessionid, bytes memory txn) {
+        (sessionid, txn) = abi.decode(inputAt(callIndex), (string, bytes));
+    }
+}
+
+contract SubmitBundleJsonRPCMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "SUBMIT_BUNDLE_JSON_RPC";
+    }
+
+    function mockReturn(bytes memory errorMessage) public {
+        setDefaultResponse(errorMessage);
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory errorMessage) public {
+        setResponse(callIndex, errorMessage);
+    }
+
+    function expectCall(string memory url, string memory method, bytes memory params) public {
+        addExpectedCall(abi.encode(url, method, params));
+    }
+
+    function callAt(uint256 callIndex)
+        public
+        view
+        returns (string memory url, string memory method, bytes memory params)
+    {
+        (url, method, params) = abi.decode(inputAt(callIndex), (string, string, bytes));
+    }
+}
+
+contract SubmitEthBlockToRelayMock is PrecompileMock {
+    function precompileName() internal pure override returns (string memory) {
+        return "SUBMIT_ETH_BLOCK_TO_RELAY";
+    }
+
+    function mockReturn(bytes memory blockBid) public {
+        setDefaultResponse(blockBid);
+    }
+
+    function mockReturnAt(uint256 callIndex, bytes memory blockBid) public {
+        setResponse(callIndex, blockBid);
+    }
+
+    function expectCall(string memory relayUrl, bytes memory builderBid) public {
+        addExpectedCall(abi.encode(relayUrl, builderBid));
+    }
+
+    function callAt(uint256 callIndex) public view returns (string memory relayUrl, bytes memory builderBid) {
+        (relayUrl, builderBid) = abi.decode(inputAt(callIndex), (string, bytes));
+    }
+}
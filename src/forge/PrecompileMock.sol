@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity ^0.8.8;
+
+interface precompileMockVM {
+    function toString(uint256) external pure returns (string memory);
+}
+
+// PrecompileMock is the base contract of the precompile mocks generated in MockRegistry.sol.
+// Instead of calling suave-geth, it records the inputs of each call and returns the
+// response programmed by the test.
+abstract contract PrecompileMock {
+    precompileMockVM internal constant mockVm = precompileMockVM(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+    struct Response {
+        bool set;
+        bool reverts;
+        bytes data;
+    }
+
+    struct Expectation {
+        bool set;
+        bytes input;
+    }
+
+    // number of calls made to the precompile
+    uint256 public callCount;
+
+    // inputs of each call made to the precompile
+    bytes[] internal callInputs;
+
+    // expected inputs of a specific call (starting at 1)
+    mapping(uint256 => Expectation) internal expectations;
+
+    // last call with expected inputs
+    uint256 internal lastExpectedCall;
+
+    // response of any call without a specific response
+    Response internal defaultResponse;
+
+    // response of a specific call (starting at 1)
+    mapping(uint256 => Response) internal responses;
+
+    // name of the precompile in Suave.sol
+    function precompileName() internal pure virtual returns (string memory);
+
+    // whether the precompile has any output. If it does not, the calls
+    // without a programmed response succeed with an empty output.
+    function hasOutputs() internal pure virtual returns (bool) {
+        return true;
+    }
+
+    function mockRevert(bytes memory reason) public {
+        defaultResponse = Response(true, true, reason);
+    }
+
+    function mockRevertAt(uint256 callIndex, bytes memory reason) public {
+        responses[callIndex] = Response(true, true, reason);
+    }
+
+    function setDefaultResponse(bytes memory output) internal {
+        defaultResponse = Response(true, false, output);
+    }
+
+    function setResponse(uint256 callIndex, bytes memory output) internal {
+        responses[callIndex] = Response(true, false, output);
+    }
+
+    // addExpectedCall sets the expected inputs of the next call, or of the call after
+    // the last one with expected inputs if there are expected calls not made yet.
+    function addExpectedCall(bytes memory input) internal {
+        uint256 callIndex = (lastExpectedCall > callCount ? lastExpectedCall : callCount) + 1;
+        expectations[callIndex] = Expectation(true, input);
+        lastExpectedCall = callIndex;
+    }
+
+    function inputAt(uint256 callIndex) internal view returns (bytes memory) {
+        require(callIndex > 0 && callIndex <= callCount, failMessage("call not found", callIndex));
+        return callInputs[callIndex - 1];
+    }
+
+    function failMessage(string memory message, uint256 callIndex) internal pure returns (string memory) {
+        return string.concat(precompileName(), ": ", message, " (call ", mockVm.toString(callIndex), ")");
+    }
+
+    fallback() external {
+        callCount++;
+        callInputs.push(msg.data);
+
+        Expectation memory expectation = expectations[callCount];
+        if (expectation.set && keccak256(msg.data) != keccak256(expectation.input)) {
+            revert(failMessage("unexpected inputs", callCount));
+        }
+
+        Response memory response = responses[callCount];
+        if (!response.set) {
+            response = defaultResponse;
+        }
+        if (!response.set && hasOutputs()) {
+            revert(failMessage("no response programmed", callCount));
+        }
+
+        bytes memory output = response.data;
+        if (response.reverts) {
+            assembly {
+                revert(add(output, 0x20), mload(output))
+            }
+        }
+        assembly {
+            return(add(output, 0x20), mload(output))
+        }
+    }
+}
@@ -3,11 +3,10 @@
 pragma solidity ^0.8.8;
 
 import "../suavelib/Suave.sol";
-import "./Connector.sol";
-import "./ContextConnector.sol";
-import "./SuaveAddrs.sol";
 import "./ConfidentialStore.sol";
 import "./ConfidentialStoreConnector.sol";
+import "./Connector.sol";
+import "./ContextConnector.sol";
 
 interface registryVM {
     function etch(address, bytes calldata) external;
@@ -17,25 +16,41 @@ library Registry {
     registryVM constant vm = registryVM(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
     address public constant confidentialStoreAddr = 0x0101010101010101010101010101010101010101;
 
-    function enable() public {
-        // enable all suave libraries
-        address[] memory addrList = SuaveAddrs.getSuaveAddrs();
-        for (uint256 i = 0; i < addrList.length; i++) {
-            // code for Forge proxy connector
-            vm.etch(addrList[i], type(Connector).runtimeCode);
-        }
+    // sha256 hash of the Suave.sol file used to generate the registry
+    string public constant suaveSolHash = "93768d775163115cbd1d7accba75a415a4a70f150dfe4d2004215ed1c41514d8";
+    // suave-geth commit that Suave.sol was synced from (empty if unknown)
+    string public constant suaveGethCommit = "";
 
+    function enable() public {
         // enable the confidential store
         deployCodeTo(type(ConfidentialStore).creationCode, confidentialStoreAddr);
 
-        // enable the confidential inputs wrapper
+        // enable the connector of each precompile
+        vm.etch(Suave.IS_CONFIDENTIAL_ADDR, type(Connector).runtimeCode);
+        vm.etch(Suave.AES_DECRYPT, type(Connector).runtimeCode);
+        vm.etch(Suave.AES_ENCRYPT, type(Connector).runtimeCode);
+        vm.etch(Suave.BUILD_ETH_BLOCK, type(Connector).runtimeCode);
+        vm.etch(Suave.BUILD_ETH_BLOCK_TO, type(Connector).runtimeCode);
         vm.etch(Suave.CONFIDENTIAL_RETRIEVE, type(ConfidentialStoreConnector).runtimeCode);
         vm.etch(Suave.CONFIDENTIAL_STORE, type(ConfidentialStoreConnector).runtimeCode);
-        vm.etch(Suave.NEW_DATA_RECORD, type(ConfidentialStoreConnector).runtimeCode);
-        vm.etch(Suave.FETCH_DATA_RECORDS, type(ConfidentialStoreConnector).runtimeCode);
-
-        // enable is confidential wrapper
         vm.etch(Suave.CONTEXT_GET, type(ContextConnector).runtimeCode);
+        vm.etch(Suave.DO_HTTPREQUEST, type(Connector).runtimeCode);
+        vm.etch(Suave.DO_HTTPREQUEST2, type(Connector).runtimeCode);
+        vm.etch(Suave.ETHCALL, type(Connector).runtimeCode);
+        vm.etch(Suave.EXTRACT_HINT, type(Connector).runtimeCode);
+        vm.etch(Suave.FETCH_DATA_RECORDS, type(ConfidentialStoreConnector).runtimeCode);
+        vm.etch(Suave.FILL_MEV_SHARE_BUNDLE, type(Connector).runtimeCode);
+        vm.etch(Suave.GET_INSECURE_TIME, type(Connector).runtimeCode);
+        vm.etch(Suave.NEW_BUILDER, type(Connector).runtimeCode);
+        vm.etch(Suave.NEW_DATA_RECORD, type(ConfidentialStoreConnector).runtimeCode);
+        vm.etch(Suave.PRIVATE_KEY_GEN, type(Connector).runtimeCode);
+        vm.etch(Suave.RANDOM_BYTES, type(Connector).runtimeCode);
+        vm.etch(Suave.SIGN_ETH_TRANSACTION, type(Connector).runtimeCode);
+        vm.etch(Suave.SIGN_MESSAGE, type(Connector).runtimeCode);
+        vm.etch(Suave.SIMULATE_BUNDLE, type(Connector).runtimeCode);
+        vm.etch(Suave.SIMULATE_TRANSACTION, type(Connector).runtimeCode);
+        vm.etch(Suave.SUBMIT_BUNDLE_JSON_RPC, type(Connector).runtimeCode);
+        vm.etch(Suave.SUBMIT_ETH_BLOCK_TO_RELAY, type(Connector).runtimeCode);
     }
 
     function deployCodeTo(bytes memory creationCode, address where) internal {
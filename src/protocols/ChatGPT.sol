@@ -79,10 +79,16 @@ contract ChatGPT {
         return doGptRequest(body);
     }
 
+    /// @notice the URL of the chat completions API, derived contracts can override it (e.g. to use a mock in the tests).
+    /// @return url the URL of the chat completions API.
+    function completionsURL() internal view virtual returns (string memory) {
+        return "https://api.openai.com/v1/chat/completions";
+    }
+
     function doGptRequest(bytes memory body) private returns (string memory) {
         Suave.HttpRequest memory request;
         request.method = "POST";
-        request.url = "https://api.openai.com/v1/chat/completions";
+        request.url = completionsURL();
         request.headers = new string[](2);
         request.headers[0] = string.concat("Authorization: Bearer ", apiKey);
         request.headers[1] = "Content-Type: application/json";
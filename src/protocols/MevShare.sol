@@ -60,9 +60,10 @@ library MevShare {
             }
         }
 
-        body = abi.encodePacked(body, "]}");
+        body = abi.encodePacked(body, ']}}],"id":1}');
 
         Suave.HttpRequest memory request;
+        request.method = "POST";
         request.headers = new string[](1);
         request.headers[0] = "Content-Type:application/json";
         request.body = body;
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Unlicense
+pragma solidity ^0.8.13;
+
+import "forge-std/Test.sol";
+import "src/forge/Backend.sol";
+
+contract TestBackend is Test {
+    function testIsSuaveGeth() public {
+        assertTrue(ForgeBackend.isSuaveGeth("suave-geth"));
+        assertTrue(ForgeBackend.isSuaveGeth("/usr/local/bin/suave-geth"));
+        assertFalse(ForgeBackend.isSuaveGeth("forge-backend"));
+        assertFalse(ForgeBackend.isSuaveGeth("/usr/local/bin/forge-backend"));
+        assertFalse(ForgeBackend.isSuaveGeth("/opt/suave-geth/forge-tracer"));
+
+        assertFalse(ForgeBackend.supportsSessions("/usr/local/bin/suave-geth"));
+        assertTrue(ForgeBackend.supportsSessions("forge-backend"));
+    }
+
+    function testTracedBinary() public {
+        // the config cannot be read, the settings are the environment variables or the defaults
+        string memory config = "missing/foundry.toml";
+        vm.setEnv("SUAVE_TRACE_BACKEND", "");
+        vm.setEnv("SUAVE_TRACE_MODE", "");
+
+        assertEq(ForgeBackend.tracedBinary("forge-backend", config), "forge-backend");
+        assertEq(ForgeBackend.tracedBinary("/usr/local/bin/forge-tracer", config), "suave-geth");
+
+        vm.setEnv("SUAVE_TRACE_BACKEND", "forge-backend");
+        assertEq(ForgeBackend.tracedBinary("forge-tracer", config), "forge-backend");
+
+        // the calls are not run in replay mode
+        vm.setEnv("SUAVE_TRACE_MODE", "replay");
+        assertEq(ForgeBackend.tracedBinary("forge-tracer", config), "forge-tracer");
+
+        vm.setEnv("SUAVE_TRACE_BACKEND", "");
+        vm.setEnv("SUAVE_TRACE_MODE", "");
+    }
+}
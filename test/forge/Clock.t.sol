@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Unlicense
+pragma solidity ^0.8.13;
+
+import "forge-std/Test.sol";
+import "src/Test.sol";
+import "src/forge/Clock.sol";
+import "src/suavelib/Suave.sol";
+
+contract TestClock is Test, SuaveEnabled {
+    modifier withForgeBackend() {
+        // the clock is only supported by the forge-backend
+        vm.skip(!ForgeBackend.supportsSessions(ForgeBackend.binary(ForgeBackend.foundryToml())));
+        _;
+    }
+
+    function testClockFreezeAndAdvance() public withForgeBackend {
+        Clock.freeze();
+        assertEq(Clock.set(1000), 1000);
+        assertEq(Suave.getInsecureTime(), 1000);
+        assertEq(Suave.getInsecureTime(), 1000);
+
+        assertEq(Clock.advance(500), 1500);
+        assertEq(Suave.getInsecureTime(), 1500);
+    }
+
+    function testClockUnfreeze() public withForgeBackend {
+        Clock.freeze();
+        Clock.set(1000);
+        Clock.unfreeze();
+        assertGe(Suave.getInsecureTime(), 1000);
+
+        Clock.reset();
+        assertGt(Suave.getInsecureTime(), 1000);
+    }
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Unlicense
+pragma solidity ^0.8.13;
+
+import "forge-std/Test.sol";
+import "src/forge/MockRegistry.sol";
+import "src/suavelib/Suave.sol";
+
+contract TestMockRegistry is Test {
+    function setUp() public {
+        MockRegistry.enable();
+    }
+
+    function testMockRegistryReturnAt() public {
+        MockRegistry.doHTTPRequest().mockReturn(bytes("default"));
+        MockRegistry.doHTTPRequest().mockReturnAt(2, bytes("second"));
+
+        Suave.HttpRequest memory request;
+        request.url = "http://example.com";
+        request.method = "GET";
+
+        assertEq(Suave.doHTTPRequest(request), bytes("default"));
+        assertEq(Suave.doHTTPRequest(request), bytes("second"));
+        assertEq(Suave.doHTTPRequest(request), bytes("default"));
+        assertEq(MockRegistry.doHTTPRequest().callCount(), 3);
+
+        Suave.HttpRequest memory found = MockRegistry.doHTTPRequest().callAt(2);
+        assertEq(found.url, "http://example.com");
+        assertEq(found.method, "GET");
+    }
+
+    function testMockRegistryRawOutput() public {
+        MockRegistry.confidentialRetrieve().mockReturn(bytes("value"));
+
+        bytes memory value = Suave.confidentialRetrieve(Suave.DataId.wrap(bytes16(0)), "key");
+        assertEq(value, bytes("value"));
+    }
+
+    function testMockRegistryNoOutputs() public {
+        Suave.confidentialStore(Suave.DataId.wrap(bytes16(uint128(1))), "key", bytes("value"));
+        assertEq(MockRegistry.confidentialStore().callCount(), 1);
+
+        (Suave.DataId dataId, string memory key, bytes memory value) = MockRegistry.confidentialStore().callAt(1);
+        assertEq(Suave.DataId.unwrap(dataId), bytes16(uint128(1)));
+        assertEq(key, "key");
+        assertEq(value, bytes("value"));
+    }
+
+    function testMockRegistryExpectCall() public {
+        MockRegistry.randomBytes().mockReturn(bytes("random"));
+        MockRegistry.randomBytes().expectCall(32);
+
+        assertEq(Suave.randomBytes(32), bytes("random"));
+
+        // the second call is not constrained
+        assertEq(Suave.randomBytes(16), bytes("random"));
+
+        // the expected inputs apply to the next calls (the third and the fourth)
+        MockRegistry.randomBytes().expectCall(16);
+        MockRegistry.randomBytes().expectCall(64);
+
+        assertEq(Suave.randomBytes(16), bytes("random"));
+        (bool success,) = Suave.RANDOM_BYTES.call(abi.encode(uint8(16)));
+        assertFalse(success);
+        assertEq(Suave.randomBytes(64), bytes("random"));
+        assertEq(MockRegistry.randomBytes().callCount(), 4);
+    }
+
+    function testMockRegistryIsConfidential() public {
+        // enable programs the mock to run in a confidential context
+        assertTrue(Suave.isConfidential());
+
+        MockRegistry.isConfidential().mockReturn(false);
+        assertFalse(Suave.isConfidential());
+    }
+
+    function testMockRegistryRevert() public {
+        MockRegistry.getInsecureTime().mockReturn(10);
+        MockRegistry.getInsecureTime().mockRevertAt(2, bytes("failed"));
+
+        (bool success, bytes memory data) = Suave.GET_INSECURE_TIME.call(abi.encode());
+        assertTrue(success);
+        assertEq(abi.decode(data, (uint256)), 10);
+
+        (success, data) = Suave.GET_INSECURE_TIME.call(abi.encode());
+        assertFalse(success);
+        assertEq(data, bytes("failed"));
+    }
+
+    function testMockRegistryNoResponse() public {
+        (bool success,) = Suave.NEW_BUILDER.call(abi.encode());
+        assertFalse(success);
+    }
+}
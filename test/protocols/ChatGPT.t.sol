@@ -30,3 +30,142 @@ contract ChatGPTTest is Test, SuaveEnabled {
         }
     }
 }
+
+// ChatGPTMockTest runs against the mock of the OpenAI API of the forge-backend
+// (tools/forge-backend) with the script in test/fixtures/openai_script.json.
+contract ChatGPTMockTest is Test, SuaveEnabled {
+    function testChatGPTMockComplete() public {
+        ChatGPT chatgpt = getMockChatGPT();
+
+        string memory found = chatgpt.complete(userMessage("Say this is a test!"));
+        assertEq(found, "This is a test!");
+    }
+
+    function testChatGPTMockCompleteWithModel() public {
+        ChatGPT chatgpt = getMockChatGPT();
+
+        ChatGPT.Message[] memory messages = new ChatGPT.Message[](2);
+        messages[0] = ChatGPT.Message(ChatGPT.Role.System, "You are a helpful assistant.");
+        messages[1] = ChatGPT.Message(ChatGPT.Role.User, "What is SUAVE?");
+
+        string memory found = chatgpt.complete(messages, "gpt-4", "0.2");
+        assertEq(found, "A decentralized block builder.");
+    }
+
+    function testChatGPTMockInvalidModel() public {
+        ChatGPT chatgpt = getMockChatGPT();
+
+        // the URL of the mock is valid, the 404 is for the model
+        assertEq(chatgpt.complete(userMessage("Say this is a test!"), "gpt-4", "0.7"), "This is a test!");
+
+        try chatgpt.complete(userMessage("Say this is a test!"), "gpt-unknown", "0.7") {
+            assertTrue(false, "expected the unknown model to revert");
+        } catch (bytes memory reason) {
+            assertHttpError(reason, "404 Not Found");
+        }
+    }
+
+    function testChatGPTMockInvalidTemperature() public {
+        ChatGPT chatgpt = getMockChatGPT();
+
+        try chatgpt.complete(userMessage("Say this is a test!"), "gpt-4", "3") {
+            assertTrue(false, "expected the invalid temperature to revert");
+        } catch (bytes memory reason) {
+            assertHttpError(reason, "400 Bad Request");
+        }
+    }
+
+    function testChatGPTMockRateLimit() public {
+        ChatGPT chatgpt = getMockChatGPT();
+
+        try chatgpt.complete(userMessage("Rate limited")) {
+            assertTrue(false, "expected the rate limit to revert");
+        } catch (bytes memory reason) {
+            assertHttpError(reason, "429 Too Many Requests");
+        }
+    }
+
+    function testChatGPTMockServerError() public {
+        ChatGPT chatgpt = getMockChatGPT();
+
+        try chatgpt.complete(userMessage("Server error")) {
+            assertTrue(false, "expected the server error to revert");
+        } catch (bytes memory reason) {
+            assertHttpError(reason, "500 Internal Server Error");
+        }
+    }
+
+    function testChatGPTMockMalformedJSON() public {
+        ChatGPT chatgpt = getMockChatGPT();
+
+        vm.expectRevert(JSONParserLib.ParsingFailed.selector);
+        chatgpt.complete(userMessage("Malformed"));
+    }
+
+    function testChatGPTMockNullContent() public {
+        ChatGPT chatgpt = getMockChatGPT();
+
+        vm.expectRevert(bytes("Invalid input"));
+        chatgpt.complete(userMessage("Null content"));
+    }
+
+    // assertHttpError checks that the reason is the revert of the doHTTPRequest precompile
+    // and that the error of the request has the http status.
+    function assertHttpError(bytes memory reason, string memory status) internal {
+        assertEq(bytes4(reason), Suave.PeekerReverted.selector, "expected a precompile revert");
+        (address precompile, bytes memory data) = abi.decode(withoutSelector(reason), (address, bytes));
+        assertEq(precompile, Suave.DO_HTTPREQUEST);
+
+        // the connector reverts with the error of the backend as Error(string)
+        assertEq(bytes4(data), bytes4(keccak256("Error(string)")), "expected an error message");
+        string memory message = abi.decode(withoutSelector(data), (string));
+        assertTrue(contains(message, status), string.concat("expected '", status, "' in: ", message));
+    }
+
+    function withoutSelector(bytes memory data) internal pure returns (bytes memory res) {
+        res = new bytes(data.length - 4);
+        for (uint256 i = 4; i < data.length; i++) {
+            res[i - 4] = data[i];
+        }
+    }
+
+    function contains(string memory str, string memory substr) internal pure returns (bool) {
+        bytes memory a = bytes(str);
+        bytes memory b = bytes(substr);
+        for (uint256 i = 0; i + b.length <= a.length; i++) {
+            bool found = true;
+            for (uint256 j = 0; j < b.length && found; j++) {
+                found = a[i + j] == b[j];
+            }
+            if (found) {
+                return true;
+            }
+        }
+        return false;
+    }
+
+    function userMessage(string memory content) internal pure returns (ChatGPT.Message[] memory messages) {
+        messages = new ChatGPT.Message[](1);
+        messages[0] = ChatGPT.Message(ChatGPT.Role.User, content);
+    }
+
+    function getMockChatGPT() public returns (ChatGPT chatgpt) {
+        try vm.envString("CHATGPT_MOCK_URL") returns (string memory url) {
+            chatgpt = new MockChatGPT(url);
+        } catch {
+            vm.skip(true);
+        }
+    }
+}
+
+contract MockChatGPT is ChatGPT {
+    string url;
+
+    constructor(string memory _url) ChatGPT("test-key") {
+        url = _url;
+    }
+
+    function completionsURL() internal view override returns (string memory) {
+        return url;
+    }
+}
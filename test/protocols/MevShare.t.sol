@@ -22,8 +22,9 @@ contract MevShareTest is Test {
         Suave.HttpRequest memory request = MevShare.encodeBundle(bundle);
         assertEq(
             string(request.body),
-            '{"jsonrpc":"2.0","method":"mev_sendBundle","params":[{"version":"v0.1","inclusion":{"block":"0x1"},"body":[{"tx":"0x1234","canRevert":true}],"validity":{"refund":[{"bodyIdx":0,"percent":10}]}'
+            '{"jsonrpc":"2.0","method":"mev_sendBundle","params":[{"version":"v0.1","inclusion":{"block":"0x1"},"body":[{"tx":"0x1234","canRevert":true}],"validity":{"refund":[{"bodyIdx":0,"percent":10}]}}],"id":1}'
         );
+        assertEq(request.method, "POST");
         assertTrue(request.withFlashbotsSignature);
     }
 }
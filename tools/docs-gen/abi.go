@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// declarationIndex has the structs, enums, value types and contracts of
+// all the source units by id to resolve the abi types of the user defined types.
+type declarationIndex map[uint64]*astNode
+
+func newDeclarationIndex(artifacts []*artifact) declarationIndex {
+	index := declarationIndex{}
+	for _, artifact := range artifacts {
+		for _, node := range artifact.Ast.Filter(func(node *astNode) bool {
+			switch node.NodeType {
+			case contractDefinitionType, "StructDefinition", "EnumDefinition", "UserDefinedValueTypeDefinition":
+				return true
+			}
+			return false
+		}) {
+			index[node.ID] = node
+		}
+	}
+	return index
+}
+
+// abiType returns the canonical abi type used in the signatures of the events and errors.
+func (d declarationIndex) abiType(t *TypeName) (string, error) {
+	switch t.Kind {
+	case elementaryTypeKind:
+		switch t.Name {
+		case "uint", "int":
+			return t.Name + "256", nil
+		case "address payable":
+			return "address", nil
+		case "byte":
+			return "bytes1", nil
+		}
+		return t.Name, nil
+
+	case arrayTypeKind:
+		base, err := d.abiType(t.Base)
+		if err != nil {
+			return "", err
+		}
+		return base + "[" + t.Length + "]", nil
+
+	case functionTypeKind:
+		return "function", nil
+
+	case userDefinedTypeKind:
+		decl, ok := d[t.Reference]
+		if !ok {
+			return "", fmt.Errorf("declaration of type '%s' not found", t.Name)
+		}
+		switch decl.NodeType {
+		case contractDefinitionType:
+			return "address", nil
+		case "EnumDefinition":
+			return "uint8", nil
+		case "UserDefinedValueTypeDefinition":
+			underlying, err := parseTypeName(decl.UnderlyingType)
+			if err != nil {
+				return "", err
+			}
+			return d.abiType(underlying)
+		case "StructDefinition":
+			components := []string{}
+			for _, member := range decl.Members {
+				memberType, err := parseTypeName(member.TypeName)
+				if err != nil {
+					return "", err
+				}
+				component, err := d.abiType(memberType)
+				if err != nil {
+					return "", err
+				}
+				components = append(components, component)
+			}
+			return "(" + strings.Join(components, ",") + ")", nil
+		}
+		return "", fmt.Errorf("unexpected declaration '%s' of type '%s'", decl.NodeType, t.Name)
+	}
+
+	return "", fmt.Errorf("type '%s' has no abi encoding", t.String())
+}
+
+// signature returns the canonical signature of an event or an error and its keccak256 hash.
+func (d declarationIndex) signature(name string, params []*Field) (string, []byte, error) {
+	types := []string{}
+	for _, param := range params {
+		typ, err := d.abiType(param.TypeName)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to encode the type of '%s': %v", param.Name, err)
+		}
+		types = append(types, typ)
+	}
+	signature := name + "(" + strings.Join(types, ",") + ")"
+
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(signature))
+	return signature, hash.Sum(nil), nil
+}
+
+func hexEncode(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+// referencedDeclarations returns the ids of the declarations referenced by
+// the identifiers in the body of a function.
+func referencedDeclarations(body json.RawMessage) ([]uint64, error) {
+	if len(body) == 0 {
+		return nil, nil
+	}
+	var node interface{}
+	if err := json.Unmarshal(body, &node); err != nil {
+		return nil, err
+	}
+
+	ids := []uint64{}
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch obj := node.(type) {
+		case map[string]interface{}:
+			if obj["nodeType"] == "Identifier" {
+				if id, ok := obj["referencedDeclaration"].(float64); ok && id > 0 {
+					ids = append(ids, uint64(id))
+				}
+			}
+			for _, v := range obj {
+				walk(v)
+			}
+		case []interface{}:
+			for _, v := range obj {
+				walk(v)
+			}
+		}
+	}
+	walk(node)
+	return ids, nil
+}
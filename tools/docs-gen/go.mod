@@ -2,9 +2,13 @@ module github.com/flashbots/suave-std/tools/docs-gen
 
 go 1.21.0
 
-require github.com/Kunde21/markdownfmt/v3 v3.1.0
+require (
+	github.com/Kunde21/markdownfmt/v3 v3.1.0
+	golang.org/x/crypto v0.17.0
+)
 
 require (
 	github.com/mattn/go-runewidth v0.0.9 // indirect
 	github.com/yuin/goldmark v1.3.5 // indirect
+	golang.org/x/sys v0.15.0 // indirect
 )
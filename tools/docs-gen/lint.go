@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+)
+
+// Kinds of lintIssue
+const (
+	missingIssueKind  = "missing"
+	mismatchIssueKind = "mismatch"
+	invalidIssueKind  = "invalid"
+)
+
+type lintIssue struct {
+	File    string `json:"file"`
+	Line    uint64 `json:"line"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// lintReport has the natspec issues of the source units. The coverage is the
+// percentage of the functions, structs and struct fields that are documented.
+type lintReport struct {
+	Documented  int         `json:"documented"`
+	Total       int         `json:"total"`
+	Coverage    float64     `json:"coverage"`
+	MinCoverage float64     `json:"min_coverage"`
+	Passed      bool        `json:"passed"`
+	Issues      []lintIssue `json:"issues"`
+}
+
+func newLintReport(minCoverage float64) *lintReport {
+	return &lintReport{MinCoverage: minCoverage, Issues: []lintIssue{}}
+}
+
+// item counts a declaration in the coverage and reports it if it is not documented.
+func (r *lintReport) item(file string, pos *Pos, documented bool, message string) {
+	r.Total++
+	if documented {
+		r.Documented++
+	} else {
+		r.issue(file, pos, missingIssueKind, message)
+	}
+}
+
+func (r *lintReport) issue(file string, pos *Pos, kind, message string) {
+	r.Issues = append(r.Issues, lintIssue{File: file, Line: pos.FromLine, Kind: kind, Message: message})
+}
+
+// finish computes the coverage. The lint passes if the coverage is above the minimum
+// and the natspec of the declarations that are documented is correct.
+func (r *lintReport) finish() {
+	r.Coverage = 100
+	if r.Total != 0 {
+		r.Coverage = 100 * float64(r.Documented) / float64(r.Total)
+	}
+	r.Passed = r.Coverage >= r.MinCoverage
+	for _, issue := range r.Issues {
+		if issue.Kind != missingIssueKind {
+			r.Passed = false
+		}
+	}
+}
+
+func (r *lintReport) write(w io.Writer, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+
+	for _, issue := range r.Issues {
+		fmt.Fprintf(w, "%s:%d: %s\n", issue.File, issue.Line, issue.Message)
+	}
+	fmt.Fprintf(w, "natspec coverage: %.1f%% (%d/%d)\n", r.Coverage, r.Documented, r.Total)
+	if r.Coverage < r.MinCoverage {
+		fmt.Fprintf(w, "coverage is below the minimum of %.1f%%\n", r.MinCoverage)
+	}
+	return nil
+}
+
+// runLint runs the lint mode and returns the exit code, 1 if the lint does not pass.
+func runLint(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.StringVar(&suaveStdPath, "suave-std", "./suave-std", "path to the suave std")
+	minCoverage := flags.Float64("min-coverage", 0, "minimum percentage of documented functions, structs and fields")
+	asJSON := flags.Bool("json", false, "write the report in json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	artifacts, err := readForgeArtifacts(filepath.Join(suaveStdPath, "out"))
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+
+	index := newContractIndex(artifacts)
+	report := newLintReport(*minCoverage)
+	for _, artifact := range artifacts {
+		source, err := newSourceUnit(filepath.Join(suaveStdPath, artifact.Ast.AbsolutePath))
+		if err != nil {
+			log.Print(err)
+			return 2
+		}
+		if err := lintSourceUnit(artifact.Ast, source, index, report); err != nil {
+			log.Printf("failed to lint %s: %v", artifact.Ast.AbsolutePath, err)
+			return 2
+		}
+	}
+	report.finish()
+
+	if err := report.write(out, *asJSON); err != nil {
+		log.Print(err)
+		return 2
+	}
+	if !report.Passed {
+		return 1
+	}
+	return 0
+}
+
+// lintSourceUnit reports the public, external and internal functions, the structs
+// and the struct fields of the contracts that are missing natspec, and the
+// @param and @return tags that do not match the declarations.
+func lintSourceUnit(ast *astNode, source *sourceUnit, index contractIndex, report *lintReport) error {
+	file := ast.AbsolutePath
+
+	for _, contract := range ast.Filter(func(node *astNode) bool {
+		return node.NodeType == contractDefinitionType
+	}) {
+		if ignoredContracts[contract.Name] {
+			continue
+		}
+
+		for _, s := range contract.Filter(func(node *astNode) bool {
+			return node.NodeType == "StructDefinition"
+		}) {
+			pos, err := source.Decode(s.Src)
+			if err != nil {
+				return err
+			}
+			name := contract.Name + "." + s.Name
+			report.item(file, pos, s.hasDocs(), fmt.Sprintf("struct '%s' is missing natspec", name))
+
+			spec := &natSpec{}
+			if s.hasDocs() {
+				if spec, err = parseNatSpec(s.Documentation.Text); err != nil {
+					report.issue(file, pos, invalidIssueKind, fmt.Sprintf("invalid natspec for struct '%s': %v", name, err))
+					spec = &natSpec{}
+				}
+			}
+
+			for _, member := range s.Members {
+				memberPos, err := source.Decode(member.Src)
+				if err != nil {
+					return err
+				}
+				report.item(file, memberPos, hasValue(spec.Param, member.Name), fmt.Sprintf("field '%s' of struct '%s' is missing a @param", member.Name, name))
+			}
+			for _, param := range spec.Param {
+				if !hasMember(s.Members, param.Name) {
+					report.issue(file, pos, mismatchIssueKind, fmt.Sprintf("@param '%s' of struct '%s' is not a field", param.Name, name))
+				}
+			}
+		}
+
+		for _, fn := range contract.Filter(func(node *astNode) bool {
+			return node.NodeType == functionDefinitionType && node.Visibility != "private" &&
+				(node.Kind == "function" || node.Kind == "constructor")
+		}) {
+			pos, err := source.Decode(fn.Src)
+			if err != nil {
+				return err
+			}
+			name := contract.Name + "." + fn.Name
+			if fn.Kind == "constructor" {
+				name = contract.Name + ".constructor"
+			}
+			report.item(file, pos, fn.hasDocs(), fmt.Sprintf("function '%s' is missing natspec", name))
+			if !fn.hasDocs() {
+				continue
+			}
+
+			spec, err := index.functionNatSpec(fn)
+			if err != nil {
+				report.issue(file, pos, invalidIssueKind, fmt.Sprintf("invalid natspec for function '%s': %v", name, err))
+				continue
+			}
+
+			params, err := fn.parameters()
+			if err != nil {
+				return err
+			}
+			var returns []*astNode
+			if fn.ReturnParameters != nil {
+				if returns, err = fn.ReturnParameters.parameterList(); err != nil {
+					return err
+				}
+			}
+			mismatches := append(specMismatches("@param", spec.Param, params), specMismatches("@return", spec.Return, returns)...)
+			for _, mismatch := range mismatches {
+				report.issue(file, pos, mismatchIssueKind, fmt.Sprintf("function '%s': %s", name, mismatch))
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasMember(members []*astNode, name string) bool {
+	for _, member := range members {
+		if member.Name == name {
+			return true
+		}
+	}
+	return false
+}
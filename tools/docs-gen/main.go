@@ -10,6 +10,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
@@ -24,6 +25,10 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(runLint(os.Args[2:], os.Stdout))
+	}
+
 	flag.StringVar(&suaveStdPath, "suave-std", "./suave-std", "path to the suave std")
 	flag.StringVar(&outPath, "out", "./suave-std-gen", "path to the output")
 	flag.Parse()
@@ -35,9 +40,11 @@ func main() {
 	}
 
 	// parse the artifacts
+	index := newContractIndex(artifacts)
+	decls := newDeclarationIndex(artifacts)
 	contractDefs := []*ContractDef{}
 	for _, artifact := range artifacts {
-		contractDef, err := parseArtifact(artifact)
+		contractDef, err := parseArtifact(artifact, index, decls)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -54,16 +61,71 @@ func main() {
 
 	log.Printf("Writing documentation to to %s", outPath)
 
-	// apply the template and write the docs
-	for _, contract := range contractDefs {
-		if err := applyTemplate(contractDefs, contract); err != nil {
+	// apply the template and write the docs, one page per source unit
+	sourceUnits := groupSourceUnits(contractDefs)
+	for _, unit := range sourceUnits {
+		if err := applyTemplate(sourceUnits, unit); err != nil {
 			log.Fatal(err)
 		}
 	}
 }
 
-func applyTemplate(all []*ContractDef, contract *ContractDef) error {
-	curPath := filepath.Dir(contract.Path)
+// groupSourceUnits groups the contracts by the source unit that defines them.
+func groupSourceUnits(contractDefs []*ContractDef) []*SourceUnitDef {
+	units := []*SourceUnitDef{}
+	byPath := map[string]*SourceUnitDef{}
+	for _, contract := range contractDefs {
+		unit, ok := byPath[contract.Path]
+		if !ok {
+			unit = &SourceUnitDef{Path: contract.Path}
+			byPath[contract.Path] = unit
+			units = append(units, unit)
+		}
+		unit.Contracts = append(unit.Contracts, contract)
+	}
+
+	for _, unit := range units {
+		if len(unit.Contracts) == 1 {
+			unit.Name = unit.Contracts[0].Name
+		} else {
+			unit.Name = strings.TrimSuffix(filepath.Base(unit.Path), ".sol")
+		}
+	}
+	return units
+}
+
+// pagePath returns the path of the page of a source unit relative to the output.
+func pagePath(path string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(path, "src/"), ".sol") + ".mdx"
+}
+
+// findTypeRef returns the source unit, the contract and the name of the struct,
+// enum or user defined value type with the given id.
+func findTypeRef(all []*SourceUnitDef, id uint64) (*SourceUnitDef, *ContractDef, string) {
+	for _, unit := range all {
+		for _, contract := range unit.Contracts {
+			for _, structRef := range contract.Structs {
+				if structRef.ID == id {
+					return unit, contract, structRef.Name
+				}
+			}
+			for _, enumRef := range contract.Enums {
+				if enumRef.ID == id {
+					return unit, contract, enumRef.Name
+				}
+			}
+			for _, valueTypeRef := range contract.ValueTypes {
+				if valueTypeRef.ID == id {
+					return unit, contract, valueTypeRef.Name
+				}
+			}
+		}
+	}
+	return nil, nil, ""
+}
+
+func applyTemplate(all []*SourceUnitDef, unit *SourceUnitDef) error {
+	curPage := pagePath(unit.Path)
 
 	funcMap := template.FuncMap{
 		"desc": func(s string) string {
@@ -87,43 +149,45 @@ func applyTemplate(all []*ContractDef, contract *ContractDef) error {
 		"quote": func(s string) string {
 			return fmt.Sprintf("`%s`", s)
 		},
-		"type": func(s *Field) string {
-			if s.TypeReference == 0 {
-				// basic type with quotes
-				return fmt.Sprintf("`%s`", s.Type)
-			}
-
-			// find the reference type
-			for _, contract := range all {
-				for _, structRef := range contract.Structs {
-					if structRef.ID == s.TypeReference {
-
-						dstPath := filepath.Dir(contract.Path)
-						dstFile := filepath.Base(contract.Path)
-
-						// try to add a link to the struct
-						rel, err := filepath.Rel(curPath, dstPath)
-						if err != nil {
-							panic(err)
-						}
-
-						anchorName := strings.ToLower(structRef.Name)
-
-						var link string
-						if rel == "." {
-							// same file, just create the reference
-							link = fmt.Sprintf("[%s](#%s)", structRef.Name, anchorName)
-						} else {
-							link = fmt.Sprintf("[%s](%s#%s)", structRef.Name, filepath.Join(rel, dstFile), anchorName)
-						}
-
-						return link
-					}
+		"type": func(s *Field) template.HTML {
+			// the type is markdown, the '=>' of the mappings must not be escaped
+			return template.HTML(s.TypeName.Markdown(func(ref *TypeName) (string, bool) {
+				// find the struct, enum or value type in the contracts
+				dstUnit, contract, name := findTypeRef(all, ref.Reference)
+				if contract == nil {
+					log.Printf("Link for type not found: %s", ref.Name)
+					return "", false
+				}
+
+				anchorName := dstUnit.anchor(contract, name)
+				dstPage := pagePath(dstUnit.Path)
+				if dstPage == curPage {
+					// same page, just create the reference
+					return fmt.Sprintf("[%s](#%s)", ref.Name, anchorName), true
 				}
-			}
 
-			log.Printf("Link for type not found: %s", s.Type)
-			return fmt.Sprintf("`%s`", s.Type)
+				// try to add a link to the page of the type
+				rel, err := filepath.Rel(filepath.Dir(curPage), dstPage)
+				if err != nil {
+					panic(err)
+				}
+				return fmt.Sprintf("[%s](%s#%s)", ref.Name, rel, anchorName), true
+			}))
+		},
+		"anchor": func(contract *ContractDef, name string) string {
+			// the headings of a page with several contracts have explicit anchors
+			// since they may have the same name in different contracts
+			if !unit.Multiple() {
+				return ""
+			}
+			return fmt.Sprintf(" {#%s}", unit.anchor(contract, name))
+		},
+		"heading": func(level int) string {
+			// the sections of each contract are one level down in a page with several contracts
+			if unit.Multiple() {
+				level++
+			}
+			return strings.Repeat("#", level)
 		},
 	}
 	t, err := template.New("template").Funcs(funcMap).Parse(docsTemplate)
@@ -131,7 +195,7 @@ func applyTemplate(all []*ContractDef, contract *ContractDef) error {
 		return err
 	}
 	var outputRaw bytes.Buffer
-	if err = t.Execute(&outputRaw, contract); err != nil {
+	if err = t.Execute(&outputRaw, unit); err != nil {
 		return err
 	}
 
@@ -146,10 +210,7 @@ func applyTemplate(all []*ContractDef, contract *ContractDef) error {
 	output = string(outputB)
 	output = strings.Replace(output, "&#39;", "'", -1)
 
-	// get the relative path with respect to src
-	relPath := strings.TrimPrefix(contract.Path, "src/")
-	dstPath := filepath.Join(outPath, relPath)
-	dstPath = strings.Replace(dstPath, ".sol", ".mdx", -1)
+	dstPath := filepath.Join(outPath, curPage)
 
 	// create any intermediate dirs
 	dir := filepath.Dir(dstPath)
@@ -165,17 +226,30 @@ func applyTemplate(all []*ContractDef, contract *ContractDef) error {
 
 var docsTemplate = `
 # {{.Name}}
-
-{{desc .Description}}
 {{$Path := .Path}}
+{{range .Contracts}}{{$Contract := .}}
+{{ if $.Multiple -}}
+## {{.Name}}
+{{end}}
+
+{{if .Title}}_{{.Title}}_{{end}}
+
+{{if .Description}}{{desc .Description}}{{end}}
+
+{{if .Author}}Author: {{.Author}}{{end}}
+
+{{template "notes" .}}
 
-## Functions
+{{ if ne (len .Functions) 0 -}}
+{{heading 2}} Functions
 
 {{range .Functions}}
-### [{{.Name}}](https://github.com/flashbots/suave-std/tree/main/{{$Path}}#L{{.Pos.FromLine}})
+{{heading 3}} [{{.Name}}](https://github.com/flashbots/suave-std/tree/main/{{$Path}}#L{{.Pos.FromLine}})
 
 {{desc .Description}}
 
+{{template "notes" .}}
+
 {{ if ne (len .Input) 0 -}}
 Input:
 {{range .Input}}
@@ -192,30 +266,198 @@ Output:
 
 {{end}}
 
+{{end}}
+
 {{ if ne (len .Structs) 0 -}}
-## Structs
+{{heading 2}} Structs
 
 {{range .Structs}}
-### [{{.Name}}](https://github.com/flashbots/suave-std/tree/main/{{$Path}}#L{{.Pos.FromLine}})
+{{heading 3}} [{{.Name}}](https://github.com/flashbots/suave-std/tree/main/{{$Path}}#L{{.Pos.FromLine}}){{anchor $Contract .Name}}
 
 {{desc .Description}}
 
+{{template "notes" .}}
+
 {{range .Fields}}
 - {{quote .Name}} ({{type .}}): {{desc .Description}}
 {{- end}}
 {{end}}
 
+{{end}}
+
+{{ if ne (len .Enums) 0 -}}
+{{heading 2}} Enums
+
+{{range .Enums}}
+{{heading 3}} [{{.Name}}](https://github.com/flashbots/suave-std/tree/main/{{$Path}}#L{{.Pos.FromLine}}){{anchor $Contract .Name}}
+
+{{if .Description}}{{desc .Description}}{{end}}
+
+{{template "notes" .}}
+
+{{range .Values}}
+- {{quote .}}
+{{- end}}
+{{end}}
+
+{{end}}
+
+{{ if ne (len .ValueTypes) 0 -}}
+{{heading 2}} Types
+
+{{range .ValueTypes}}
+{{heading 3}} [{{.Name}}](https://github.com/flashbots/suave-std/tree/main/{{$Path}}#L{{.Pos.FromLine}}){{anchor $Contract .Name}}
+
+{{if .Description}}{{desc .Description}}{{end}}
+
+{{template "notes" .}}
+
+Underlying type: {{quote .Underlying}}
+{{end}}
+
+{{end}}
+
+{{ if ne (len .Events) 0 -}}
+{{heading 2}} Events
+
+{{range .Events}}
+{{heading 3}} [{{.Name}}](https://github.com/flashbots/suave-std/tree/main/{{$Path}}#L{{.Pos.FromLine}}){{anchor $Contract .Name}}
+
+{{if .Description}}{{desc .Description}}{{end}}
+
+{{template "notes" .}}
+
+Signature: {{quote .Signature}}
+
+{{if .Anonymous}}Anonymous event.{{else}}Topic: {{quote .Topic}}{{end}}
+
+{{template "params" .Params}}
+{{end}}
+
+{{end}}
+
+{{ if ne (len .Errors) 0 -}}
+{{heading 2}} Errors
+
+{{range .Errors}}
+{{heading 3}} [{{.Name}}](https://github.com/flashbots/suave-std/tree/main/{{$Path}}#L{{.Pos.FromLine}}){{anchor $Contract .Name}}
+
+{{if .Description}}{{desc .Description}}{{end}}
+
+{{template "notes" .}}
+
+Signature: {{quote .Signature}}
+
+Selector: {{quote .Selector}}
+
+{{template "params" .Params}}
+{{end}}
+
+{{end}}
+
+{{ if ne (len .Constants) 0 -}}
+{{heading 2}} Constants
+
+{{range .Constants}}
+- {{quote .Field.Name}} ({{type .Field}}) = {{quote .Value}}{{if .Field.Description}}: {{desc .Field.Description}}{{end}}
+{{- end}}
+
+{{end}}
+
+{{ if ne (len .Precompiles) 0 -}}
+{{heading 2}} Precompiles
+
+| Precompile | Address | Functions |
+| --- | --- | --- |
+{{range .Precompiles -}}
+| {{quote .Name}} | {{quote .Address}} | {{range $indx, $func := .Functions}}{{if $indx}}, {{end}}{{quote $func}}{{end}} |
+{{end}}
+
+{{end}}
+{{end}}
+
+{{define "params"}}
+{{ if ne (len .) 0 -}}
+Parameters:
+{{range .}}
+- {{if .Name}}{{quote .Name}} {{end}}({{type .}}{{if .Indexed}}, indexed{{end}}){{if .Description}}: {{desc .Description}}{{end}}
+{{- end}}
+{{end}}
+{{end}}
+
+{{define "notes"}}
+{{ if .Dev -}}
+Developer notes: {{desc .Dev}}
+{{end}}
+
+{{ if ne (len .Custom) 0 -}}
+Custom tags:
+{{range .Custom}}
+- {{quote .Name}}: {{.Value}}
+{{- end}}
+{{end}}
 {{end}}
 `
 
+// SourceUnitDef is a page of the docs with the contracts of a source file.
+type SourceUnitDef struct {
+	Name      string         `json:"name"`
+	Path      string         `json:"path"`
+	Contracts []*ContractDef `json:"contracts"`
+}
+
+// Multiple returns whether the source unit has several documented contracts,
+// in which case each contract has its own section.
+func (s *SourceUnitDef) Multiple() bool {
+	return len(s.Contracts) > 1
+}
+
+// anchor returns the anchor of a type of the contract in the page. The names are
+// prefixed with the contract if the page has several contracts.
+func (s *SourceUnitDef) anchor(contract *ContractDef, name string) string {
+	if s.Multiple() {
+		name = contract.Name + "-" + name
+	}
+	return strings.ToLower(name)
+}
+
+// Notes are the developer notes and the custom tags of a natspec.
+type Notes struct {
+	Dev    string      `json:"dev,omitempty"`
+	Custom []CustomTag `json:"custom,omitempty"`
+}
+
+type CustomTag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func newNotes(spec *natSpec) Notes {
+	notes := Notes{Dev: spec.Dev}
+	for _, custom := range spec.Custom {
+		notes.Custom = append(notes.Custom, CustomTag{Name: custom.Name, Value: custom.Description})
+	}
+	return notes
+}
+
 type ContractDef struct {
-	Name        string        `json:"name"`
-	Path        string        `json:"path"`
-	Kind        string        `json:"kind"`
-	Examples    string        `json:"examples"`
-	Description string        `json:"description"`
-	Structs     []StructRef   `json:"structs"`
-	Functions   []FunctionDef `json:"functions"`
+	Name        string          `json:"name"`
+	Path        string          `json:"path"`
+	Kind        string          `json:"kind"`
+	Examples    string          `json:"examples"`
+	Title       string          `json:"title,omitempty"`
+	Author      string          `json:"author,omitempty"`
+	Description string          `json:"description"`
+	Structs     []StructRef     `json:"structs"`
+	Enums       []EnumRef       `json:"enums"`
+	ValueTypes  []ValueTypeRef  `json:"value_types"`
+	Events      []EventDef      `json:"events"`
+	Errors      []ErrorDef      `json:"errors"`
+	Constants   []ConstantDef   `json:"constants"`
+	Precompiles []PrecompileDef `json:"precompiles"`
+	Functions   []FunctionDef   `json:"functions"`
+
+	Notes
 }
 
 type StructRef struct {
@@ -224,6 +466,28 @@ type StructRef struct {
 	Pos         *Pos     `json:"pos"`
 	Description string   `json:"description"`
 	Fields      []*Field `json:"fields"`
+
+	Notes
+}
+
+type EnumRef struct {
+	ID          uint64   `json:"id"`
+	Name        string   `json:"name"`
+	Pos         *Pos     `json:"pos"`
+	Description string   `json:"description"`
+	Values      []string `json:"values"`
+
+	Notes
+}
+
+type ValueTypeRef struct {
+	ID          uint64 `json:"id"`
+	Name        string `json:"name"`
+	Pos         *Pos   `json:"pos"`
+	Description string `json:"description"`
+	Underlying  string `json:"underlying"`
+
+	Notes
 }
 
 type FunctionDef struct {
@@ -233,13 +497,59 @@ type FunctionDef struct {
 	Input       []*Field `json:"input,omitempty"`
 	Output      []*Field `json:"output,omitempty"`
 	IsModifier  bool     `json:"is_modifier,omitempty"`
+
+	Notes
 }
 
 type Field struct {
-	Name          string `json:"name"`
-	Description   string `json:"description"`
-	Type          string `json:"type,omitempty"`
-	TypeReference uint64 `json:"type-reference,omitempty"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Type        string    `json:"type,omitempty"`
+	TypeName    *TypeName `json:"type-name,omitempty"`
+	Indexed     bool      `json:"indexed,omitempty"`
+}
+
+type EventDef struct {
+	Name        string   `json:"name"`
+	Pos         *Pos     `json:"pos"`
+	Description string   `json:"description"`
+	Params      []*Field `json:"params"`
+	Signature   string   `json:"signature"`
+	Topic       string   `json:"topic,omitempty"`
+	Anonymous   bool     `json:"anonymous,omitempty"`
+
+	Notes
+}
+
+type ErrorDef struct {
+	Name        string   `json:"name"`
+	Pos         *Pos     `json:"pos"`
+	Description string   `json:"description"`
+	Params      []*Field `json:"params"`
+	Signature   string   `json:"signature"`
+	Selector    string   `json:"selector"`
+
+	Notes
+}
+
+type ConstantDef struct {
+	Field *Field `json:"field"`
+	Pos   *Pos   `json:"pos"`
+	Value string `json:"value"`
+
+	Notes
+}
+
+// PrecompileDef is an address constant called by the functions of the contract.
+type PrecompileDef struct {
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Functions []string `json:"functions"`
+}
+
+// ignoredContracts are the vendored contracts that are not documented nor linted.
+var ignoredContracts = map[string]bool{
+	"RLPWriter": true,
 }
 
 var (
@@ -255,6 +565,7 @@ func readForgeArtifacts(path string) ([]*artifact, error) {
 	}
 
 	artifacts := []*artifact{}
+	sourceUnits := map[string]struct{}{}
 	err := filepath.WalkDir(path, func(path string, d fs.DirEntry, _ error) error {
 		if d.IsDir() {
 			return nil
@@ -285,6 +596,13 @@ func readForgeArtifacts(path string) ([]*artifact, error) {
 			return nil
 		}
 
+		// there is an artifact for each contract of a source unit,
+		// all of them with the ast of the whole source unit
+		if _, ok := sourceUnits[artifact.Ast.AbsolutePath]; ok {
+			return nil
+		}
+		sourceUnits[artifact.Ast.AbsolutePath] = struct{}{}
+
 		artifacts = append(artifacts, &artifact)
 		return nil
 	})
@@ -314,6 +632,25 @@ type astNode struct {
 	Parameters            json.RawMessage
 	ReferencedDeclaration uint64
 	PathNode              *astNode
+	BaseContracts         []*astNode
+	BaseName              *astNode
+	Body                  json.RawMessage
+	Constant              bool
+	Indexed               bool
+	Anonymous             bool
+
+	// type names
+	BaseType             *astNode
+	Length               *astNode
+	KeyType              *astNode
+	ValueType            *astNode
+	UnderlyingType       *astNode
+	ParameterTypes       *astNode
+	ReturnParameterTypes *astNode
+	StateMutability      string
+	Visibility           string
+	// Value is the value of a literal or the initial value of a variable
+	Value json.RawMessage
 }
 
 func (a *astNode) hasDocs() bool {
@@ -340,7 +677,7 @@ func (a *astNode) Filter(check func(*astNode) bool) []*astNode {
 	return res
 }
 
-func parseArtifact(artifact *artifact) ([]*ContractDef, error) {
+func parseArtifact(artifact *artifact, index contractIndex, decls declarationIndex) ([]*ContractDef, error) {
 	// first find the contracts that have docs attached
 	contractsWithDocs := artifact.Ast.Filter(func(node *astNode) bool {
 		return node.NodeType == contractDefinitionType && node.hasDocs()
@@ -355,15 +692,21 @@ func parseArtifact(artifact *artifact) ([]*ContractDef, error) {
 
 	// for each contract, find the functions with comments
 	for _, contract := range contractsWithDocs {
-		if contract.Name == "RLPWriter" {
+		if ignoredContracts[contract.Name] {
 			continue
 		}
 
 		contractDecl := &ContractDef{
-			Path:    artifact.Ast.AbsolutePath, // FIX; now only one contract per source unit
-			Name:    contract.Name,
-			Kind:    contract.ContractKind,
-			Structs: []StructRef{},
+			Path:        artifact.Ast.AbsolutePath,
+			Name:        contract.Name,
+			Kind:        contract.ContractKind,
+			Structs:     []StructRef{},
+			Enums:       []EnumRef{},
+			ValueTypes:  []ValueTypeRef{},
+			Events:      []EventDef{},
+			Errors:      []ErrorDef{},
+			Constants:   []ConstantDef{},
+			Precompiles: []PrecompileDef{},
 		}
 
 		/*
@@ -399,11 +742,12 @@ func parseArtifact(artifact *artifact) ([]*ContractDef, error) {
 				Name:        s.Name,
 				Pos:         pos,
 				Description: structNat.Description,
+				Notes:       newNotes(structNat),
 			}
 
 			{
 				// fill out the types
-				fields, err := fillSpecTypes(structNat.Param, s.Members)
+				fields, err := fillSpecTypes("@param", structNat.Param, s.Members)
 				if err != nil {
 					return nil, fmt.Errorf("failed to parse %s struct %s: %v", contract.Name, s.Name, err)
 				}
@@ -413,19 +757,197 @@ func parseArtifact(artifact *artifact) ([]*ContractDef, error) {
 			contractDecl.Structs = append(contractDecl.Structs, structRef)
 		}
 
+		// Decode enums and user defined value types. They are documented even without
+		// natspec since the fields that use them link to them.
+		astEnums := contract.Filter(func(node *astNode) bool {
+			return node.NodeType == "EnumDefinition"
+		})
+		for _, e := range astEnums {
+			pos, err := sourceUnit.Decode(e.Src)
+			if err != nil {
+				return nil, err
+			}
+			enumRef := EnumRef{
+				ID:     e.ID,
+				Name:   e.Name,
+				Pos:    pos,
+				Values: []string{},
+			}
+			if e.hasDocs() {
+				enumNat, err := parseNatSpec(e.Documentation.Text)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse natspec for %s enum %s: %v", contract.Name, e.Name, err)
+				}
+				enumRef.Description = enumNat.Description
+				enumRef.Notes = newNotes(enumNat)
+			}
+			for _, member := range e.Members {
+				enumRef.Values = append(enumRef.Values, member.Name)
+			}
+			contractDecl.Enums = append(contractDecl.Enums, enumRef)
+		}
+
+		astValueTypes := contract.Filter(func(node *astNode) bool {
+			return node.NodeType == "UserDefinedValueTypeDefinition"
+		})
+		for _, v := range astValueTypes {
+			pos, err := sourceUnit.Decode(v.Src)
+			if err != nil {
+				return nil, err
+			}
+			underlying, err := parseTypeName(v.UnderlyingType)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s type %s: %v", contract.Name, v.Name, err)
+			}
+			valueTypeRef := ValueTypeRef{
+				ID:         v.ID,
+				Name:       v.Name,
+				Pos:        pos,
+				Underlying: underlying.String(),
+			}
+			if v.hasDocs() {
+				valueTypeNat, err := parseNatSpec(v.Documentation.Text)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse natspec for %s type %s: %v", contract.Name, v.Name, err)
+				}
+				valueTypeRef.Description = valueTypeNat.Description
+				valueTypeRef.Notes = newNotes(valueTypeNat)
+			}
+			contractDecl.ValueTypes = append(contractDecl.ValueTypes, valueTypeRef)
+		}
+
+		// Decode events and errors with their topic and selector
+		for _, astDecl := range contract.Filter(func(node *astNode) bool {
+			return node.NodeType == "EventDefinition" || node.NodeType == "ErrorDefinition"
+		}) {
+			declNat := &natSpec{}
+			if astDecl.hasDocs() {
+				if declNat, err = parseNatSpec(astDecl.Documentation.Text); err != nil {
+					return nil, fmt.Errorf("failed to parse natspec for %s %s: %v", contract.Name, astDecl.Name, err)
+				}
+			}
+			pos, err := sourceUnit.Decode(astDecl.Src)
+			if err != nil {
+				return nil, err
+			}
+			params, err := astDecl.parameters()
+			if err != nil {
+				return nil, err
+			}
+			fields, err := declarationFields(declNat.Param, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s %s: %v", contract.Name, astDecl.Name, err)
+			}
+			signature, hash, err := decls.signature(astDecl.Name, fields)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compute the signature of %s %s: %v", contract.Name, astDecl.Name, err)
+			}
+
+			if astDecl.NodeType == "EventDefinition" {
+				eventDef := EventDef{
+					Name:        astDecl.Name,
+					Pos:         pos,
+					Description: declNat.Description,
+					Params:      fields,
+					Signature:   signature,
+					Anonymous:   astDecl.Anonymous,
+					Notes:       newNotes(declNat),
+				}
+				if !astDecl.Anonymous {
+					eventDef.Topic = hexEncode(hash)
+				}
+				contractDecl.Events = append(contractDecl.Events, eventDef)
+			} else {
+				contractDecl.Errors = append(contractDecl.Errors, ErrorDef{
+					Name:        astDecl.Name,
+					Pos:         pos,
+					Description: declNat.Description,
+					Params:      fields,
+					Signature:   signature,
+					Selector:    hexEncode(hash[:4]),
+					Notes:       newNotes(declNat),
+				})
+			}
+		}
+
+		// Decode the public constants. The address constants called by the functions
+		// are the precompiles of the contract (i.e. in Suave.sol).
+		precompiles := map[uint64]*PrecompileDef{}
+		for _, astConst := range contract.Filter(func(node *astNode) bool {
+			return node.NodeType == "VariableDeclaration" && node.Constant && node.Visibility == "public"
+		}) {
+			constNat := &natSpec{}
+			if astConst.hasDocs() {
+				if constNat, err = parseNatSpec(astConst.Documentation.Text); err != nil {
+					return nil, fmt.Errorf("failed to parse natspec for %s constant %s: %v", contract.Name, astConst.Name, err)
+				}
+			}
+			pos, err := sourceUnit.Decode(astConst.Src)
+			if err != nil {
+				return nil, err
+			}
+			typeName, err := parseTypeName(astConst.TypeName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s constant %s: %v", contract.Name, astConst.Name, err)
+			}
+			value, err := sourceUnit.constantValue(astConst.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s constant %s: %v", contract.Name, astConst.Name, err)
+			}
+			contractDecl.Constants = append(contractDecl.Constants, ConstantDef{
+				Field: &Field{
+					Name:        astConst.Name,
+					Description: constNat.Description,
+					Type:        typeName.String(),
+					TypeName:    typeName,
+				},
+				Pos:   pos,
+				Value: value,
+				Notes: newNotes(constNat),
+			})
+			if typeName.String() == "address" {
+				precompiles[astConst.ID] = &PrecompileDef{Name: astConst.Name, Address: value}
+			}
+		}
+		for _, astFunc := range contract.Filter(func(node *astNode) bool {
+			return node.NodeType == functionDefinitionType
+		}) {
+			ids, err := referencedDeclarations(astFunc.Body)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode the body of %s function %s: %v", contract.Name, astFunc.Name, err)
+			}
+			for _, id := range ids {
+				if precompile, ok := precompiles[id]; ok && !slices.Contains(precompile.Functions, astFunc.Name) {
+					precompile.Functions = append(precompile.Functions, astFunc.Name)
+				}
+			}
+		}
+		constants := contractDecl.Constants[:0]
+		for _, constant := range contractDecl.Constants {
+			if precompile := findPrecompile(precompiles, constant.Field.Name); precompile != nil && len(precompile.Functions) != 0 {
+				contractDecl.Precompiles = append(contractDecl.Precompiles, *precompile)
+			} else {
+				constants = append(constants, constant)
+			}
+		}
+		contractDecl.Constants = constants
+
 		// Decode contract natspec
 		contractNatSpec, err := parseNatSpec(contract.Documentation.Text)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse natspec for contract '%s': %v", contract.Name, err)
 		}
+		contractDecl.Title = contractNatSpec.Title
+		contractDecl.Author = contractNatSpec.Author
 		contractDecl.Description = contractNatSpec.Description
+		contractDecl.Notes = newNotes(contractNatSpec)
 
 		astFuncs := contract.Filter(func(node *astNode) bool {
 			return (node.NodeType == functionDefinitionType || node.NodeType == modifierDefinitionType) && node.hasDocs()
 		})
 
 		for _, astFunc := range astFuncs {
-			natSpec, err := parseNatSpec(astFunc.Documentation.Text)
+			natSpec, err := index.functionNatSpec(astFunc)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse function natspec: contract='%s', function='%s': %v", contract.Name, astFunc.Name, err)
 			}
@@ -442,6 +964,7 @@ func parseArtifact(artifact *artifact) ([]*ContractDef, error) {
 			funcDecl := FunctionDef{
 				Name:        funcName,
 				Description: natSpec.Description,
+				Notes:       newNotes(natSpec),
 				Pos:         pos,
 				IsModifier:  astFunc.NodeType == modifierDefinitionType,
 			}
@@ -454,9 +977,9 @@ func parseArtifact(artifact *artifact) ([]*ContractDef, error) {
 				if err := json.Unmarshal(astFunc.Parameters, &inputParams); err != nil {
 					return nil, err
 				}
-				fields, err := fillSpecTypes(natSpec.Param, inputParams.Parameters)
+				fields, err := fillSpecTypes("@param", natSpec.Param, inputParams.Parameters)
 				if err != nil {
-					return nil, err
+					return nil, fmt.Errorf("failed to parse %s function %s: %v", contract.Name, astFunc.Name, err)
 				}
 				funcDecl.Input = fields
 			}
@@ -467,7 +990,7 @@ func parseArtifact(artifact *artifact) ([]*ContractDef, error) {
 				if err := json.Unmarshal(astFunc.ReturnParameters.Parameters, &astOutputs); err != nil {
 					return nil, err
 				}
-				fields, err := fillSpecTypes(natSpec.Return, astOutputs)
+				fields, err := fillSpecTypes("@return", natSpec.Return, astOutputs)
 				if err != nil {
 					return nil, fmt.Errorf("failed to parse %s function %s: %v", contract.Name, astFunc.Name, err)
 				}
@@ -482,109 +1005,94 @@ func parseArtifact(artifact *artifact) ([]*ContractDef, error) {
 	return contractDecls, nil
 }
 
-func fillSpecTypes(natValues []natSpecValue, astValues []*astNode) ([]*Field, error) {
-	if len(natValues) != len(astValues) {
-		return nil, fmt.Errorf("incorrect size?")
+func findPrecompile(precompiles map[uint64]*PrecompileDef, name string) *PrecompileDef {
+	for _, precompile := range precompiles {
+		if precompile.Name == name {
+			return precompile
+		}
 	}
+	return nil
+}
 
-	fields := []*Field{}
-	for indx, val := range natValues {
-		astVal := astValues[indx]
+// parameters returns the parameters of a function, event or error.
+func (a *astNode) parameters() ([]*astNode, error) {
+	var list astNode
+	if err := json.Unmarshal(a.Parameters, &list); err != nil {
+		return nil, err
+	}
+	return list.parameterList()
+}
 
-		// validate that they have the same name if there is any in the ast
-		if astVal.Name != "" {
-			if astVal.Name != val.Name {
-				return nil, fmt.Errorf("error 2")
+// declarationFields returns the parameters of an event or an error.
+// They are listed even if they are not documented.
+func declarationFields(natValues []natSpecValue, astValues []*astNode) ([]*Field, error) {
+	var fields []*Field
+	if len(natValues) == 0 {
+		for _, astVal := range astValues {
+			typeName, err := parseTypeName(astVal.TypeName)
+			if err != nil {
+				return nil, err
 			}
+			fields = append(fields, &Field{Name: astVal.Name, Type: typeName.String(), TypeName: typeName})
 		}
-
-		field := &Field{
-			Name:        val.Name,
-			Description: val.Description,
-		}
-
-		if astVal.TypeName.NodeType == "ElementaryTypeName" {
-			// just append the type
-			field.Type = astVal.TypeName.Name
-		} else if astVal.TypeName.NodeType == "UserDefinedTypeName" {
-			// find the resource reference.... it is the same!
-			field.TypeReference = astVal.TypeName.ReferencedDeclaration
-			field.Type = astVal.TypeName.PathNode.Name
-		} else if astVal.TypeName.NodeType == "ArrayTypeName" {
-			// TODO: Fix
-			field.Type = astVal.TypeName.Name
-		} else {
-			return nil, fmt.Errorf("not found %s", astVal.TypeName.NodeType)
+	} else {
+		var err error
+		if fields, err = fillSpecTypes("@param", natValues, astValues); err != nil {
+			return nil, err
 		}
-
-		fields = append(fields, field)
 	}
-
+	for indx, field := range fields {
+		field.Indexed = astValues[indx].Indexed
+	}
 	return fields, nil
 }
 
-type natSpec struct {
-	Description string
-	Param       []natSpecValue
-	Return      []natSpecValue
-}
-
-type natSpecValue struct {
-	Name, Description string
-}
-
-func parseNatSpec(txt string) (*natSpec, error) {
-	spec := &natSpec{
-		Param:  []natSpecValue{},
-		Return: []natSpecValue{},
+// specMismatches compares the @param (or @return) values of a natspec with the
+// parameters in the ast. The parameters without a name in the ast match any value.
+func specMismatches(tag string, natValues []natSpecValue, astValues []*astNode) []string {
+	mismatches := []string{}
+	if len(natValues) != len(astValues) {
+		names := []string{}
+		for _, val := range natValues {
+			names = append(names, val.Name)
+		}
+		mismatches = append(mismatches, fmt.Sprintf("%d %s tags (%s) for %d parameters", len(natValues), tag, strings.Join(names, ", "), len(astValues)))
 	}
-
-	lines := strings.Split(txt, "\n")
-	for _, line := range lines {
-		line = strings.Trim(line, " ")
-
-		if !strings.HasPrefix(line, "@") {
-			return nil, fmt.Errorf("no @ found at the beginning of natspec")
+	for indx, val := range natValues {
+		if indx >= len(astValues) {
+			break
+		}
+		if name := astValues[indx].Name; name != "" && name != val.Name {
+			mismatches = append(mismatches, fmt.Sprintf("%s '%s' does not match the parameter '%s' at position %d", tag, val.Name, name, indx))
 		}
+	}
+	return mismatches
+}
 
-		consumeNextWord := func() (string, bool) {
-			whitespaceIndx := strings.Index(line, " ")
-			if whitespaceIndx == -1 {
-				return "", false
-			}
+func fillSpecTypes(tag string, natValues []natSpecValue, astValues []*astNode) ([]*Field, error) {
+	if mismatches := specMismatches(tag, natValues, astValues); len(mismatches) != 0 {
+		return nil, fmt.Errorf("%s", mismatches[0])
+	}
 
-			word := line[:whitespaceIndx]
-			line = line[whitespaceIndx+1:]
+	fields := []*Field{}
+	for indx, val := range natValues {
+		astVal := astValues[indx]
 
-			return word, true
+		typeName, err := parseTypeName(astVal.TypeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the type of '%s': %v", val.Name, err)
 		}
-
-		natspecPrefix, ok := consumeNextWord()
-		if !ok {
-			return nil, fmt.Errorf("bad 1")
+		field := &Field{
+			Name:        val.Name,
+			Description: val.Description,
+			Type:        typeName.String(),
+			TypeName:    typeName,
 		}
 
-		if natspecPrefix == "@notice" {
-			spec.Description = line
-		} else if natspecPrefix == "@param" || natspecPrefix == "@return" {
-			valName, ok := consumeNextWord()
-			if !ok {
-				return nil, fmt.Errorf("bad 2")
-			}
-
-			val := natSpecValue{
-				Name:        valName,
-				Description: line,
-			}
-			if natspecPrefix == "@param" {
-				spec.Param = append(spec.Param, val)
-			} else {
-				spec.Return = append(spec.Return, val)
-			}
-		}
+		fields = append(fields, field)
 	}
 
-	return spec, nil
+	return fields, nil
 }
 
 type sourceUnit struct {
@@ -606,20 +1114,29 @@ type Pos struct {
 	ToLine   uint64 `json:"to_line"`
 }
 
-func (s *sourceUnit) Decode(position string) (*Pos, error) {
+// decodeSrc returns the offset and the length of a position.
+func decodeSrc(position string) (int, int, error) {
 	// position comes in the format <offset><length><something else?>
 	parts := strings.Split(position, ":")
 	if len(parts) != 3 {
-		return nil, fmt.Errorf("pos format not expected %s", position)
+		return 0, 0, fmt.Errorf("pos format not expected %s", position)
 	}
 
 	offset, err := strconv.Atoi(parts[0])
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse int '%s': %v", parts[0], err)
+		return 0, 0, fmt.Errorf("failed to parse int '%s': %v", parts[0], err)
 	}
 	length, err := strconv.Atoi(parts[1])
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse int '%s': %v", parts[1], err)
+		return 0, 0, fmt.Errorf("failed to parse int '%s': %v", parts[1], err)
+	}
+	return offset, length, nil
+}
+
+func (s *sourceUnit) Decode(position string) (*Pos, error) {
+	offset, length, err := decodeSrc(position)
+	if err != nil {
+		return nil, err
 	}
 
 	from := s.FindLineCol(uint64(offset))
@@ -628,6 +1145,39 @@ func (s *sourceUnit) Decode(position string) (*Pos, error) {
 	return &Pos{FromLine: from, ToLine: to}, nil
 }
 
+// Text returns the source code at the position.
+func (s *sourceUnit) Text(position string) (string, error) {
+	offset, length, err := decodeSrc(position)
+	if err != nil {
+		return "", err
+	}
+	source := strings.Join(s.Lines, "\n")
+	if offset < 0 || length < 0 || offset+length > len(source) {
+		return "", fmt.Errorf("position %s out of the source", position)
+	}
+	return source[offset : offset+length], nil
+}
+
+// constantValue returns the value of a constant, either a literal or the
+// expression as it is written in the source.
+func (s *sourceUnit) constantValue(raw json.RawMessage) (string, error) {
+	var value astNode
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", err
+	}
+	if value.NodeType == "Literal" {
+		var literal string
+		if err := json.Unmarshal(value.Value, &literal); err != nil {
+			return "", err
+		}
+		if value.Kind == "string" {
+			literal = strconv.Quote(literal)
+		}
+		return literal, nil
+	}
+	return s.Text(value.Src)
+}
+
 func (s *sourceUnit) FindLineCol(pos uint64) uint64 {
 	var line, count uint64
 	for line = 0; line < uint64(len(s.Lines)); line++ {
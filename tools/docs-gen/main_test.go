@@ -1,7 +1,12 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -16,8 +21,8 @@ func TestDecodeNatspec(t *testing.T) {
 @return value is the random number`,
 			&natSpec{
 				Description: "Calculate tree age in years, rounded up, for live trees",
-				Param:       []*natValue{},
-				Return: []*natValue{
+				Param:       []natSpecValue{},
+				Return: []natSpecValue{
 					{Name: "value", Description: "is the random number"},
 				},
 			},
@@ -29,12 +34,12 @@ func TestDecodeNatspec(t *testing.T) {
 @return c is the first return value`,
 			&natSpec{
 				Description: "Calculate tree age in years, rounded up, for live trees",
-				Param: []*natValue{
+				Param: []natSpecValue{
 					{Name: "a", Description: "is the first value"},
 					{Name: "b", Description: "is the second value"},
 				},
-				Return: []*natValue{
-					{Name: "c", Description: "is the fist return value"},
+				Return: []natSpecValue{
+					{Name: "c", Description: "is the first return value"},
 				},
 			},
 		},
@@ -52,3 +57,426 @@ func TestDecodeNatspec(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeName(t *testing.T) {
+	cases := []struct {
+		node     string
+		str      string
+		markdown string
+	}{
+		{
+			`{"nodeType": "ElementaryTypeName", "name": "address", "stateMutability": "payable"}`,
+			"address payable",
+			"`address payable`",
+		},
+		{
+			`{"nodeType": "UserDefinedTypeName", "pathNode": {"name": "Suave.DataId"}, "referencedDeclaration": 1}`,
+			"Suave.DataId",
+			"[Suave.DataId](#dataid)",
+		},
+		{
+			`{"nodeType": "ArrayTypeName", "baseType": {"nodeType": "UserDefinedTypeName", "pathNode": {"name": "Withdrawal"}, "referencedDeclaration": 2}}`,
+			"Withdrawal[]",
+			"[Withdrawal](#withdrawal)`[]`",
+		},
+		{
+			// nested arrays of fixed size
+			`{"nodeType": "ArrayTypeName", "length": {"nodeType": "Literal", "value": "3"}, "baseType": {"nodeType": "ArrayTypeName", "baseType": {"nodeType": "ElementaryTypeName", "name": "bytes32"}}}`,
+			"bytes32[][3]",
+			"`bytes32[][3]`",
+		},
+		{
+			`{"nodeType": "ArrayTypeName", "length": {"nodeType": "Identifier", "name": "SIZE"}, "baseType": {"nodeType": "UserDefinedTypeName", "pathNode": {"name": "Unknown"}, "referencedDeclaration": 3}}`,
+			"Unknown[SIZE]",
+			"`Unknown[SIZE]`",
+		},
+		{
+			`{"nodeType": "Mapping", "keyType": {"nodeType": "UserDefinedTypeName", "pathNode": {"name": "Suave.DataId"}, "referencedDeclaration": 1}, "valueType": {"nodeType": "Mapping", "keyType": {"nodeType": "ElementaryTypeName", "name": "string"}, "valueType": {"nodeType": "ElementaryTypeName", "name": "bytes"}}}`,
+			"mapping(Suave.DataId => mapping(string => bytes))",
+			"`mapping(`[Suave.DataId](#dataid) `=> mapping(string => bytes))`",
+		},
+		{
+			`{"nodeType": "FunctionTypeName", "visibility": "external", "stateMutability": "view", "parameterTypes": {"parameters": [{"typeName": {"nodeType": "ElementaryTypeName", "name": "uint256"}}]}, "returnParameterTypes": {"parameters": [{"typeName": {"nodeType": "ElementaryTypeName", "name": "bool"}}]}}`,
+			"function(uint256) external view returns (bool)",
+			"`function(uint256) external view returns (bool)`",
+		},
+	}
+
+	// only the types 1 and 2 are documented
+	link := func(ref *TypeName) (string, bool) {
+		if ref.Reference == 3 {
+			return "", false
+		}
+		name := ref.Name[strings.LastIndex(ref.Name, ".")+1:]
+		return fmt.Sprintf("[%s](#%s)", ref.Name, strings.ToLower(name)), true
+	}
+
+	for _, c := range cases {
+		var node astNode
+		if err := json.Unmarshal([]byte(c.node), &node); err != nil {
+			t.Fatal(err)
+		}
+		typ, err := parseTypeName(&node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if str := typ.String(); str != c.str {
+			t.Fatalf("expected '%s' but found '%s'", c.str, str)
+		}
+		if markdown := typ.Markdown(link); markdown != c.markdown {
+			t.Fatalf("expected '%s' but found '%s'", c.markdown, markdown)
+		}
+	}
+}
+
+func TestApplyTemplate_SourceUnits(t *testing.T) {
+	outPath = t.TempDir()
+
+	legacyType := func(id uint64, name string) *TypeName {
+		return &TypeName{Kind: userDefinedTypeKind, Name: name, Reference: id}
+	}
+	pos := &Pos{FromLine: 1, ToLine: 2}
+
+	contracts := []*ContractDef{
+		{
+			Name:        "Transactions",
+			Path:        "src/Transactions.sol",
+			Description: "Transactions library",
+			Structs:     []StructRef{{ID: 1, Name: "Legacy", Pos: pos, Description: "legacy transaction"}},
+		},
+		{
+			Name:        "TransactionsHelper",
+			Path:        "src/Transactions.sol",
+			Description: "Helper library",
+			Structs:     []StructRef{{ID: 2, Name: "Legacy", Pos: pos, Description: "another legacy transaction"}},
+			Functions: []FunctionDef{
+				{Name: "encode", Pos: pos, Description: "encode", Input: []*Field{{Name: "txn", TypeName: legacyType(1, "Transactions.Legacy")}}},
+			},
+		},
+		{
+			Name:        "Bundle",
+			Path:        "src/protocols/Bundle.sol",
+			Description: "Bundle library",
+			Functions: []FunctionDef{
+				{Name: "send", Pos: pos, Description: "send", Input: []*Field{
+					{Name: "txn", TypeName: legacyType(1, "Transactions.Legacy")},
+					{Name: "txns", TypeName: &TypeName{Kind: arrayTypeKind, Base: legacyType(2, "TransactionsHelper.Legacy")}},
+				}},
+			},
+		},
+	}
+
+	units := groupSourceUnits(contracts)
+	if len(units) != 2 {
+		t.Fatalf("expected 2 source units but found %d", len(units))
+	}
+	for _, unit := range units {
+		if err := applyTemplate(units, unit); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(path string) string {
+		data, err := os.ReadFile(filepath.Join(outPath, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	transactions := read("Transactions.mdx")
+	for _, expected := range []string{
+		"# Transactions\n",
+		"## Transactions\n",
+		"## TransactionsHelper\n",
+		"### Functions",
+		"#### [Legacy](https://github.com/flashbots/suave-std/tree/main/src/Transactions.sol#L1) {#transactions-legacy}",
+		"#### [Legacy](https://github.com/flashbots/suave-std/tree/main/src/Transactions.sol#L1) {#transactionshelper-legacy}",
+		"([Transactions.Legacy](#transactions-legacy))",
+	} {
+		if !strings.Contains(transactions, expected) {
+			t.Fatalf("'%s' not found in the page:\n%s", expected, transactions)
+		}
+	}
+
+	bundle := read("protocols/Bundle.mdx")
+	for _, expected := range []string{
+		"# Bundle\n",
+		"## Functions",
+		"([Transactions.Legacy](../Transactions.mdx#transactions-legacy))",
+		"([TransactionsHelper.Legacy](../Transactions.mdx#transactionshelper-legacy)`[]`)",
+	} {
+		if !strings.Contains(bundle, expected) {
+			t.Fatalf("'%s' not found in the page:\n%s", expected, bundle)
+		}
+	}
+}
+
+func TestParseNatSpec(t *testing.T) {
+	cases := []struct {
+		str  string
+		spec *natSpec
+	}{
+		{
+			// multi-line tags in a block comment
+			` * @title RLPWriter
+ * @author RLPWriter is a library for encoding Solidity types to RLP bytes. Adapted from Bakaoh's
+ *         RLPEncode library with minor
+ *         modifications to improve legibility.
+ * @custom:attribution https://github.com/bakaoh/solidity-rlp-encode`,
+			&natSpec{
+				Title:  "RLPWriter",
+				Author: "RLPWriter is a library for encoding Solidity types to RLP bytes. Adapted from Bakaoh's RLPEncode library with minor modifications to improve legibility.",
+				Param:  []natSpecValue{},
+				Return: []natSpecValue{},
+				Custom: []natSpecValue{
+					{Name: "attribution", Description: "https://github.com/bakaoh/solidity-rlp-encode"},
+				},
+			},
+		},
+		{
+			// the text without a tag is the notice
+			` Sends a bundle
+ to the relay.
+ @dev The bundle is not simulated.
+ @param bundle the bundle
+ to send
+ @return ok whether
+ the bundle was sent`,
+			&natSpec{
+				Description: "Sends a bundle to the relay.",
+				Dev:         "The bundle is not simulated.",
+				Param:       []natSpecValue{{Name: "bundle", Description: "the bundle to send"}},
+				Return:      []natSpecValue{{Name: "ok", Description: "whether the bundle was sent"}},
+			},
+		},
+		{
+			` @inheritdoc Backend`,
+			&natSpec{
+				Param:      []natSpecValue{},
+				Return:     []natSpecValue{},
+				InheritDoc: "Backend",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		spec, err := parseNatSpec(c.str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(spec, c.spec) {
+			t.Fatalf("expected %+v but found %+v", c.spec, spec)
+		}
+	}
+
+	for _, str := range []string{"@param", "@custom: value", "@inheritdoc", "@unknown tag"} {
+		if _, err := parseNatSpec(str); err == nil {
+			t.Fatalf("expected an error for '%s'", str)
+		}
+	}
+}
+
+func TestInheritDoc(t *testing.T) {
+	// Impl inherits from Base, which inherits from Iface
+	ast := `{
+		"nodeType": "SourceUnit",
+		"nodes": [
+			{"nodeType": "ContractDefinition", "name": "Iface", "nodes": [
+				{"nodeType": "FunctionDefinition", "name": "send", "documentation": {"text": "@notice Sends the data\n@dev Only to the relay\n@param data the data\n@param to the target\n@custom:security none"},
+				 "parameters": {"parameters": [{"name": "data", "typeName": {"nodeType": "ElementaryTypeName", "name": "bytes"}}, {"name": "to", "typeName": {"nodeType": "ElementaryTypeName", "name": "address"}}]}},
+				{"nodeType": "FunctionDefinition", "name": "send", "documentation": {"text": "@notice Sends nothing"},
+				 "parameters": {"parameters": []}}
+			]},
+			{"nodeType": "ContractDefinition", "name": "Base", "baseContracts": [{"baseName": {"name": "Iface"}}]},
+			{"nodeType": "ContractDefinition", "name": "Impl", "baseContracts": [{"baseName": {"name": "Base"}}], "nodes": [
+				{"nodeType": "FunctionDefinition", "name": "send", "documentation": {"text": "@inheritdoc Base\n@param to the address of the relay"},
+				 "parameters": {"parameters": [{"name": "data", "typeName": {"nodeType": "ElementaryTypeName", "name": "bytes"}}, {"name": "to", "typeName": {"nodeType": "ElementaryTypeName", "name": "address"}}]}},
+				{"nodeType": "FunctionDefinition", "name": "send", "documentation": {"text": "@inheritdoc Other"},
+				 "parameters": {"parameters": []}}
+			]}
+		]
+	}`
+
+	var sourceUnit astNode
+	if err := json.Unmarshal([]byte(ast), &sourceUnit); err != nil {
+		t.Fatal(err)
+	}
+	index := newContractIndex([]*artifact{{Ast: &sourceUnit}})
+
+	impl := index["Impl"]
+	spec, err := index.functionNatSpec(&impl.Nodes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &natSpec{
+		Description: "Sends the data",
+		Dev:         "Only to the relay",
+		Param: []natSpecValue{
+			{Name: "data", Description: "the data"},
+			{Name: "to", Description: "the address of the relay"},
+		},
+		Return: []natSpecValue{},
+		Custom: []natSpecValue{{Name: "security", Description: "none"}},
+	}
+	if !reflect.DeepEqual(spec, expected) {
+		t.Fatalf("expected %+v but found %+v", expected, spec)
+	}
+
+	if _, err := index.functionNatSpec(&impl.Nodes[1]); err == nil || !strings.Contains(err.Error(), "contract 'Other' not found") {
+		t.Fatalf("expected an error for an unknown contract but found %v", err)
+	}
+}
+
+func TestSignature(t *testing.T) {
+	decls := declarationIndex{
+		1: {NodeType: "StructDefinition", Name: "Withdrawal", Members: []*astNode{
+			{Name: "index", TypeName: &astNode{NodeType: "ElementaryTypeName", Name: "uint64"}},
+			{Name: "Address", TypeName: &astNode{NodeType: "ElementaryTypeName", Name: "address"}},
+		}},
+		2: {NodeType: "EnumDefinition", Name: "CryptoSignature"},
+		3: {NodeType: "UserDefinedValueTypeDefinition", Name: "DataId", UnderlyingType: &astNode{NodeType: "ElementaryTypeName", Name: "bytes16"}},
+	}
+	field := func(typ *TypeName) *Field {
+		return &Field{TypeName: typ}
+	}
+	elementary := func(name string) *TypeName {
+		return &TypeName{Kind: elementaryTypeKind, Name: name}
+	}
+
+	signature, hash, err := decls.signature("Transfer", []*Field{
+		field(elementary("address")), field(elementary("address payable")), field(elementary("uint")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signature != "Transfer(address,address,uint256)" || hexEncode(hash) != "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef" {
+		t.Fatalf("unexpected signature %s (%s)", signature, hexEncode(hash))
+	}
+
+	signature, _, err = decls.signature("Stored", []*Field{
+		field(&TypeName{Kind: arrayTypeKind, Length: "2", Base: &TypeName{Kind: userDefinedTypeKind, Name: "Suave.Withdrawal", Reference: 1}}),
+		field(&TypeName{Kind: userDefinedTypeKind, Name: "Suave.CryptoSignature", Reference: 2}),
+		field(&TypeName{Kind: userDefinedTypeKind, Name: "Suave.DataId", Reference: 3}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signature != "Stored((uint64,address)[2],uint8,bytes16)" {
+		t.Fatalf("unexpected signature %s", signature)
+	}
+
+	if _, _, err := decls.signature("Unknown", []*Field{field(&TypeName{Kind: userDefinedTypeKind, Name: "Unknown", Reference: 4})}); err == nil {
+		t.Fatal("expected an error for an unknown type")
+	}
+}
+
+func TestConstantValue(t *testing.T) {
+	source := &sourceUnit{Lines: []string{
+		`bytes32 public constant TOPIC = keccak256("topic");`,
+		`string public constant NAME = "suave";`,
+	}}
+
+	cases := map[string]string{
+		`{"nodeType": "Literal", "kind": "number", "value": "0x0000000000000000000000000000000042010000"}`: "0x0000000000000000000000000000000042010000",
+		`{"nodeType": "Literal", "kind": "string", "value": "suave"}`:                                      `"suave"`,
+		`{"nodeType": "FunctionCall", "src": "32:18:0"}`:                                                   `keccak256("topic")`,
+	}
+	for raw, expected := range cases {
+		value, err := source.constantValue(json.RawMessage(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != expected {
+			t.Fatalf("expected '%s' but found '%s'", expected, value)
+		}
+	}
+}
+
+func TestLintSourceUnit(t *testing.T) {
+	source := &sourceUnit{Lines: []string{
+		`contract C {`,
+		`  /// @notice a struct`,
+		`  /// @param a the a`,
+		`  /// @param c the c`,
+		`  struct S { uint a; uint b; }`,
+		`  function f(uint x) public returns (uint) {}`,
+		`  /// @notice g`,
+		`  /// @param y the y`,
+		`  /// @return z the z`,
+		`  function g(uint x) internal returns (uint) {}`,
+		`  function h() private {}`,
+		`}`,
+	}}
+	ast := `{
+		"nodeType": "SourceUnit",
+		"absolutePath": "src/C.sol",
+		"nodes": [
+			{"nodeType": "ContractDefinition", "name": "C", "nodes": [
+				{"nodeType": "StructDefinition", "name": "S", "src": "80:28:0", "documentation": {"text": "@notice a struct\n@param a the a\n@param c the c"},
+				 "members": [{"name": "a", "src": "91:6:0"}, {"name": "b", "src": "99:6:0"}]},
+				{"nodeType": "FunctionDefinition", "name": "f", "kind": "function", "visibility": "public", "src": "111:43:0",
+				 "parameters": {"parameters": [{"name": "x"}]}, "returnParameters": {"parameters": [{"name": ""}]}},
+				{"nodeType": "FunctionDefinition", "name": "g", "kind": "function", "visibility": "internal", "src": "216:45:0",
+				 "documentation": {"text": "@notice g\n@param y the y\n@return z the z"},
+				 "parameters": {"parameters": [{"name": "x"}]}, "returnParameters": {"parameters": [{"name": ""}]}},
+				{"nodeType": "FunctionDefinition", "name": "h", "kind": "function", "visibility": "private", "src": "264:23:0",
+				 "parameters": {"parameters": []}, "returnParameters": {"parameters": []}}
+			]}
+		]
+	}`
+
+	var sourceAst astNode
+	if err := json.Unmarshal([]byte(ast), &sourceAst); err != nil {
+		t.Fatal(err)
+	}
+	report := newLintReport(50)
+	if err := lintSourceUnit(&sourceAst, source, newContractIndex([]*artifact{{Ast: &sourceAst}}), report); err != nil {
+		t.Fatal(err)
+	}
+	report.finish()
+
+	expected := []lintIssue{
+		{File: "src/C.sol", Line: 5, Kind: missingIssueKind, Message: "field 'b' of struct 'C.S' is missing a @param"},
+		{File: "src/C.sol", Line: 5, Kind: mismatchIssueKind, Message: "@param 'c' of struct 'C.S' is not a field"},
+		{File: "src/C.sol", Line: 6, Kind: missingIssueKind, Message: "function 'C.f' is missing natspec"},
+		{File: "src/C.sol", Line: 10, Kind: mismatchIssueKind, Message: "function 'C.g': @param 'y' does not match the parameter 'x' at position 0"},
+	}
+	if !reflect.DeepEqual(report.Issues, expected) {
+		t.Fatalf("expected %+v but found %+v", expected, report.Issues)
+	}
+	if report.Documented != 3 || report.Total != 5 || report.Coverage != 60 {
+		t.Fatalf("unexpected coverage %.1f (%d/%d)", report.Coverage, report.Documented, report.Total)
+	}
+	// the coverage is above the minimum but the natspec of g does not match
+	if report.Passed {
+		t.Fatal("expected the lint to fail")
+	}
+
+	var out strings.Builder
+	if err := report.write(&out, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "src/C.sol:5: field 'b' of struct 'C.S' is missing a @param\n") || !strings.HasSuffix(out.String(), "natspec coverage: 60.0% (3/5)\n") {
+		t.Fatalf("unexpected report:\n%s", out.String())
+	}
+}
+
+func TestFillSpecTypes_Mismatch(t *testing.T) {
+	astValues := []*astNode{
+		{Name: "a", TypeName: &astNode{NodeType: "ElementaryTypeName", Name: "uint256"}},
+		{Name: "b", TypeName: &astNode{NodeType: "ElementaryTypeName", Name: "bytes"}},
+	}
+	cases := []struct {
+		natValues []natSpecValue
+		err       string
+	}{
+		{[]natSpecValue{{Name: "a"}}, "1 @param tags (a) for 2 parameters"},
+		{[]natSpecValue{{Name: "a"}, {Name: "c"}}, "@param 'c' does not match the parameter 'b' at position 1"},
+	}
+	for _, c := range cases {
+		if _, err := fillSpecTypes("@param", c.natValues, astValues); err == nil || err.Error() != c.err {
+			t.Fatalf("expected '%s' but found %v", c.err, err)
+		}
+	}
+}
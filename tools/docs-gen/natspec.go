@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type natSpec struct {
+	Title       string
+	Author      string
+	Description string
+	Dev         string
+	Param       []natSpecValue
+	Return      []natSpecValue
+	// Custom are the @custom:<name> tags
+	Custom []natSpecValue
+	// InheritDoc is the contract set in @inheritdoc
+	InheritDoc string
+}
+
+type natSpecValue struct {
+	Name, Description string
+}
+
+// parseNatSpec parses the text of a natspec comment. The lines that do not start
+// with a tag continue the previous tag, or the @notice if there is no tag before.
+func parseNatSpec(txt string) (*natSpec, error) {
+	spec := &natSpec{
+		Param:  []natSpecValue{},
+		Return: []natSpecValue{},
+	}
+
+	// text is the value of the tag being parsed
+	var text *string
+
+	lines := strings.Split(txt, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		// the lines of the /** */ comments start with '*'
+		line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "@") {
+			if text == nil {
+				text = &spec.Description
+			}
+			appendText(text, line)
+			continue
+		}
+
+		tag, line := splitWord(line)
+		switch {
+		case tag == "@notice":
+			text = &spec.Description
+		case tag == "@dev":
+			text = &spec.Dev
+		case tag == "@title":
+			text = &spec.Title
+		case tag == "@author":
+			text = &spec.Author
+
+		case tag == "@param" || tag == "@return":
+			var name string
+			if name, line = splitWord(line); name == "" {
+				return nil, fmt.Errorf("%s tag without a name", tag)
+			}
+			dst := &spec.Param
+			if tag == "@return" {
+				dst = &spec.Return
+			}
+			*dst = append(*dst, natSpecValue{Name: name})
+			text = &(*dst)[len(*dst)-1].Description
+
+		case strings.HasPrefix(tag, "@custom:"):
+			name := strings.TrimPrefix(tag, "@custom:")
+			if name == "" {
+				return nil, fmt.Errorf("@custom tag without a name")
+			}
+			spec.Custom = append(spec.Custom, natSpecValue{Name: name})
+			text = &spec.Custom[len(spec.Custom)-1].Description
+
+		case tag == "@inheritdoc":
+			if spec.InheritDoc, line = splitWord(line); spec.InheritDoc == "" {
+				return nil, fmt.Errorf("@inheritdoc tag without a contract")
+			}
+			if line != "" {
+				return nil, fmt.Errorf("unexpected text after @inheritdoc %s: '%s'", spec.InheritDoc, line)
+			}
+			text = nil
+			continue
+
+		default:
+			return nil, fmt.Errorf("unknown natspec tag '%s'", tag)
+		}
+
+		appendText(text, line)
+	}
+
+	return spec, nil
+}
+
+// splitWord returns the first word of the line and the rest of the line.
+func splitWord(line string) (string, string) {
+	word, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	return word, strings.TrimSpace(rest)
+}
+
+// appendText continues the text of a tag with a new line of the comment.
+func appendText(text *string, line string) {
+	if line == "" {
+		return
+	}
+	if *text == "" {
+		*text = line
+	} else {
+		*text += " " + line
+	}
+}
+
+// inherit copies from the natspec of the base function the tags that are not set.
+func (n *natSpec) inherit(base *natSpec) {
+	if n.Description == "" {
+		n.Description = base.Description
+	}
+	if n.Dev == "" {
+		n.Dev = base.Dev
+	}
+	n.Param = inheritValues(n.Param, base.Param)
+	n.Return = inheritValues(n.Return, base.Return)
+	for _, custom := range base.Custom {
+		if !hasValue(n.Custom, custom.Name) {
+			n.Custom = append(n.Custom, custom)
+		}
+	}
+	n.InheritDoc = ""
+}
+
+// inheritValues merges the params (or returns) in the order of the base function.
+func inheritValues(values, base []natSpecValue) []natSpecValue {
+	res := []natSpecValue{}
+	for _, baseValue := range base {
+		value := baseValue
+		for _, v := range values {
+			if v.Name == baseValue.Name {
+				value = v
+			}
+		}
+		res = append(res, value)
+	}
+	for _, v := range values {
+		if !hasValue(base, v.Name) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func hasValue(values []natSpecValue, name string) bool {
+	for _, v := range values {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// contractIndex has the contracts of all the source units by name
+// to resolve the @inheritdoc tags.
+type contractIndex map[string]*astNode
+
+func newContractIndex(artifacts []*artifact) contractIndex {
+	index := contractIndex{}
+	for _, artifact := range artifacts {
+		for _, contract := range artifact.Ast.Filter(func(node *astNode) bool {
+			return node.NodeType == contractDefinitionType
+		}) {
+			index[contract.Name] = contract
+		}
+	}
+	return index
+}
+
+// functionNatSpec parses the natspec of a function or modifier and
+// resolves its @inheritdoc tag through the inheritance chain.
+func (c contractIndex) functionNatSpec(fn *astNode) (*natSpec, error) {
+	spec, err := parseNatSpec(fn.Documentation.Text)
+	if err != nil {
+		return nil, err
+	}
+	if spec.InheritDoc == "" {
+		return spec, nil
+	}
+
+	base, err := c.findFunction(spec.InheritDoc, fn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve @inheritdoc %s: %v", spec.InheritDoc, err)
+	}
+	if base.hasDocs() {
+		baseSpec, err := c.functionNatSpec(base)
+		if err != nil {
+			return nil, err
+		}
+		spec.inherit(baseSpec)
+	}
+	return spec, nil
+}
+
+// findFunction finds the function with the same signature as fn in the
+// contract or in the contracts it inherits from.
+func (c contractIndex) findFunction(contractName string, fn *astNode) (*astNode, error) {
+	signature, err := functionSignature(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := map[string]bool{}
+	queue := []string{contractName}
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+
+		contract, ok := c[name]
+		if !ok {
+			return nil, fmt.Errorf("contract '%s' not found", name)
+		}
+		for i := range contract.Nodes {
+			node := &contract.Nodes[i]
+			if node.NodeType != fn.NodeType || node.Name != fn.Name {
+				continue
+			}
+			if nodeSignature, err := functionSignature(node); err == nil && nodeSignature == signature {
+				return node, nil
+			}
+		}
+		for _, base := range contract.BaseContracts {
+			if base.BaseName != nil {
+				queue = append(queue, base.BaseName.Name)
+			}
+		}
+	}
+	return nil, fmt.Errorf("function '%s' not found", signature)
+}
+
+// functionSignature returns the name and the parameter types of a function.
+func functionSignature(fn *astNode) (string, error) {
+	var inputParams struct {
+		Parameters []*astNode
+	}
+	if err := json.Unmarshal(fn.Parameters, &inputParams); err != nil {
+		return "", err
+	}
+	types := []string{}
+	for _, param := range inputParams.Parameters {
+		typeName, err := parseTypeName(param.TypeName)
+		if err != nil {
+			return "", err
+		}
+		types = append(types, typeName.String())
+	}
+	return fn.Name + "(" + strings.Join(types, ",") + ")", nil
+}
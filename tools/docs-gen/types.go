@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Kinds of TypeName
+const (
+	elementaryTypeKind  = "elementary"
+	userDefinedTypeKind = "user-defined"
+	arrayTypeKind       = "array"
+	mappingTypeKind     = "mapping"
+	functionTypeKind    = "function"
+)
+
+// TypeName is the type of a field. Arrays and mappings are described
+// by their element types so that each user defined type can be linked.
+type TypeName struct {
+	Kind string `json:"kind"`
+
+	// Name is the name of an elementary or user defined type as it is written
+	// in the source (i.e. Suave.DataId)
+	Name string `json:"name,omitempty"`
+	// Reference is the id of the declaration of a user defined type
+	Reference uint64 `json:"reference,omitempty"`
+
+	// Base and Length are set for the arrays. Length is empty for dynamic arrays.
+	Base   *TypeName `json:"base,omitempty"`
+	Length string    `json:"length,omitempty"`
+
+	// Key and Value are set for the mappings
+	Key   *TypeName `json:"key,omitempty"`
+	Value *TypeName `json:"value,omitempty"`
+
+	// Params and Returns are set for the function types
+	Params     []*TypeName `json:"params,omitempty"`
+	Returns    []*TypeName `json:"returns,omitempty"`
+	Visibility string      `json:"visibility,omitempty"`
+	Mutability string      `json:"mutability,omitempty"`
+}
+
+// parseTypeName decodes the type name node of a variable declaration.
+func parseTypeName(node *astNode) (*TypeName, error) {
+	if node == nil {
+		return nil, fmt.Errorf("type name not found")
+	}
+
+	switch node.NodeType {
+	case "ElementaryTypeName":
+		typ := &TypeName{Kind: elementaryTypeKind, Name: node.Name}
+		if node.Name == "address" && node.StateMutability == "payable" {
+			typ.Name = "address payable"
+		}
+		return typ, nil
+
+	case "UserDefinedTypeName":
+		typ := &TypeName{Kind: userDefinedTypeKind, Name: node.Name, Reference: node.ReferencedDeclaration}
+		if node.PathNode != nil {
+			typ.Name = node.PathNode.Name
+		}
+		return typ, nil
+
+	case "ArrayTypeName":
+		base, err := parseTypeName(node.BaseType)
+		if err != nil {
+			return nil, err
+		}
+		typ := &TypeName{Kind: arrayTypeKind, Base: base}
+		if node.Length != nil {
+			// the length is either a literal or a constant
+			typ.Length = node.Length.Name
+			if node.Length.NodeType == "Literal" {
+				if err := json.Unmarshal(node.Length.Value, &typ.Length); err != nil {
+					return nil, fmt.Errorf("failed to decode the array length: %v", err)
+				}
+			}
+		}
+		return typ, nil
+
+	case "Mapping":
+		key, err := parseTypeName(node.KeyType)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseTypeName(node.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		return &TypeName{Kind: mappingTypeKind, Key: key, Value: value}, nil
+
+	case "FunctionTypeName":
+		typ := &TypeName{Kind: functionTypeKind, Visibility: node.Visibility, Mutability: node.StateMutability}
+		for _, list := range []struct {
+			node *astNode
+			dst  *[]*TypeName
+		}{
+			{node.ParameterTypes, &typ.Params},
+			{node.ReturnParameterTypes, &typ.Returns},
+		} {
+			if list.node == nil {
+				continue
+			}
+			params, err := list.node.parameterList()
+			if err != nil {
+				return nil, err
+			}
+			for _, param := range params {
+				paramType, err := parseTypeName(param.TypeName)
+				if err != nil {
+					return nil, err
+				}
+				*list.dst = append(*list.dst, paramType)
+			}
+		}
+		return typ, nil
+	}
+
+	return nil, fmt.Errorf("unknown type name node '%s'", node.NodeType)
+}
+
+// parameterList returns the parameters of a ParameterList node.
+func (a *astNode) parameterList() ([]*astNode, error) {
+	var params []*astNode
+	if len(a.Parameters) == 0 {
+		return params, nil
+	}
+	if err := json.Unmarshal(a.Parameters, &params); err != nil {
+		return nil, fmt.Errorf("failed to decode the parameter list: %v", err)
+	}
+	return params, nil
+}
+
+// String returns the type as it is written in Solidity.
+func (t *TypeName) String() string {
+	var b strings.Builder
+	for _, s := range t.segments() {
+		if s.ref != nil {
+			b.WriteString(s.ref.Name)
+		} else {
+			b.WriteString(s.text)
+		}
+	}
+	return b.String()
+}
+
+// typeSegment is either plain text or a reference to a user defined type.
+type typeSegment struct {
+	text string
+	ref  *TypeName
+}
+
+// segments splits the type into the references to user defined types and the text between them.
+func (t *TypeName) segments() []typeSegment {
+	switch t.Kind {
+	case userDefinedTypeKind:
+		return []typeSegment{{ref: t}}
+
+	case arrayTypeKind:
+		return append(t.Base.segments(), typeSegment{text: "[" + t.Length + "]"})
+
+	case mappingTypeKind:
+		segments := []typeSegment{{text: "mapping("}}
+		segments = append(segments, t.Key.segments()...)
+		segments = append(segments, typeSegment{text: " => "})
+		segments = append(segments, t.Value.segments()...)
+		return append(segments, typeSegment{text: ")"})
+
+	case functionTypeKind:
+		segments := []typeSegment{{text: "function("}}
+		segments = appendTypeList(segments, t.Params)
+		segments = append(segments, typeSegment{text: ")"})
+		if t.Visibility != "" {
+			segments = append(segments, typeSegment{text: " " + t.Visibility})
+		}
+		if t.Mutability != "" && t.Mutability != "nonpayable" {
+			segments = append(segments, typeSegment{text: " " + t.Mutability})
+		}
+		if len(t.Returns) != 0 {
+			segments = append(segments, typeSegment{text: " returns ("})
+			segments = appendTypeList(segments, t.Returns)
+			segments = append(segments, typeSegment{text: ")"})
+		}
+		return segments
+	}
+
+	return []typeSegment{{text: t.Name}}
+}
+
+func appendTypeList(segments []typeSegment, types []*TypeName) []typeSegment {
+	for indx, typ := range types {
+		if indx != 0 {
+			segments = append(segments, typeSegment{text: ", "})
+		}
+		segments = append(segments, typ.segments()...)
+	}
+	return segments
+}
+
+// Markdown renders the type with a link for each user defined type that link resolves
+// and the rest of the type in code spans.
+func (t *TypeName) Markdown(link func(ref *TypeName) (string, bool)) string {
+	var b, text strings.Builder
+	flush := func() {
+		// the spaces at the edges of a code span are trimmed, keep them outside
+		str := text.String()
+		if code := strings.TrimSpace(str); code != "" {
+			b.WriteString(strings.Repeat(" ", len(str)-len(strings.TrimLeft(str, " "))))
+			b.WriteString("`" + code + "`")
+			b.WriteString(strings.Repeat(" ", len(str)-len(strings.TrimRight(str, " "))))
+		} else {
+			b.WriteString(str)
+		}
+		text.Reset()
+	}
+	for _, s := range t.segments() {
+		if s.ref == nil {
+			text.WriteString(s.text)
+			continue
+		}
+		if l, ok := link(s.ref); ok {
+			flush()
+			b.WriteString(l)
+		} else {
+			text.WriteString(s.ref.Name)
+		}
+	}
+	flush()
+	return b.String()
+}
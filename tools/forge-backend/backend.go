@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/suave-std/tools/suavelib"
+)
+
+// suaveGethBinary is the binary that serves the precompiles without a native implementation.
+const suaveGethBinary = "suave-geth"
+
+// precompileFunc runs a precompile with the abi encoded input and returns its output.
+type precompileFunc func(b *backend, input []byte) ([]byte, error)
+
+// precompiles are the precompiles implemented by the backend.
+var precompiles = map[common.Address]precompileFunc{
+	suavelib.RandomBytesAddr:         runRandomBytes,
+	suavelib.AesEncryptAddr:          runAesEncrypt,
+	suavelib.AesDecryptAddr:          runAesDecrypt,
+	suavelib.SignMessageAddr:         runSignMessage,
+	suavelib.SignEthTransactionAddr:  runSignEthTransaction,
+	suavelib.PrivateKeyGenAddr:       runPrivateKeyGen,
+	suavelib.GetInsecureTimeAddr:     runGetInsecureTime,
+	suavelib.DoHTTPRequestAddr:       runDoHTTPRequest,
+	suavelib.DoHTTPRequest2Addr:      runDoHTTPRequest2,
+	suavelib.NewBuilderAddr:          runNewBuilder,
+	suavelib.SimulateBundleAddr:      runSimulateBundle,
+	suavelib.SimulateTransactionAddr: runSimulateTransaction,
+}
+
+// backend runs the precompile calls of a forge test.
+type backend struct {
+	config *config
+	// configPath is the path to the foundry.toml file, the relative paths
+	// in the configuration are relative to its directory
+	configPath string
+	// session is the state of the test, it is nil if the Connector does not use sessions
+	session *session
+	// delegateArgs are the arguments of 'suave-geth forge' to delegate the call
+	delegateArgs []string
+}
+
+// revertError is the revert reason of a precompile served by suave-geth
+// or by a cassette. It is returned as is.
+type revertError string
+
+func (e revertError) Error() string {
+	return string(e)
+}
+
+// call runs the precompile at the address.
+func (b *backend) call(addr common.Address, input []byte) ([]byte, error) {
+	run, ok := precompiles[addr]
+	if !ok {
+		return b.delegate(addr)
+	}
+	output, err := run(b, input)
+	if err != nil {
+		var revert revertError
+		if errors.As(err, &revert) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %v", precompileName(addr), err)
+	}
+	return output, nil
+}
+
+// delegate runs the precompile with 'suave-geth forge'.
+func (b *backend) delegate(addr common.Address) ([]byte, error) {
+	if _, err := exec.LookPath(suaveGethBinary); err != nil {
+		return nil, fmt.Errorf("precompile %s is not implemented by forge-backend and %s is not installed", precompileName(addr), suaveGethBinary)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(suaveGethBinary, append([]string{"forge"}, b.delegateArgs...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, revertError(stderr.String())
+		}
+		return nil, err
+	}
+	output := strings.TrimSpace(stdout.String())
+	if output == "" {
+		return nil, nil
+	}
+	return hexutil.Decode(output)
+}
+
+// resolvePath returns the path relative to the directory of foundry.toml.
+func (b *backend) resolvePath(path string) string {
+	if filepath.IsAbs(path) || b.configPath == "" {
+		return path
+	}
+	return filepath.Join(filepath.Dir(b.configPath), path)
+}
+
+func precompileName(addr common.Address) string {
+	if name, ok := suavelib.PrecompileNames[addr]; ok {
+		return name
+	}
+	return addr.Hex()
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/flashbots/suave-std/tools/suavelib"
+	"github.com/flashbots/suave-std/tools/suavelib/diff"
+)
+
+// redactedHeaders are the headers whose values are not written in the cassettes
+// since they usually have credentials.
+var redactedHeaders = []string{"authorization", "x-api-key", "x-flashbots-signature", "cookie"}
+
+// cassette is a file with the http requests recorded with the doHTTPRequest
+// and doHTTPRequest2 precompiles, and their responses.
+type cassette struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+// interaction is a request recorded in a cassette.
+type interaction struct {
+	Precompile string           `json:"precompile"`
+	Request    *cassetteRequest `json:"request"`
+	// Response is nil if the precompile reverted
+	Response *cassetteResponse `json:"response,omitempty"`
+	// Revert is the revert reason of the precompile
+	Revert string `json:"revert,omitempty"`
+}
+
+type cassetteRequest struct {
+	Url                    string   `json:"url"`
+	Method                 string   `json:"method"`
+	Headers                []string `json:"headers,omitempty"`
+	Body                   httpBody `json:"body,omitempty"`
+	WithFlashbotsSignature bool     `json:"withFlashbotsSignature,omitempty"`
+}
+
+type cassetteResponse struct {
+	// Status is not known for the doHTTPRequest precompile
+	Status uint64   `json:"status,omitempty"`
+	Body   httpBody `json:"body"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// httpBody is a body in a cassette. It is written as a string if it is text
+// or in hex with the 0x prefix otherwise.
+type httpBody []byte
+
+func (b httpBody) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) && !bytes.HasPrefix(b, []byte("0x")) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(hexutil.Encode(b))
+}
+
+func (b *httpBody) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	if strings.HasPrefix(str, "0x") {
+		if decoded, err := hexutil.Decode(str); err == nil {
+			*b = decoded
+			return nil
+		}
+	}
+	*b = []byte(str)
+	return nil
+}
+
+func newCassetteRequest(request suavelib.HttpRequest) *cassetteRequest {
+	headers := []string{}
+	for _, header := range request.Headers {
+		name, _, found := strings.Cut(header, ":")
+		if found && slices.Contains(redactedHeaders, strings.ToLower(strings.TrimSpace(name))) {
+			header = name + ": <redacted>"
+		}
+		headers = append(headers, header)
+	}
+	return &cassetteRequest{
+		Url:                    request.Url,
+		Method:                 request.Method,
+		Headers:                headers,
+		Body:                   request.Body,
+		WithFlashbotsSignature: request.WithFlashbotsSignature,
+	}
+}
+
+func loadCassette(path string) (*cassette, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &cassette{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	c := &cassette{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to decode the cassette %s: %v", path, err)
+	}
+	return c, nil
+}
+
+func (c *cassette) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// mismatches returns the fields of the request that do not match the interaction.
+func (i *interaction) mismatches(precompile string, request *cassetteRequest, match []string) []string {
+	fields := []string{}
+	if i.Precompile != precompile {
+		fields = append(fields, "precompile")
+	}
+	for _, field := range match {
+		var equal bool
+		switch field {
+		case "url":
+			equal = i.Request.Url == request.Url
+		case "method":
+			equal = strings.EqualFold(i.Request.Method, request.Method)
+		case "body":
+			equal = bytes.Equal(i.Request.Body, request.Body)
+		}
+		if !equal {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// find returns the n-th interaction that matches the request, or the last one if
+// there are fewer matches. Otherwise, the error shows the diff with the closest
+// interaction of the cassette.
+func (c *cassette) find(path string, precompile string, request *cassetteRequest, match []string, n int) (*interaction, error) {
+	var closest *interaction
+	var closestIndx, closestMismatches int
+
+	matches := []*interaction{}
+	for indx, i := range c.Interactions {
+		mismatches := i.mismatches(precompile, request, match)
+		if len(mismatches) == 0 {
+			matches = append(matches, i)
+			continue
+		}
+		if closest == nil || len(mismatches) < closestMismatches {
+			closest, closestIndx, closestMismatches = i, indx, len(mismatches)
+		}
+	}
+	if len(matches) != 0 {
+		return matches[min(n, len(matches)-1)], nil
+	}
+
+	msg := fmt.Sprintf("no entry in the cassette %s matches the request (matching on %s)", path, strings.Join(match, ", "))
+	if closest == nil {
+		return nil, fmt.Errorf("%s, the cassette is empty:\n%s", msg, strings.Join(describeRequest(precompile, request, match), "\n"))
+	}
+	diff := lineDiff(describeRequest(closest.Precompile, closest.Request, match), describeRequest(precompile, request, match))
+	return nil, fmt.Errorf("%s, diff with the closest entry (#%d):\n--- cassette\n+++ request\n%s", msg, closestIndx, strings.Join(diff, "\n"))
+}
+
+// recordInteraction adds the interaction to the cassette file as the n-th interaction that
+// matches the request. It replaces the n-th match if the cassette has it (i.e. when the
+// cassette is recorded again) and appends the interaction otherwise, so that the
+// identical requests of a test are recorded in order.
+func recordInteraction(path string, i *interaction, match []string, n int) error {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// the cassette is loaded again since other tests might be recording in parallel
+	c, err := loadCassette(path)
+	if err != nil {
+		return err
+	}
+	found := false
+	for indx, other := range c.Interactions {
+		if len(other.mismatches(i.Precompile, i.Request, match)) != 0 {
+			continue
+		}
+		if n == 0 {
+			c.Interactions[indx], found = i, true
+			break
+		}
+		n--
+	}
+	if !found {
+		c.Interactions = append(c.Interactions, i)
+	}
+	return c.save(path)
+}
+
+// lockFile creates a lock file next to the path and returns the function that removes it.
+func lockFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	lockPath := path + ".lock"
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if time.Since(start) > 10*time.Second {
+			return nil, fmt.Errorf("timeout waiting for the lock %s, remove it if no test is running", lockPath)
+		}
+	}
+}
+
+// describeRequest returns the lines that describe the matched fields of the request.
+// JSON bodies are indented so that the diff shows the changed values.
+func describeRequest(precompile string, request *cassetteRequest, match []string) []string {
+	lines := []string{"precompile: " + precompile}
+	for _, field := range match {
+		switch field {
+		case "url":
+			lines = append(lines, "url: "+request.Url)
+		case "method":
+			lines = append(lines, "method: "+request.Method)
+		case "body":
+			lines = append(lines, "body:")
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, request.Body, "  ", "  "); err == nil {
+				lines = append(lines, "  "+indented.String())
+			} else if utf8.Valid(request.Body) {
+				lines = append(lines, "  "+string(request.Body))
+			} else {
+				lines = append(lines, "  "+hexutil.Encode(request.Body))
+			}
+		}
+	}
+	return strings.Split(strings.Join(lines, "\n"), "\n")
+}
+
+// lineDiff returns the lines of a and b prefixed with ' ' if they are in both,
+// '-' if they are only in a and '+' if they are only in b.
+func lineDiff(a, b []string) []string {
+	lines := []string{}
+	for _, op := range diff.Lines(a, b) {
+		lines = append(lines, string(op.Kind)+op.Line)
+	}
+	return lines
+}
+
+func runDoHTTPRequest(b *backend, input []byte) ([]byte, error) {
+	request, err := suavelib.UnpackDoHTTPRequestInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	return b.httpRequest(suavelib.DoHTTPRequestAddr, request,
+		func(response *cassetteResponse) ([]byte, error) {
+			return suavelib.PackDoHTTPRequestOutputs(response.Body)
+		},
+		func(output []byte) (*cassetteResponse, error) {
+			body, err := suavelib.UnpackDoHTTPRequestOutputs(output)
+			if err != nil {
+				return nil, err
+			}
+			return &cassetteResponse{Body: body}, nil
+		},
+	)
+}
+
+func runDoHTTPRequest2(b *backend, input []byte) ([]byte, error) {
+	request, err := suavelib.UnpackDoHTTPRequest2Inputs(input)
+	if err != nil {
+		return nil, err
+	}
+	return b.httpRequest(suavelib.DoHTTPRequest2Addr, request,
+		func(response *cassetteResponse) ([]byte, error) {
+			return suavelib.PackDoHTTPRequest2Outputs(suavelib.HttpResponse{
+				Status: response.Status,
+				Body:   response.Body,
+				Error:  []byte(response.Error),
+			})
+		},
+		func(output []byte) (*cassetteResponse, error) {
+			response, err := suavelib.UnpackDoHTTPRequest2Outputs(output)
+			if err != nil {
+				return nil, err
+			}
+			return &cassetteResponse{Status: response.Status, Body: response.Body, Error: string(response.Error)}, nil
+		},
+	)
+}
+
+// httpRequest serves an http precompile according to the http mode. The pack and unpack
+// functions convert the response of the cassette to the output of the precompile.
+func (b *backend) httpRequest(addr common.Address, request suavelib.HttpRequest, pack func(*cassetteResponse) ([]byte, error), unpack func([]byte) (*cassetteResponse, error)) ([]byte, error) {
+	if b.config.HTTPMode == httpModeLive {
+		return b.delegate(addr)
+	}
+
+	path := b.resolvePath(b.config.Cassette)
+	i := &interaction{
+		Precompile: precompileName(addr),
+		Request:    newCassetteRequest(request),
+	}
+
+	n, err := b.nextRequest(i, b.config.HTTPMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.config.HTTPMode == httpModeReplay {
+		c, err := loadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		found, err := c.find(b.config.Cassette, i.Precompile, i.Request, b.config.HTTPMatch, n)
+		if err != nil {
+			return nil, err
+		}
+		if found.Response == nil {
+			return nil, revertError(found.Revert)
+		}
+		return pack(found.Response)
+	}
+
+	output, err := b.delegate(addr)
+	if err != nil {
+		var revert revertError
+		if !errors.As(err, &revert) {
+			return nil, err
+		}
+		i.Revert = string(revert)
+	} else if i.Response, err = unpack(output); err != nil {
+		return nil, err
+	}
+	if err := recordInteraction(path, i, b.config.HTTPMatch, n); err != nil {
+		return nil, fmt.Errorf("failed to record the request in %s: %v", path, err)
+	}
+	if i.Response == nil {
+		return nil, revertError(i.Revert)
+	}
+	return output, nil
+}
+
+// nextRequest returns the number of previous requests of the session that match the same
+// interactions of the cassette. The n-th identical request of a test is served by the
+// n-th matching interaction. It is always 0 if the Connector does not use sessions.
+func (b *backend) nextRequest(i *interaction, match []string) (int, error) {
+	if b.session == nil {
+		return 0, nil
+	}
+	key := crypto.Keccak256Hash([]byte(strings.Join(describeRequest(i.Precompile, i.Request, match), "\n"))).Hex()
+	if b.session.HTTPCounters == nil {
+		b.session.HTTPCounters = map[string]int{}
+	}
+	n := b.session.HTTPCounters[key]
+	b.session.HTTPCounters[key]++
+	if err := b.session.save(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// clock is the controllable clock of a session that serves getInsecureTime.
+// All the times are Unix times in milliseconds.
+type clock struct {
+	// Time is the time of the clock when it was last updated
+	Time uint64 `json:"time"`
+	// UpdatedAt is the wall time when the clock was last updated
+	UpdatedAt uint64 `json:"updatedAt"`
+	// Frozen is true if the clock does not advance with the wall time
+	Frozen bool `json:"frozen"`
+}
+
+// now returns the time of the clock at the wall time.
+func (c *clock) now(wall uint64) uint64 {
+	if c.Frozen || wall < c.UpdatedAt {
+		return c.Time
+	}
+	return c.Time + (wall - c.UpdatedAt)
+}
+
+// insecureTime returns the time of the session clock, or the wall time
+// if there is no session or the clock was never updated.
+func (b *backend) insecureTime() uint64 {
+	wall := uint64(time.Now().UnixMilli())
+	if b.session == nil || b.session.Clock == nil {
+		return wall
+	}
+	return b.session.Clock.now(wall)
+}
+
+// updateClock applies a clock operation to the session and returns the new time of the clock.
+func (s *session) updateClock(op string, arg uint64, wall uint64) (uint64, error) {
+	c := s.Clock
+	if c == nil {
+		c = &clock{Time: wall, UpdatedAt: wall}
+	}
+	now := c.now(wall)
+
+	switch op {
+	case "set":
+		c = &clock{Time: arg, UpdatedAt: wall, Frozen: c.Frozen}
+	case "advance":
+		c = &clock{Time: now + arg, UpdatedAt: wall, Frozen: c.Frozen}
+	case "freeze":
+		c = &clock{Time: now, UpdatedAt: wall, Frozen: true}
+	case "unfreeze":
+		c = &clock{Time: now, UpdatedAt: wall}
+	case "reset":
+		s.Clock = nil
+		return wall, nil
+	default:
+		return 0, fmt.Errorf("unknown clock operation '%s', expected set, advance, freeze, unfreeze or reset", op)
+	}
+	s.Clock = c
+	return c.now(wall), nil
+}
+
+// runClock runs the 'clock' command. It updates the clock of a session and
+// returns the new time of the clock abi encoded as an uint256.
+//
+//	forge-backend clock [--config foundry.toml] --session id <set|advance|freeze|unfreeze|reset> [time]
+func runClock(args []string) ([]byte, error) {
+	var configPath, sessionID string
+
+	fs := flag.NewFlagSet("clock", flag.ContinueOnError)
+	fs.StringVar(&configPath, "config", "", "path to the foundry.toml file")
+	fs.StringVar(&sessionID, "session", "", "id of the session of the test")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() == 0 {
+		return nil, fmt.Errorf("expected a clock operation")
+	}
+
+	op := fs.Arg(0)
+	var arg uint64
+	switch op {
+	case "set", "advance":
+		if fs.NArg() != 2 {
+			return nil, fmt.Errorf("clock operation '%s' expects a time in milliseconds", op)
+		}
+		var err error
+		if arg, err = strconv.ParseUint(fs.Arg(1), 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid time '%s': %v", fs.Arg(1), err)
+		}
+	default:
+		if fs.NArg() != 1 {
+			return nil, fmt.Errorf("clock operation '%s' does not expect arguments", op)
+		}
+	}
+
+	s, err := loadSession(stateDir(configPath), sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := s.updateClock(op, arg, uint64(time.Now().UnixMilli()))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	return clockOutputs.Pack(new(big.Int).SetUint64(now))
+}
+
+var clockOutputs = abi.Arguments{
+	{Name: "time", Type: mustType("uint256")},
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// randomSeedEnv is the environment variable that sets the seed of the randomBytes
+// precompile. It takes precedence over the 'random_seed' key in foundry.toml.
+const randomSeedEnv = "SUAVE_RANDOM_SEED"
+
+// Environment variables that take precedence over the http keys in foundry.toml.
+const (
+	httpModeEnv  = "SUAVE_HTTP_MODE"
+	cassetteEnv  = "SUAVE_CASSETTE"
+	httpMatchEnv = "SUAVE_HTTP_MATCH"
+)
+
+// stateEnv is the environment variable that takes precedence over the 'state'
+// key in foundry.toml.
+const stateEnv = "SUAVE_STATE"
+
+// Modes of the http precompiles (doHTTPRequest and doHTTPRequest2).
+const (
+	// httpModeLive sends the requests with suave-geth
+	httpModeLive = ""
+	// httpModeRecord sends the requests with suave-geth and records them in the cassette
+	httpModeRecord = "record"
+	// httpModeReplay serves the requests from the cassette
+	httpModeReplay = "replay"
+)
+
+// httpMatchFields are the fields of a request that can be matched with the cassette.
+var httpMatchFields = []string{"url", "method", "body"}
+
+// config is the configuration of the backend in the [profile.suave] section of foundry.toml.
+type config struct {
+	// RandomSeed is the seed of the randomBytes precompile (in hex or decimal).
+	// A random seed is used for each test if it is not set.
+	RandomSeed string `toml:"random_seed"`
+
+	// HTTPMode is the mode of the http precompiles: live (empty), record or replay
+	HTTPMode string `toml:"http_mode"`
+	// Cassette is the file with the recorded http requests
+	Cassette string `toml:"cassette"`
+	// HTTPMatch are the fields used to match a request with the cassette,
+	// all of them (url, method and body) if it is empty
+	HTTPMatch []string `toml:"http_match"`
+
+	// State is the genesis or state dump in which newBuilder, simulateTransaction
+	// and simulateBundle run the transactions. They are delegated to suave-geth
+	// if it is not set.
+	State string `toml:"state"`
+}
+
+// loadConfig reads the configuration from foundry.toml and the environment.
+// The file is optional.
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+
+	if path != "" {
+		var foundryToml struct {
+			Profile struct {
+				Suave config `toml:"suave"`
+			} `toml:"profile"`
+		}
+		if _, err := toml.DecodeFile(path, &foundryToml); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		cfg = &foundryToml.Profile.Suave
+	}
+
+	if seed, ok := os.LookupEnv(randomSeedEnv); ok {
+		cfg.RandomSeed = seed
+	}
+	if mode, ok := os.LookupEnv(httpModeEnv); ok {
+		cfg.HTTPMode = mode
+	}
+	if cassette, ok := os.LookupEnv(cassetteEnv); ok {
+		cfg.Cassette = cassette
+	}
+	if match, ok := os.LookupEnv(httpMatchEnv); ok {
+		cfg.HTTPMatch = strings.Split(match, ",")
+	}
+	if state, ok := os.LookupEnv(stateEnv); ok {
+		cfg.State = state
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *config) validate() error {
+	switch c.HTTPMode {
+	case httpModeLive:
+	case httpModeRecord, httpModeReplay:
+		if c.Cassette == "" {
+			return fmt.Errorf("http mode '%s' requires a cassette file", c.HTTPMode)
+		}
+	default:
+		return fmt.Errorf("unknown http mode '%s', expected record, replay or empty", c.HTTPMode)
+	}
+
+	if len(c.HTTPMatch) == 0 {
+		c.HTTPMatch = slices.Clone(httpMatchFields)
+	}
+	for i, field := range c.HTTPMatch {
+		c.HTTPMatch[i] = strings.TrimSpace(field)
+		if !slices.Contains(httpMatchFields, c.HTTPMatch[i]) {
+			return fmt.Errorf("unknown http match field '%s', expected any of %s", field, strings.Join(httpMatchFields, ", "))
+		}
+	}
+	return nil
+}
+
+// parseSeed parses a seed in hex (with the 0x prefix) or in decimal.
+func parseSeed(str string) (common.Hash, error) {
+	seed, ok := new(big.Int), false
+	if hex, isHex := strings.CutPrefix(str, "0x"); isHex {
+		seed, ok = seed.SetString(hex, 16)
+	} else {
+		seed, ok = seed.SetString(str, 10)
+	}
+	if !ok || seed.Sign() < 0 || seed.BitLen() > 256 {
+		return common.Hash{}, fmt.Errorf("invalid seed '%s', expected a 256 bits number in hex or decimal", str)
+	}
+	return common.BigToHash(seed), nil
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+)
+
+// defaultCallGas is the gas of the eth_call requests without gas.
+const defaultCallGas = 50_000_000
+
+// ethRPCFixture is the chain served by the mock Ethereum JSON-RPC server.
+type ethRPCFixture struct {
+	ChainID     hexutil.Uint64                   `json:"chainId"`
+	BlockNumber hexutil.Uint64                   `json:"blockNumber"`
+	Timestamp   hexutil.Uint64                   `json:"timestamp"`
+	Accounts    map[common.Address]*stateAccount `json:"accounts"`
+	// Calls are eth_call results returned without running the code
+	Calls []*fixtureCall `json:"calls"`
+}
+
+// fixtureCall is the result of the eth_call requests to an address with the data.
+type fixtureCall struct {
+	To   common.Address `json:"to"`
+	Data hexutil.Bytes  `json:"data"`
+	// Result is the output of the call
+	Result hexutil.Bytes `json:"result,omitempty"`
+	// Revert is the revert data if the call reverts
+	Revert *hexutil.Bytes `json:"revert,omitempty"`
+}
+
+func loadEthRPCFixture(path string) (*ethRPCFixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &ethRPCFixture{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(f); err != nil {
+		return nil, fmt.Errorf("failed to decode the fixture %s: %v", path, err)
+	}
+	if f.ChainID == 0 {
+		f.ChainID = 1
+	}
+	return f, nil
+}
+
+// ethRPCServer is a mock Ethereum node that serves the state of a fixture.
+type ethRPCServer struct {
+	fixture *ethRPCFixture
+	env     *blockEnv
+	// state is not modified, each eth_call runs on a copy
+	state *memState
+}
+
+func newEthRPCServer(fixture *ethRPCFixture) *ethRPCServer {
+	state := newMemState()
+	for addr, account := range fixture.Accounts {
+		state.setAccount(addr, account)
+	}
+	return &ethRPCServer{
+		fixture: fixture,
+		env: &blockEnv{
+			ChainID:  uint64(fixture.ChainID),
+			Number:   uint64(fixture.BlockNumber),
+			Time:     uint64(fixture.Timestamp),
+			GasLimit: defaultCallGas,
+		},
+		state: state,
+	}
+}
+
+// blockTag is a block number in hex or one of the block tags.
+type blockTag string
+
+func (t *blockTag) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("block must be a string")
+	}
+	switch str {
+	case "latest", "pending", "earliest", "safe", "finalized":
+	default:
+		if _, err := hexutil.DecodeUint64(str); err != nil {
+			return fmt.Errorf("invalid block '%s', expected a tag or a hex number: %v", str, err)
+		}
+	}
+	*t = blockTag(str)
+	return nil
+}
+
+// callArgs are the transaction fields of eth_call.
+type callArgs struct {
+	From                 *common.Address `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  *hexutil.Uint64 `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                *hexutil.Uint64 `json:"nonce"`
+	Data                 *hexutil.Bytes  `json:"data"`
+	Input                *hexutil.Bytes  `json:"input"`
+	AccessList           json.RawMessage `json:"accessList"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+	Type                 *hexutil.Uint64 `json:"type"`
+}
+
+func (args *callArgs) data() ([]byte, error) {
+	if args.Data != nil && args.Input != nil && !bytes.Equal(*args.Data, *args.Input) {
+		return nil, invalidParams("both \"data\" and \"input\" are set and not equal")
+	}
+	if args.Input != nil {
+		return *args.Input, nil
+	}
+	if args.Data != nil {
+		return *args.Data, nil
+	}
+	return nil, nil
+}
+
+// overrideAccount is an entry of the state override set of eth_call.
+type overrideAccount struct {
+	Nonce     *hexutil.Uint64             `json:"nonce"`
+	Code      *hexutil.Bytes              `json:"code"`
+	Balance   *hexutil.Big                `json:"balance"`
+	State     map[common.Hash]common.Hash `json:"state"`
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+func (s *ethRPCServer) handle(_ *http.Request, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "eth_chainId":
+		if err := parsePositionalParams(params, 0); err != nil {
+			return nil, err
+		}
+		return hexutil.Uint64(s.env.ChainID), nil
+
+	case "net_version":
+		if err := parsePositionalParams(params, 0); err != nil {
+			return nil, err
+		}
+		return fmt.Sprint(s.env.ChainID), nil
+
+	case "eth_blockNumber":
+		if err := parsePositionalParams(params, 0); err != nil {
+			return nil, err
+		}
+		return hexutil.Uint64(s.env.Number), nil
+
+	case "eth_getBalance", "eth_getTransactionCount", "eth_getCode":
+		var addr common.Address
+		var block blockTag
+		if err := parsePositionalParams(params, 2, &addr, &block); err != nil {
+			return nil, err
+		}
+
+		switch method {
+		case "eth_getBalance":
+			return (*hexutil.Big)(s.state.GetBalance(addr).ToBig()), nil
+		case "eth_getTransactionCount":
+			return hexutil.Uint64(s.state.GetNonce(addr)), nil
+		default:
+			return hexutil.Bytes(s.state.GetCode(addr)), nil
+		}
+
+	case "eth_getStorageAt":
+		var addr common.Address
+		var key hexutil.Big
+		var block blockTag
+		if err := parsePositionalParams(params, 3, &addr, &key, &block); err != nil {
+			return nil, err
+		}
+
+		return s.state.GetState(addr, common.BigToHash((*big.Int)(&key))), nil
+
+	case "eth_call":
+		var args callArgs
+		var block blockTag
+		var overrides map[common.Address]*overrideAccount
+		if err := parsePositionalParams(params, 1, &args, &block, &overrides); err != nil {
+			return nil, err
+		}
+		return s.call(&args, overrides)
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("the method %s does not exist/is not available", method)}
+}
+
+// call serves eth_call. The calls in the fixture are returned as they are unless
+// the overrides change the account of the call, otherwise the code runs on a copy
+// of the state with the overrides.
+func (s *ethRPCServer) call(args *callArgs, overrides map[common.Address]*overrideAccount) (hexutil.Bytes, error) {
+	if args.To == nil {
+		return nil, invalidParams("contract creation is not supported, \"to\" is required")
+	}
+	data, err := args.data()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, call := range s.fixture.Calls {
+		// the recorded result does not apply if the account of the call is overridden
+		if overrides[*args.To] != nil {
+			break
+		}
+		if call.To == *args.To && bytes.Equal(call.Data, data) {
+			if call.Revert != nil {
+				return nil, revertRPCError(*call.Revert)
+			}
+			return call.Result, nil
+		}
+	}
+
+	state := s.state.copy()
+
+	for addr, override := range overrides {
+		if override.State != nil && override.StateDiff != nil {
+			return nil, invalidParams("account %s has both \"state\" and \"stateDiff\"", addr.Hex())
+		}
+		if override.Nonce != nil {
+			state.SetNonce(addr, uint64(*override.Nonce))
+		}
+		if override.Code != nil {
+			state.SetCode(addr, *override.Code)
+		}
+		if override.Balance != nil {
+			balance, overflow := uint256.FromBig((*big.Int)(override.Balance))
+			if overflow {
+				return nil, invalidParams("balance of account %s overflows", addr.Hex())
+			}
+			state.getOrNewAccount(addr).balance = balance
+		}
+		if override.State != nil || override.StateDiff != nil {
+			account := state.getOrNewAccount(addr)
+			if override.State != nil {
+				account.storage = maps.Clone(override.State)
+			}
+			for key, value := range override.StateDiff {
+				account.storage[key] = value
+			}
+			account.committed = maps.Clone(account.storage)
+		}
+	}
+
+	var from common.Address
+	if args.From != nil {
+		from = *args.From
+	}
+	gas := uint64(defaultCallGas)
+	if args.Gas != nil {
+		gas = uint64(*args.Gas)
+	}
+	value := new(uint256.Int)
+	if args.Value != nil {
+		var overflow bool
+		if value, overflow = uint256.FromBig((*big.Int)(args.Value)); overflow {
+			return nil, invalidParams("value overflows")
+		}
+		// like go-ethereum, the sender gets the value of the call
+		state.AddBalance(from, value)
+	}
+
+	output, _, err := state.call(s.env, from, *args.To, data, value, gas)
+	if errors.Is(err, vm.ErrExecutionReverted) {
+		return nil, revertRPCError(output)
+	} else if err != nil {
+		return nil, &rpcError{Code: rpcServerError, Message: "execution failed: " + err.Error()}
+	}
+	return output, nil
+}
+
+// revertRPCError is the error of go-ethereum for the reverted calls.
+func revertRPCError(data []byte) *rpcError {
+	msg := "execution reverted"
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		msg += ": " + reason
+	}
+	return &rpcError{Code: 3, Message: msg, Data: hexutil.Bytes(data)}
+}
+
+// runEthRPC runs the 'ethrpc' command. It serves the fixture with a mock Ethereum
+// JSON-RPC server until the process is stopped.
+//
+//	forge-backend ethrpc --fixture ethrpc.json [--listen 127.0.0.1:8545]
+func runEthRPC(args []string) error {
+	var fixturePath, listenAddr string
+
+	fs := flag.NewFlagSet("ethrpc", flag.ContinueOnError)
+	fs.StringVar(&fixturePath, "fixture", "", "path to the fixture with the accounts and the calls")
+	fs.StringVar(&listenAddr, "listen", "127.0.0.1:8545", "address of the server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fixturePath == "" {
+		return fmt.Errorf("the --fixture flag is required")
+	}
+
+	fixture, err := loadEthRPCFixture(fixturePath)
+	if err != nil {
+		return err
+	}
+	server := newEthRPCServer(fixture)
+
+	log.Printf("Serving the fixture %s on http://%s", fixturePath, strings.TrimPrefix(listenAddr, "http://"))
+	return http.ListenAndServe(listenAddr, jsonRPCServer(server.handle))
+}
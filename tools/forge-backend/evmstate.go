@@ -0,0 +1,506 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// memState is an in-memory vm.StateDB to run the EVM without a database.
+// Snapshots copy the whole state, which is fine for the small states of the tests.
+type memState struct {
+	accounts  map[common.Address]*memAccount
+	transient map[common.Address]map[common.Hash]common.Hash
+	refund    uint64
+	logs      []*types.Log
+
+	accessAddrs map[common.Address]bool
+	accessSlots map[common.Address]map[common.Hash]bool
+
+	snapshots []*memState
+}
+
+type memAccount struct {
+	balance *uint256.Int
+	nonce   uint64
+	code    []byte
+	storage map[common.Hash]common.Hash
+	// committed is the storage before the current transaction
+	committed map[common.Hash]common.Hash
+
+	selfDestructed bool
+}
+
+var _ vm.StateDB = (*memState)(nil)
+
+func newMemState() *memState {
+	return &memState{
+		accounts:    map[common.Address]*memAccount{},
+		transient:   map[common.Address]map[common.Hash]common.Hash{},
+		accessAddrs: map[common.Address]bool{},
+		accessSlots: map[common.Address]map[common.Hash]bool{},
+	}
+}
+
+func (a *memAccount) copy() *memAccount {
+	return &memAccount{
+		balance:        new(uint256.Int).Set(a.balance),
+		nonce:          a.nonce,
+		code:           a.code,
+		storage:        maps.Clone(a.storage),
+		committed:      a.committed,
+		selfDestructed: a.selfDestructed,
+	}
+}
+
+// copy returns a deep copy of the state without the snapshots.
+func (s *memState) copy() *memState {
+	cpy := &memState{
+		accounts:    make(map[common.Address]*memAccount, len(s.accounts)),
+		transient:   make(map[common.Address]map[common.Hash]common.Hash, len(s.transient)),
+		refund:      s.refund,
+		logs:        append([]*types.Log{}, s.logs...),
+		accessAddrs: maps.Clone(s.accessAddrs),
+		accessSlots: make(map[common.Address]map[common.Hash]bool, len(s.accessSlots)),
+	}
+	for addr, account := range s.accounts {
+		cpy.accounts[addr] = account.copy()
+	}
+	for addr, slots := range s.transient {
+		cpy.transient[addr] = maps.Clone(slots)
+	}
+	for addr, slots := range s.accessSlots {
+		cpy.accessSlots[addr] = maps.Clone(slots)
+	}
+	return cpy
+}
+
+// commit finalizes a transaction: the storage becomes the committed storage and
+// the self destructed accounts are removed.
+func (s *memState) commit() {
+	for addr, account := range s.accounts {
+		if account.selfDestructed {
+			delete(s.accounts, addr)
+			continue
+		}
+		account.committed = maps.Clone(account.storage)
+	}
+	s.transient = map[common.Address]map[common.Hash]common.Hash{}
+	s.refund = 0
+	s.snapshots = nil
+}
+
+func (s *memState) getOrNewAccount(addr common.Address) *memAccount {
+	account, ok := s.accounts[addr]
+	if !ok {
+		account = &memAccount{
+			balance:   new(uint256.Int),
+			storage:   map[common.Hash]common.Hash{},
+			committed: map[common.Hash]common.Hash{},
+		}
+		s.accounts[addr] = account
+	}
+	return account
+}
+
+func (s *memState) CreateAccount(addr common.Address) {
+	// the balance is kept as in go-ethereum
+	balance := s.GetBalance(addr)
+	delete(s.accounts, addr)
+	s.getOrNewAccount(addr).balance = balance
+}
+
+func (s *memState) SubBalance(addr common.Address, amount *uint256.Int) {
+	account := s.getOrNewAccount(addr)
+	account.balance = new(uint256.Int).Sub(account.balance, amount)
+}
+
+func (s *memState) AddBalance(addr common.Address, amount *uint256.Int) {
+	account := s.getOrNewAccount(addr)
+	account.balance = new(uint256.Int).Add(account.balance, amount)
+}
+
+func (s *memState) GetBalance(addr common.Address) *uint256.Int {
+	if account, ok := s.accounts[addr]; ok {
+		return new(uint256.Int).Set(account.balance)
+	}
+	return new(uint256.Int)
+}
+
+func (s *memState) GetNonce(addr common.Address) uint64 {
+	if account, ok := s.accounts[addr]; ok {
+		return account.nonce
+	}
+	return 0
+}
+
+func (s *memState) SetNonce(addr common.Address, nonce uint64) {
+	s.getOrNewAccount(addr).nonce = nonce
+}
+
+func (s *memState) GetCodeHash(addr common.Address) common.Hash {
+	account, ok := s.accounts[addr]
+	if !ok {
+		return common.Hash{}
+	}
+	if len(account.code) == 0 {
+		return types.EmptyCodeHash
+	}
+	return crypto.Keccak256Hash(account.code)
+}
+
+func (s *memState) GetCode(addr common.Address) []byte {
+	if account, ok := s.accounts[addr]; ok {
+		return account.code
+	}
+	return nil
+}
+
+func (s *memState) SetCode(addr common.Address, code []byte) {
+	s.getOrNewAccount(addr).code = code
+}
+
+func (s *memState) GetCodeSize(addr common.Address) int {
+	return len(s.GetCode(addr))
+}
+
+func (s *memState) AddRefund(gas uint64) {
+	s.refund += gas
+}
+
+func (s *memState) SubRefund(gas uint64) {
+	if gas > s.refund {
+		panic("refund counter below zero")
+	}
+	s.refund -= gas
+}
+
+func (s *memState) GetRefund() uint64 {
+	return s.refund
+}
+
+func (s *memState) GetCommittedState(addr common.Address, key common.Hash) common.Hash {
+	if account, ok := s.accounts[addr]; ok {
+		return account.committed[key]
+	}
+	return common.Hash{}
+}
+
+func (s *memState) GetState(addr common.Address, key common.Hash) common.Hash {
+	if account, ok := s.accounts[addr]; ok {
+		return account.storage[key]
+	}
+	return common.Hash{}
+}
+
+func (s *memState) SetState(addr common.Address, key, value common.Hash) {
+	s.getOrNewAccount(addr).storage[key] = value
+}
+
+func (s *memState) GetTransientState(addr common.Address, key common.Hash) common.Hash {
+	return s.transient[addr][key]
+}
+
+func (s *memState) SetTransientState(addr common.Address, key, value common.Hash) {
+	if _, ok := s.transient[addr]; !ok {
+		s.transient[addr] = map[common.Hash]common.Hash{}
+	}
+	s.transient[addr][key] = value
+}
+
+func (s *memState) SelfDestruct(addr common.Address) {
+	if account, ok := s.accounts[addr]; ok {
+		account.selfDestructed = true
+		account.balance = new(uint256.Int)
+	}
+}
+
+func (s *memState) HasSelfDestructed(addr common.Address) bool {
+	if account, ok := s.accounts[addr]; ok {
+		return account.selfDestructed
+	}
+	return false
+}
+
+func (s *memState) Selfdestruct6780(addr common.Address) {
+	// the memState does not track the accounts created in the transaction
+	// so the account is only cleared as in EIP-6780
+	if account, ok := s.accounts[addr]; ok {
+		account.balance = new(uint256.Int)
+	}
+}
+
+func (s *memState) Exist(addr common.Address) bool {
+	_, ok := s.accounts[addr]
+	return ok
+}
+
+func (s *memState) Empty(addr common.Address) bool {
+	account, ok := s.accounts[addr]
+	return !ok || (account.nonce == 0 && account.balance.IsZero() && len(account.code) == 0)
+}
+
+func (s *memState) AddressInAccessList(addr common.Address) bool {
+	return s.accessAddrs[addr]
+}
+
+func (s *memState) SlotInAccessList(addr common.Address, slot common.Hash) (bool, bool) {
+	return s.accessAddrs[addr], s.accessSlots[addr][slot]
+}
+
+func (s *memState) AddAddressToAccessList(addr common.Address) {
+	s.accessAddrs[addr] = true
+}
+
+func (s *memState) AddSlotToAccessList(addr common.Address, slot common.Hash) {
+	s.accessAddrs[addr] = true
+	if _, ok := s.accessSlots[addr]; !ok {
+		s.accessSlots[addr] = map[common.Hash]bool{}
+	}
+	s.accessSlots[addr][slot] = true
+}
+
+func (s *memState) Prepare(rules params.Rules, sender, coinbase common.Address, dest *common.Address, precompiles []common.Address, txAccesses types.AccessList) {
+	s.accessAddrs = map[common.Address]bool{}
+	s.accessSlots = map[common.Address]map[common.Hash]bool{}
+	if !rules.IsBerlin {
+		return
+	}
+	s.AddAddressToAccessList(sender)
+	if dest != nil {
+		s.AddAddressToAccessList(*dest)
+	}
+	for _, addr := range precompiles {
+		s.AddAddressToAccessList(addr)
+	}
+	for _, el := range txAccesses {
+		s.AddAddressToAccessList(el.Address)
+		for _, key := range el.StorageKeys {
+			s.AddSlotToAccessList(el.Address, key)
+		}
+	}
+	if rules.IsShanghai {
+		s.AddAddressToAccessList(coinbase)
+	}
+}
+
+func (s *memState) RevertToSnapshot(id int) {
+	snapshot := s.snapshots[id]
+	snapshots := s.snapshots[:id]
+	*s = *snapshot
+	s.snapshots = snapshots
+}
+
+func (s *memState) Snapshot() int {
+	s.snapshots = append(s.snapshots, s.copy())
+	return len(s.snapshots) - 1
+}
+
+func (s *memState) AddLog(log *types.Log) {
+	log.Index = uint(len(s.logs))
+	s.logs = append(s.logs, log)
+}
+
+func (s *memState) AddPreimage(common.Hash, []byte) {}
+
+// blockEnv is the block in which the EVM runs the calls.
+type blockEnv struct {
+	ChainID  uint64
+	Number   uint64
+	Time     uint64
+	GasLimit uint64
+	Coinbase common.Address
+}
+
+// newEVM returns an EVM over the state with every fork of the dev chain enabled.
+func newEVM(state *memState, env *blockEnv, origin common.Address, gasPrice *big.Int) *vm.EVM {
+	chainConfig := *params.AllDevChainProtocolChanges
+	chainConfig.ChainID = new(big.Int).SetUint64(env.ChainID)
+
+	blockCtx := vm.BlockContext{
+		CanTransfer: func(db vm.StateDB, addr common.Address, amount *uint256.Int) bool {
+			return db.GetBalance(addr).Cmp(amount) >= 0
+		},
+		Transfer: func(db vm.StateDB, sender, recipient common.Address, amount *uint256.Int) {
+			db.SubBalance(sender, amount)
+			db.AddBalance(recipient, amount)
+		},
+		GetHash: func(n uint64) common.Hash {
+			return crypto.Keccak256Hash(new(big.Int).SetUint64(n).Bytes())
+		},
+		Coinbase:    env.Coinbase,
+		GasLimit:    env.GasLimit,
+		BlockNumber: new(big.Int).SetUint64(env.Number),
+		Time:        env.Time,
+		Difficulty:  new(big.Int),
+		BaseFee:     new(big.Int),
+		BlobBaseFee: new(big.Int),
+		Random:      &common.Hash{},
+	}
+	txCtx := vm.TxContext{
+		Origin:   origin,
+		GasPrice: gasPrice,
+	}
+	return vm.NewEVM(blockCtx, txCtx, state, &chainConfig, vm.Config{NoBaseFee: true})
+}
+
+// call runs a message call on the state and returns its output and the gas used.
+// The changes to the state are kept unless the call fails.
+func (s *memState) call(env *blockEnv, from, to common.Address, data []byte, value *uint256.Int, gas uint64) ([]byte, uint64, error) {
+	evm := newEVM(s, env, from, new(big.Int))
+	rules := evm.ChainConfig().Rules(evm.Context.BlockNumber, true, env.Time)
+	s.Prepare(rules, from, env.Coinbase, &to, vm.ActivePrecompiles(rules), nil)
+
+	output, leftOverGas, err := evm.Call(vm.AccountRef(from), to, data, gas, value)
+	s.commit()
+	return output, gas - leftOverGas, err
+}
+
+// txResult is the result of a transaction applied to the state.
+type txResult struct {
+	GasUsed uint64
+	// EffectiveGasPrice is the price paid per gas, there is no base fee
+	EffectiveGasPrice *big.Int
+	Logs              []*types.Log
+	ReturnData        []byte
+	// ContractAddress is the address of the contract created by the transaction
+	ContractAddress *common.Address
+	// Err is the error of the execution (i.e. a revert), the transaction is still valid
+	Err error
+}
+
+// applyTransaction validates the transaction and runs it on the state like a block
+// with no base fee would. It returns an error if the transaction is not valid
+// (i.e. the nonce is used), in which case the state is not modified.
+func (s *memState) applyTransaction(env *blockEnv, tx *types.Transaction) (*txResult, error) {
+	chainID := new(big.Int).SetUint64(env.ChainID)
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender: %v", err)
+	}
+	if nonce := s.GetNonce(from); tx.Nonce() < nonce {
+		return nil, fmt.Errorf("nonce too low: address %s, tx: %d state: %d", from.Hex(), tx.Nonce(), nonce)
+	} else if tx.Nonce() > nonce {
+		return nil, fmt.Errorf("nonce too high: address %s, tx: %d state: %d", from.Hex(), tx.Nonce(), nonce)
+	}
+
+	gasPrice := tx.EffectiveGasTipValue(new(big.Int))
+	gasCost := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), gasPrice)
+	cost := new(big.Int).Add(gasCost, tx.Value())
+	if balance := s.GetBalance(from).ToBig(); balance.Cmp(cost) < 0 {
+		return nil, fmt.Errorf("insufficient funds for gas * price + value: address %s have %v want %v", from.Hex(), balance, cost)
+	}
+	gas := intrinsicGas(tx)
+	if tx.Gas() < gas {
+		return nil, fmt.Errorf("intrinsic gas too low: have %d, want %d", tx.Gas(), gas)
+	}
+	value, _ := uint256.FromBig(tx.Value())
+
+	evm := newEVM(s, env, from, gasPrice)
+	rules := evm.ChainConfig().Rules(evm.Context.BlockNumber, true, env.Time)
+	s.Prepare(rules, from, env.Coinbase, tx.To(), vm.ActivePrecompiles(rules), tx.AccessList())
+
+	gasCostU256, _ := uint256.FromBig(gasCost)
+	s.SubBalance(from, gasCostU256)
+
+	logsBefore := len(s.logs)
+	result := &txResult{EffectiveGasPrice: gasPrice}
+	var leftOverGas uint64
+	if tx.To() == nil {
+		var addr common.Address
+		result.ReturnData, addr, leftOverGas, result.Err = evm.Create(vm.AccountRef(from), tx.Data(), tx.Gas()-gas, value)
+		result.ContractAddress = &addr
+	} else {
+		s.SetNonce(from, tx.Nonce()+1)
+		result.ReturnData, leftOverGas, result.Err = evm.Call(vm.AccountRef(from), *tx.To(), tx.Data(), tx.Gas()-gas, value)
+	}
+
+	// the refund is capped as in EIP-3529
+	gasUsed := tx.Gas() - leftOverGas
+	refund := min(s.GetRefund(), gasUsed/params.RefundQuotientEIP3529)
+	leftOverGas += refund
+	result.GasUsed = tx.Gas() - leftOverGas
+
+	remaining := new(uint256.Int).Mul(uint256.NewInt(leftOverGas), uint256.MustFromBig(gasPrice))
+	s.AddBalance(from, remaining)
+	fee := new(uint256.Int).Mul(uint256.NewInt(result.GasUsed), uint256.MustFromBig(gasPrice))
+	s.AddBalance(env.Coinbase, fee)
+
+	for _, log := range s.logs[logsBefore:] {
+		log.TxHash = tx.Hash()
+		log.BlockNumber = env.Number
+		result.Logs = append(result.Logs, log)
+	}
+	s.commit()
+	return result, nil
+}
+
+// intrinsicGas is the gas of a transaction before the execution.
+func intrinsicGas(tx *types.Transaction) uint64 {
+	gas := params.TxGas
+	if tx.To() == nil {
+		gas = params.TxGasContractCreation
+		gas += uint64((len(tx.Data())+31)/32) * params.InitCodeWordGas
+	}
+	for _, b := range tx.Data() {
+		if b == 0 {
+			gas += params.TxDataZeroGas
+		} else {
+			gas += params.TxDataNonZeroGasEIP2028
+		}
+	}
+	for _, tuple := range tx.AccessList() {
+		gas += params.TxAccessListAddressGas + uint64(len(tuple.StorageKeys))*params.TxAccessListStorageKeyGas
+	}
+	return gas
+}
+
+// stateAccount is an account in a fixture or in a state dump.
+type stateAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   hexutil.Uint64              `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// setAccount sets the balance, nonce, code and storage of an account.
+func (s *memState) setAccount(addr common.Address, account *stateAccount) {
+	acc := s.getOrNewAccount(addr)
+	if account.Balance != nil {
+		acc.balance, _ = uint256.FromBig((*big.Int)(account.Balance))
+	}
+	acc.nonce = uint64(account.Nonce)
+	acc.code = account.Code
+	for key, value := range account.Storage {
+		acc.storage[key] = value
+		acc.committed[key] = value
+	}
+}
+
+// dump returns the accounts of the state. The dump is loaded back with setAccount.
+func (s *memState) dump() map[common.Address]*stateAccount {
+	accounts := map[common.Address]*stateAccount{}
+	for addr, account := range s.accounts {
+		storage := map[common.Hash]common.Hash{}
+		for key, value := range account.storage {
+			if value != (common.Hash{}) {
+				storage[key] = value
+			}
+		}
+		accounts[addr] = &stateAccount{
+			Balance: (*hexutil.Big)(account.balance.ToBig()),
+			Nonce:   hexutil.Uint64(account.nonce),
+			Code:    account.code,
+			Storage: storage,
+		}
+	}
+	return accounts
+}
@@ -0,0 +1,25 @@
+module github.com/flashbots/suave-std/tools/forge-backend
+
+go 1.21.0
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/ethereum/go-ethereum v1.13.14
+	github.com/flashbots/suave-std/tools/suavelib v0.0.0
+	github.com/holiman/uint256 v1.2.4
+)
+
+require (
+	github.com/bits-and-blooms/bitset v1.10.0 // indirect
+	github.com/consensys/bavard v0.1.13 // indirect
+	github.com/consensys/gnark-crypto v0.12.1 // indirect
+	github.com/crate-crypto/go-kzg-4844 v0.7.0 // indirect
+	github.com/mmcloughlin/addchain v0.4.0 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/exp v0.0.0-20231110203233-9a3e6036ecaa // indirect
+	golang.org/x/sync v0.5.0 // indirect
+	golang.org/x/sys v0.16.0 // indirect
+	rsc.io/tmplfunc v0.0.3 // indirect
+)
+
+replace github.com/flashbots/suave-std/tools/suavelib => ../suavelib
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+func invalidParams(format string, args ...interface{}) *rpcError {
+	return &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// rpcHandler serves a JSON-RPC method. It returns a *rpcError to respond with
+// a specific error code, any other error is returned with rpcServerError.
+type rpcHandler func(r *http.Request, method string, params json.RawMessage) (interface{}, error)
+
+// jsonRPCServer returns an http handler that validates the JSON-RPC 2.0 requests
+// (single or batch) and serves them with the handler.
+func jsonRPCServer(handler rpcHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "JSON-RPC requests must be sent with POST", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var result interface{}
+		body = bytes.TrimSpace(body)
+		if len(body) > 0 && body[0] == '[' {
+			var batch []json.RawMessage
+			if err := json.Unmarshal(body, &batch); err != nil {
+				result = parseErrorResponse(err)
+			} else if len(batch) == 0 {
+				result = errorResponse(nil, &rpcError{Code: rpcInvalidRequest, Message: "empty batch"})
+			} else {
+				responses := []*rpcResponse{}
+				for _, msg := range batch {
+					if response := serveRPCMessage(r, handler, msg); response != nil {
+						responses = append(responses, response)
+					}
+				}
+				if len(responses) > 0 {
+					result = responses
+				}
+			}
+		} else {
+			if response := serveRPCMessage(r, handler, body); response != nil {
+				result = response
+			}
+		}
+
+		// notifications do not have a response
+		if result == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+// serveRPCMessage serves a single request of a batch. It returns nil for notifications.
+func serveRPCMessage(r *http.Request, handler rpcHandler, msg json.RawMessage) *rpcResponse {
+	if !json.Valid(msg) {
+		return parseErrorResponse(fmt.Errorf("invalid JSON"))
+	}
+	var req rpcRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return errorResponse(nil, &rpcError{Code: rpcInvalidRequest, Message: "invalid request: " + err.Error()})
+	}
+	if rpcErr := req.validate(); rpcErr != nil {
+		return errorResponse(req.ID, rpcErr)
+	}
+
+	result, err := handler(r, req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: rpcServerError, Message: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr)
+	}
+	if result == nil {
+		// the result member is required on success
+		result = json.RawMessage("null")
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (req *rpcRequest) validate() *rpcError {
+	if req.JSONRPC != "2.0" {
+		return &rpcError{Code: rpcInvalidRequest, Message: fmt.Sprintf("invalid jsonrpc version '%s', expected '2.0'", req.JSONRPC)}
+	}
+	if req.ID != nil {
+		switch req.ID[0] {
+		case '"', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		case 'n':
+			// null ids are discouraged but valid
+		default:
+			return &rpcError{Code: rpcInvalidRequest, Message: "id must be a string, a number or null"}
+		}
+	}
+	if req.Method == "" {
+		return &rpcError{Code: rpcInvalidRequest, Message: "missing method"}
+	}
+	if len(req.Params) > 0 && req.Params[0] != '[' && req.Params[0] != '{' && string(req.Params) != "null" {
+		return &rpcError{Code: rpcInvalidRequest, Message: "params must be an array or an object"}
+	}
+	return nil
+}
+
+func errorResponse(id json.RawMessage, err *rpcError) *rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: err}
+}
+
+func parseErrorResponse(err error) *rpcResponse {
+	return errorResponse(nil, &rpcError{Code: rpcParseError, Message: "parse error: " + err.Error()})
+}
+
+// parsePositionalParams decodes the params array into the args. The first
+// required args must be present and the rest are optional.
+func parsePositionalParams(params json.RawMessage, required int, args ...interface{}) error {
+	var values []json.RawMessage
+	if len(params) > 0 && string(params) != "null" {
+		if err := json.Unmarshal(params, &values); err != nil {
+			return invalidParams("params must be an array")
+		}
+	}
+	if len(values) < required {
+		return invalidParams("missing value for required argument %d", len(values))
+	}
+	if len(values) > len(args) {
+		return invalidParams("too many arguments, want at most %d", len(args))
+	}
+	for indx, value := range values {
+		dec := json.NewDecoder(bytes.NewReader(value))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(args[indx]); err != nil {
+			return invalidParams("invalid argument %d: %v", indx, err)
+		}
+	}
+	return nil
+}
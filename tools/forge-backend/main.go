@@ -0,0 +1,155 @@
+// forge-backend is an offline backend for the forge Connector. It is called through
+// ffi with the same arguments as 'suave-geth forge':
+//
+//	forge-backend forge [--local] [--config foundry.toml] [--session id] <address> <calldata>
+//
+// It writes the hex encoded output of the precompile on stdout or the revert
+// reason on stderr with a non-zero exit code. The precompiles that are not
+// implemented natively are delegated to suave-geth. The builder precompiles
+// run on the state file of the configuration, if it is set.
+//
+// The state of a test (i.e. the seed of randomBytes) is kept in a session
+// created with:
+//
+//	forge-backend session [--config foundry.toml]
+//
+// The clock of getInsecureTime in a session is controlled with:
+//
+//	forge-backend clock [--config foundry.toml] --session id <set|advance|freeze|unfreeze|reset> [time]
+//
+// A mock Ethereum JSON-RPC server that serves the accounts and calls of a
+// fixture is started with:
+//
+//	forge-backend ethrpc --fixture ethrpc.json [--listen 127.0.0.1:8545]
+//
+// A mock Flashbots relay that validates eth_sendBundle and mev_sendBundle is started with:
+//
+//	forge-backend relay [--listen 127.0.0.1:8546] [--log bundles.jsonl] [--allow-unsigned]
+//
+// A mock of the OpenAI chat completions API with scripted responses is started with:
+//
+//	forge-backend openai --script openai.json [--listen 127.0.0.1:8547]
+//
+// An emulator of the suavex builder namespace of suave-geth is started with:
+//
+//	forge-backend suavex --fixture suavex.json [--listen 127.0.0.1:8548]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var output []byte
+	var err error
+
+	switch os.Args[1] {
+	case "forge":
+		output, err = run(os.Args[2:])
+	case "session":
+		output, err = runSession(os.Args[2:])
+	case "clock":
+		output, err = runClock(os.Args[2:])
+	case "ethrpc", "relay", "openai", "suavex":
+		// the servers run until they are stopped and have no output
+		if err := servers[os.Args[1]](os.Args[2:]); err != nil {
+			fmt.Fprint(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		return
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprint(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	fmt.Print(hexutil.Encode(output))
+}
+
+// servers are the commands that run a mock of an external service.
+var servers = map[string]func(args []string) error{
+	"ethrpc": runEthRPC,
+	"relay":  runRelay,
+	"openai": runOpenAI,
+	"suavex": runSuavex,
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: forge-backend forge [--local] [--config foundry.toml] [--session id] <address> <calldata>")
+	fmt.Fprintln(os.Stderr, "       forge-backend session [--config foundry.toml]")
+	fmt.Fprintln(os.Stderr, "       forge-backend clock [--config foundry.toml] --session id <set|advance|freeze|unfreeze|reset> [time]")
+	fmt.Fprintln(os.Stderr, "       forge-backend ethrpc --fixture ethrpc.json [--listen 127.0.0.1:8545]")
+	fmt.Fprintln(os.Stderr, "       forge-backend relay [--listen 127.0.0.1:8546] [--log bundles.jsonl] [--allow-unsigned]")
+	fmt.Fprintln(os.Stderr, "       forge-backend openai --script openai.json [--listen 127.0.0.1:8547]")
+	fmt.Fprintln(os.Stderr, "       forge-backend suavex --fixture suavex.json [--listen 127.0.0.1:8548]")
+	os.Exit(1)
+}
+
+func run(args []string) ([]byte, error) {
+	var local bool
+	var configPath, sessionID string
+
+	fs := flag.NewFlagSet("forge", flag.ContinueOnError)
+	fs.BoolVar(&local, "local", false, "unused, kept for compatibility with suave-geth")
+	fs.StringVar(&configPath, "config", "", "path to the foundry.toml file")
+	fs.StringVar(&sessionID, "session", "", "id of the session of the test")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 2 {
+		return nil, fmt.Errorf("expected the precompile address and the calldata but found %d arguments", fs.NArg())
+	}
+
+	addrBytes, err := hexutil.Decode(fs.Arg(0))
+	if err != nil {
+		return nil, fmt.Errorf("invalid precompile address '%s': %v", fs.Arg(0), err)
+	}
+	input, err := hexutil.Decode(fs.Arg(1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid calldata: %v", err)
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// suave-geth does not know about the sessions
+	delegateArgs := []string{"--local"}
+	if configPath != "" {
+		delegateArgs = append(delegateArgs, "--config", configPath)
+	}
+	delegateArgs = append(delegateArgs, fs.Arg(0), fs.Arg(1))
+
+	b := &backend{
+		config:       cfg,
+		configPath:   configPath,
+		delegateArgs: delegateArgs,
+	}
+	if sessionID != "" {
+		if b.session, err = loadSession(stateDir(configPath), sessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := b.call(common.BytesToAddress(addrBytes), input)
+	if err != nil {
+		return nil, err
+	}
+	if b.session != nil {
+		if err := b.session.save(); err != nil {
+			return nil, err
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,1220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/flashbots/suave-std/tools/suavelib"
+)
+
+var testSigningKey = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+
+func runPrecompile(t *testing.T, addr common.Address, input []byte) []byte {
+	t.Helper()
+
+	output, err := run([]string{"--local", "--config", "foundry.toml", addr.Hex(), hexutil.Encode(input)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+func TestRandomBytes(t *testing.T) {
+	input, _ := suavelib.PackRandomBytesInputs(32)
+	value, err := suavelib.UnpackRandomBytesOutputs(runPrecompile(t, suavelib.RandomBytesAddr, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(value) != 32 {
+		t.Fatalf("expected 32 bytes but found %d", len(value))
+	}
+}
+
+func TestRandomBytes_Session(t *testing.T) {
+	t.Setenv(stateDirEnv, t.TempDir())
+	t.Setenv(randomSeedEnv, "0x1234")
+
+	randomBytes := func(session string) []byte {
+		input, _ := suavelib.PackRandomBytesInputs(48)
+		output, err := run([]string{"--session", session, suavelib.RandomBytesAddr.Hex(), hexutil.Encode(input)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := suavelib.UnpackRandomBytesOutputs(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	newTestSession := func() string {
+		output, err := runSession(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values, err := sessionOutputs.Unpack(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seed := common.Hash(values[1].([32]byte)); seed != common.HexToHash("0x1234") {
+			t.Fatalf("unexpected seed %s", seed.Hex())
+		}
+		return common.Hash(values[0].([32]byte)).Hex()
+	}
+
+	session1 := newTestSession()
+	first, second := randomBytes(session1), randomBytes(session1)
+	if bytes.Equal(first, second) {
+		t.Fatal("expected different values for each call")
+	}
+
+	// a session with the same seed replays the same values
+	session2 := newTestSession()
+	if session1 == session2 {
+		t.Fatal("expected a new session id")
+	}
+	if !bytes.Equal(randomBytes(session2), first) || !bytes.Equal(randomBytes(session2), second) {
+		t.Fatal("expected the same values for the same seed")
+	}
+
+	if _, err := run([]string{"--session", common.Hash{0x1}.Hex(), suavelib.RandomBytesAddr.Hex(), "0x"}); err == nil {
+		t.Fatal("expected an error for an unknown session")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foundry.toml")
+	config := "[profile.default]\nsolc_version = \"0.8.23\"\n[profile.suave]\nwhitelist = [\"*\"]\nrandom_seed = \"42\"\n"
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RandomSeed != "42" {
+		t.Fatalf("unexpected seed '%s'", cfg.RandomSeed)
+	}
+	seed, err := parseSeed(cfg.RandomSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed != common.BigToHash(big.NewInt(42)) {
+		t.Fatalf("unexpected seed %s", seed.Hex())
+	}
+
+	// the environment variable takes precedence
+	t.Setenv(randomSeedEnv, "0x2a")
+	if cfg, err = loadConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.RandomSeed != "0x2a" {
+		t.Fatalf("unexpected seed '%s'", cfg.RandomSeed)
+	}
+
+	if _, err := parseSeed("seed"); err == nil {
+		t.Fatal("expected an error for an invalid seed")
+	}
+}
+
+func TestAesEncryptDecrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{0x1}, 32)
+
+	input, _ := suavelib.PackAesEncryptInputs(key, []byte("message"))
+	ciphertext, err := suavelib.UnpackAesEncryptOutputs(runPrecompile(t, suavelib.AesEncryptAddr, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input, _ = suavelib.PackAesDecryptInputs(key, ciphertext)
+	message, err := suavelib.UnpackAesDecryptOutputs(runPrecompile(t, suavelib.AesDecryptAddr, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(message) != "message" {
+		t.Fatalf("unexpected message '%s'", message)
+	}
+
+	// decrypting with another key fails
+	input, _ = suavelib.PackAesDecryptInputs(bytes.Repeat([]byte{0x2}, 32), ciphertext)
+	if _, err := run([]string{suavelib.AesDecryptAddr.Hex(), hexutil.Encode(input)}); err == nil {
+		t.Fatal("expected an error")
+	} else if !strings.HasPrefix(err.Error(), "AES_DECRYPT: ") {
+		t.Fatalf("expected the precompile name in the error: %v", err)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	digest := crypto.Keccak256([]byte("message"))
+
+	input, _ := suavelib.PackSignMessageInputs(digest, suavelib.CryptoSignatureSECP256, testSigningKey)
+	signature, err := suavelib.UnpackSignMessageOutputs(runPrecompile(t, suavelib.SignMessageAddr, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubKey, err := crypto.SigToPub(digest, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.HexToECDSA(testSigningKey)
+	if crypto.PubkeyToAddress(*pubKey) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatal("signature does not recover the signing address")
+	}
+
+	input, _ = suavelib.PackSignMessageInputs(digest, suavelib.CryptoSignatureBLS, testSigningKey)
+	if _, err := run([]string{suavelib.SignMessageAddr.Hex(), hexutil.Encode(input)}); err == nil {
+		t.Fatal("expected an error for BLS signatures")
+	}
+}
+
+func TestSignEthTransaction(t *testing.T) {
+	txn, err := types.NewTx(&types.LegacyTx{
+		Nonce:    1,
+		To:       &common.Address{0x1},
+		Value:    big.NewInt(10),
+		Gas:      21000,
+		GasPrice: big.NewInt(100),
+	}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chainId := range []string{"0x539", "1337"} {
+		input, _ := suavelib.PackSignEthTransactionInputs(txn, chainId, testSigningKey)
+		signedTxn, err := suavelib.UnpackSignEthTransactionOutputs(runPrecompile(t, suavelib.SignEthTransactionAddr, input))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(signedTxn); err != nil {
+			t.Fatal(err)
+		}
+		if tx.ChainId().Int64() != 1337 {
+			t.Fatalf("unexpected chain id %s", tx.ChainId())
+		}
+		sender, err := types.LatestSignerForChainID(tx.ChainId()).Sender(&tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, _ := crypto.HexToECDSA(testSigningKey)
+		if sender != crypto.PubkeyToAddress(key.PublicKey) {
+			t.Fatalf("unexpected sender %s", sender)
+		}
+	}
+}
+
+func TestPrivateKeyGen(t *testing.T) {
+	input, _ := suavelib.PackPrivateKeyGenInputs(suavelib.CryptoSignatureSECP256)
+	privateKey, err := suavelib.UnpackPrivateKeyGenOutputs(runPrecompile(t, suavelib.PrivateKeyGenAddr, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := crypto.HexToECDSA(privateKey); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetInsecureTime(t *testing.T) {
+	input, _ := suavelib.PackGetInsecureTimeInputs()
+	now, err := suavelib.UnpackGetInsecureTimeOutputs(runPrecompile(t, suavelib.GetInsecureTimeAddr, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := time.Now().UnixMilli() - now.Int64(); diff < 0 || diff > 10000 {
+		t.Fatalf("unexpected time %s", now)
+	}
+}
+
+func TestRun_InvalidArguments(t *testing.T) {
+	if _, err := run([]string{"0x01"}); err == nil {
+		t.Fatal("expected an error for a missing calldata")
+	}
+	if _, err := run([]string{"address", "0x"}); err == nil {
+		t.Fatal("expected an error for an invalid address")
+	}
+}
+
+func TestUpdateClock(t *testing.T) {
+	s := &session{}
+
+	cases := []struct {
+		op        string
+		arg, wall uint64
+		now       uint64
+	}{
+		{"set", 1000, 50, 1000},
+		{"advance", 500, 60, 1510},
+		{"freeze", 0, 70, 1520},
+		{"advance", 100, 80, 1620},
+		{"unfreeze", 0, 90, 1620},
+		{"set", 5000, 100, 5000},
+		{"reset", 0, 110, 110},
+	}
+	for _, c := range cases {
+		now, err := s.updateClock(c.op, c.arg, c.wall)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if now != c.now {
+			t.Fatalf("%s %d: expected %d but found %d", c.op, c.arg, c.now, now)
+		}
+	}
+	if s.Clock != nil {
+		t.Fatal("expected the clock to be reset")
+	}
+
+	if _, err := s.updateClock("rewind", 0, 0); err == nil {
+		t.Fatal("expected an error for an unknown operation")
+	}
+}
+
+func TestGetInsecureTime_Clock(t *testing.T) {
+	t.Setenv(stateDirEnv, t.TempDir())
+
+	output, err := runSession(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, _ := sessionOutputs.Unpack(output)
+	session := common.Hash(values[0].([32]byte)).Hex()
+
+	for _, args := range [][]string{{"freeze"}, {"set", "1000"}, {"advance", "500"}} {
+		if _, err := runClock(append([]string{"--session", session}, args...)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	input, _ := suavelib.PackGetInsecureTimeInputs()
+	output, err = run([]string{"--session", session, suavelib.GetInsecureTimeAddr.Hex(), hexutil.Encode(input)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now, err := suavelib.UnpackGetInsecureTimeOutputs(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if now.Uint64() != 1500 {
+		t.Fatalf("expected 1500 but found %s", now)
+	}
+
+	if _, err := runClock([]string{"--session", session, "set"}); err == nil {
+		t.Fatal("expected an error for a missing time")
+	}
+}
+
+func TestDoHTTPRequest_Record(t *testing.T) {
+	dir := t.TempDir()
+	cassette := filepath.Join(dir, "cassette.json")
+
+	// fake suave-geth that returns the same response for any request
+	output, _ := suavelib.PackDoHTTPRequestOutputs([]byte(`{"result":"0x1"}`))
+	script := "#!/bin/sh\necho " + hexutil.Encode(output) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "suave-geth"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	t.Setenv(httpModeEnv, "record")
+	t.Setenv(cassetteEnv, cassette)
+
+	request := suavelib.HttpRequest{
+		Url:     "http://localhost:8545",
+		Method:  "POST",
+		Headers: []string{"Content-Type: application/json", "Authorization: Bearer secret"},
+		Body:    []byte(`{"method":"eth_blockNumber"}`),
+	}
+	input, _ := suavelib.PackDoHTTPRequestInputs(request)
+	if found := runPrecompile(t, suavelib.DoHTTPRequestAddr, input); !bytes.Equal(found, output) {
+		t.Fatalf("unexpected output %x", found)
+	}
+
+	data, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Fatal("the authorization header is not redacted")
+	}
+	if !strings.Contains(string(data), `"body": "{\"result\":\"0x1\"}"`) {
+		t.Fatalf("the response is not recorded as text:\n%s", data)
+	}
+
+	// the recorded request is served without suave-geth
+	t.Setenv("PATH", "")
+	t.Setenv(httpModeEnv, "replay")
+	if found := runPrecompile(t, suavelib.DoHTTPRequestAddr, input); !bytes.Equal(found, output) {
+		t.Fatalf("unexpected output %x", found)
+	}
+}
+
+func TestDoHTTPRequest_Sequence(t *testing.T) {
+	dir := t.TempDir()
+	cassette := filepath.Join(dir, "cassette.json")
+	t.Setenv(stateDirEnv, t.TempDir())
+	t.Setenv(cassetteEnv, cassette)
+
+	// fake suave-geth that returns a different block number in each call
+	first, _ := suavelib.PackDoHTTPRequestOutputs([]byte(`{"result":"0x1"}`))
+	second, _ := suavelib.PackDoHTTPRequestOutputs([]byte(`{"result":"0x2"}`))
+	script := "#!/bin/sh\nif [ -f " + dir + "/called ]; then echo " + hexutil.Encode(second) + "; else touch " + dir + "/called; echo " + hexutil.Encode(first) + "; fi\n"
+	if err := os.WriteFile(filepath.Join(dir, "suave-geth"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	input, _ := suavelib.PackDoHTTPRequestInputs(suavelib.HttpRequest{
+		Url:    "http://localhost:8545",
+		Method: "POST",
+		Body:   []byte(`{"method":"eth_blockNumber"}`),
+	})
+	newSession := func() string {
+		output, err := runSession(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values, _ := sessionOutputs.Unpack(output)
+		return common.Hash(values[0].([32]byte)).Hex()
+	}
+	call := func(session string) []byte {
+		output, err := run([]string{"--session", session, suavelib.DoHTTPRequestAddr.Hex(), hexutil.Encode(input)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return output
+	}
+	countInteractions := func() int {
+		c, err := loadCassette(cassette)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(c.Interactions)
+	}
+
+	// both identical requests are recorded
+	t.Setenv(httpModeEnv, "record")
+	session := newSession()
+	call(session)
+	call(session)
+	if n := countInteractions(); n != 2 {
+		t.Fatalf("expected 2 interactions but found %d", n)
+	}
+
+	// the n-th request gets the n-th response and the last one after that
+	t.Setenv(httpModeEnv, "replay")
+	session = newSession()
+	for indx, expected := range [][]byte{first, second, second} {
+		if found := call(session); !bytes.Equal(found, expected) {
+			t.Fatalf("unexpected output for request %d: %x", indx, found)
+		}
+	}
+
+	// recording again replaces the interactions in order
+	t.Setenv(httpModeEnv, "record")
+	session = newSession()
+	call(session)
+	call(session)
+	if n := countInteractions(); n != 2 {
+		t.Fatalf("expected 2 interactions but found %d", n)
+	}
+}
+
+func TestDoHTTPRequest_Replay(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	data := `{
+		"interactions": [
+			{
+				"precompile": "DO_HTTPREQUEST2",
+				"request": {"url": "http://localhost:8545", "method": "POST", "body": "{\"method\":\"eth_blockNumber\",\"id\":1}"},
+				"response": {"status": 200, "body": "{\"result\":\"0x1\"}"}
+			},
+			{
+				"precompile": "DO_HTTPREQUEST2",
+				"request": {"url": "http://localhost:8545", "method": "GET"},
+				"revert": "connection refused"
+			}
+		]
+	}`
+	if err := os.WriteFile(cassette, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(httpModeEnv, "replay")
+	t.Setenv(cassetteEnv, cassette)
+
+	request := suavelib.HttpRequest{
+		Url:    "http://localhost:8545",
+		Method: "POST",
+		Body:   []byte(`{"method":"eth_blockNumber","id":1}`),
+	}
+	input, _ := suavelib.PackDoHTTPRequest2Inputs(request)
+	response, err := suavelib.UnpackDoHTTPRequest2Outputs(runPrecompile(t, suavelib.DoHTTPRequest2Addr, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != 200 || string(response.Body) != `{"result":"0x1"}` {
+		t.Fatalf("unexpected response %+v", response)
+	}
+
+	// the revert is replayed as is
+	request.Method, request.Body = "GET", nil
+	input, _ = suavelib.PackDoHTTPRequest2Inputs(request)
+	if _, err := run([]string{suavelib.DoHTTPRequest2Addr.Hex(), hexutil.Encode(input)}); err == nil || err.Error() != "connection refused" {
+		t.Fatalf("expected the recorded revert but found %v", err)
+	}
+
+	// a request without a match fails with the diff of the body
+	request.Method, request.Body = "POST", []byte(`{"method":"eth_blockNumber","id":2}`)
+	input, _ = suavelib.PackDoHTTPRequest2Inputs(request)
+	_, err = run([]string{suavelib.DoHTTPRequest2Addr.Hex(), hexutil.Encode(input)})
+	if err == nil {
+		t.Fatal("expected an error for a request without a match")
+	}
+	if !strings.Contains(err.Error(), "closest entry (#0)") || !strings.Contains(err.Error(), "-    \"id\": 1") || !strings.Contains(err.Error(), "+    \"id\": 2") {
+		t.Fatalf("expected the diff of the body: %v", err)
+	}
+
+	// the request matches if the body is not matched
+	t.Setenv(httpMatchEnv, "url,method")
+	runPrecompile(t, suavelib.DoHTTPRequest2Addr, input)
+}
+
+func TestLineDiff(t *testing.T) {
+	diff := lineDiff([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+	expected := []string{" a", "-b", " c", "+d"}
+	if strings.Join(diff, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected diff %q", diff)
+	}
+}
+
+func ethRPCRequest(t *testing.T, server http.Handler, body string) map[string]interface{} {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response '%s': %v", rec.Body.String(), err)
+	}
+	if response["jsonrpc"] != "2.0" {
+		t.Fatalf("invalid jsonrpc version in response '%s'", rec.Body.String())
+	}
+	return response
+}
+
+func TestEthRPC(t *testing.T) {
+	fixture, err := loadEthRPCFixture("../../test/fixtures/ethrpc_mainnet.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contract := common.HexToAddress("0x1234")
+	fixture.Accounts[contract] = &stateAccount{
+		// returns the storage slot 0
+		Code:    hexutil.MustDecode("0x60005460005260206000f3"),
+		Storage: map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(7))},
+	}
+	server := jsonRPCServer(newEthRPCServer(fixture).handle)
+
+	cases := []struct {
+		name   string
+		body   string
+		result interface{}
+		code   float64
+	}{
+		{
+			name:   "balance",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0xa94f5374fce5edbc8e2a8697c15331677e6ebf0b","latest"]}`,
+			result: "0xde0b6b3a7640000",
+		},
+		{
+			name:   "nonce",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"eth_getTransactionCount","params":["0xA94F5374Fce5edBC8E2a8697C15331677e6EbF0B","latest"]}`,
+			result: "0x3",
+		},
+		{
+			name:   "unknown account",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"eth_getTransactionCount","params":["0x0000000000000000000000000000000000000001","0x1"]}`,
+			result: "0x0",
+		},
+		{
+			name:   "fixture call",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x00000000219ab540356cBB839Cbe05303d7705Fa","data":"0x621fd130"},"latest"]}`,
+			result: fixture.Calls[0].Result.String(),
+		},
+		{
+			name:   "code",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x0000000000000000000000000000000000001234","data":"0x"},"latest"]}`,
+			result: common.BigToHash(big.NewInt(7)).Hex(),
+		},
+		{
+			name:   "override code",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x00000000219ab540356cBB839Cbe05303d7705Fa","data":"0x621fd130"},"latest",{"0x00000000219ab540356cBB839Cbe05303d7705Fa":{"code":"0x600a60005260206000f3"}}]}`,
+			result: common.BigToHash(big.NewInt(10)).Hex(),
+		},
+		{
+			// the fixture call is not returned and the account has no code
+			name:   "override balance",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x00000000219ab540356cBB839Cbe05303d7705Fa","data":"0x621fd130"},"latest",{"0x00000000219ab540356cBB839Cbe05303d7705Fa":{"balance":"0x1"}}]}`,
+			result: "0x",
+		},
+		{
+			name:   "override state",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x0000000000000000000000000000000000001234"},"latest",{"0x0000000000000000000000000000000000001234":{"stateDiff":{"0x0000000000000000000000000000000000000000000000000000000000000000":"0x000000000000000000000000000000000000000000000000000000000000000b"}}}]}`,
+			result: common.BigToHash(big.NewInt(11)).Hex(),
+		},
+		{
+			name: "revert",
+			body: `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x0000000000000000000000000000000000001234"},"latest",{"0x0000000000000000000000000000000000001234":{"code":"0x60006000fd"}}]}`,
+			code: 3,
+		},
+		{
+			name: "invalid version",
+			body: `{"jsonrpc":"1.0","id":1,"method":"eth_blockNumber","params":[]}`,
+			code: rpcInvalidRequest,
+		},
+		{
+			name: "invalid json",
+			body: `{"jsonrpc":"2.0",`,
+			code: rpcParseError,
+		},
+		{
+			name: "unknown method",
+			body: `{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x"]}`,
+			code: rpcMethodNotFound,
+		},
+		{
+			name: "invalid address",
+			body: `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x1234","latest"]}`,
+			code: rpcInvalidParams,
+		},
+		{
+			name: "missing block",
+			body: `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x0000000000000000000000000000000000000001"]}`,
+			code: rpcInvalidParams,
+		},
+		{
+			name: "invalid block",
+			body: `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x0000000000000000000000000000000000000001","newest"]}`,
+			code: rpcInvalidParams,
+		},
+		{
+			name: "unknown call field",
+			body: `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x0000000000000000000000000000000000001234","calldata":"0x"},"latest"]}`,
+			code: rpcInvalidParams,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			response := ethRPCRequest(t, server, c.body)
+			if c.code != 0 {
+				rpcErr, ok := response["error"].(map[string]interface{})
+				if !ok {
+					t.Fatalf("expected an error but found %v", response)
+				}
+				if rpcErr["code"] != c.code {
+					t.Fatalf("expected error code %v but found %v", c.code, rpcErr)
+				}
+				return
+			}
+			if response["result"] != c.result {
+				t.Fatalf("expected result %v but found %v", c.result, response)
+			}
+		})
+	}
+}
+
+func TestEthRPC_Batch(t *testing.T) {
+	server := jsonRPCServer(newEthRPCServer(&ethRPCFixture{ChainID: 5}).handle)
+
+	rec := httptest.NewRecorder()
+	body := `[{"jsonrpc":"2.0","id":"a","method":"eth_chainId"},{"jsonrpc":"2.0","method":"eth_chainId"},{"jsonrpc":"2.0","id":2,"method":"eth_unknown"}]`
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	var responses []*rpcResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatal(err)
+	}
+	// the notification has no response
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses but found %d", len(responses))
+	}
+	if string(responses[0].ID) != `"a"` || responses[0].Result != "0x5" {
+		t.Fatalf("unexpected response %+v", responses[0])
+	}
+	if string(responses[1].ID) != "2" || responses[1].Error == nil || responses[1].Error.Code != rpcMethodNotFound {
+		t.Fatalf("unexpected response %+v", responses[1])
+	}
+}
+
+func signedTestTx(t *testing.T, nonce uint64) []byte {
+	t.Helper()
+
+	key, _ := crypto.HexToECDSA(testSigningKey)
+	to := common.HexToAddress("0x095e7baea6a6c7c4c2dfeb977efac326af552d87")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(10),
+		Gas:       21000,
+		To:        &to,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := tx.MarshalBinary()
+	return raw
+}
+
+func relayRequest(t *testing.T, server http.Handler, body string, sign bool) (int, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if sign {
+		key, _ := crypto.HexToECDSA(testSigningKey)
+		sig, err := crypto.Sign(accounts.TextHash([]byte(crypto.Keccak256Hash([]byte(body)).Hex())), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(flashbotsSignatureHeader, crypto.PubkeyToAddress(key.PublicKey).Hex()+":"+hexutil.Encode(sig))
+	}
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response '%s': %v", rec.Body.String(), err)
+	}
+	return rec.Code, response
+}
+
+func TestRelay(t *testing.T) {
+	r := &relay{logPath: filepath.Join(t.TempDir(), "bundles.jsonl")}
+	server := r.handler()
+
+	tx1, tx2 := hexutil.Encode(signedTestTx(t, 0)), hexutil.Encode(signedTestTx(t, 1))
+
+	// the bodies as encoded by Bundle.sol and MevShare.sol
+	ethBundle := `{"jsonrpc":"2.0","method":"eth_sendBundle","params":[{"blockNumber": "0x1", "txs": ["` + tx1 + `","` + tx2 + `"], "minTimestamp": 2}],"id":1}`
+	mevBundle := `{"jsonrpc":"2.0","method":"mev_sendBundle","params":[{"version":"v0.1","inclusion":{"block":"0x2"},"body":[{"tx":"` + tx1 + `","canRevert":true}],"validity":{"refund":[{"bodyIdx":0,"percent":10}]}}],"id":1}`
+
+	for _, body := range []string{ethBundle, mevBundle} {
+		status, response := relayRequest(t, server, body, true)
+		if status != http.StatusOK || response["result"] == nil {
+			t.Fatalf("expected the bundle to be accepted but found %d %v", status, response)
+		}
+	}
+
+	cases := []struct {
+		name string
+		body string
+		sign bool
+		// status is the expected http status, the bundle is not logged if it is not 200
+		status int
+		error  string
+	}{
+		{"unsigned", ethBundle, false, http.StatusUnauthorized, "missing X-Flashbots-Signature"},
+		{"invalid rlp", strings.Replace(ethBundle, tx1, "0x1234", 1), true, http.StatusOK, "invalid transaction 0"},
+		{"missing block", strings.Replace(ethBundle, `"blockNumber": "0x1", `, "", 1), true, http.StatusOK, "missing blockNumber"},
+		{"unknown field", strings.Replace(ethBundle, `"minTimestamp"`, `"minTime"`, 1), true, http.StatusOK, "unknown field"},
+		{"invalid version", strings.Replace(mevBundle, "v0.1", "v0.2", 1), true, http.StatusOK, "unsupported version"},
+		{"refund out of range", strings.Replace(mevBundle, `"bodyIdx":0`, `"bodyIdx":1`, 1), true, http.StatusOK, "bodyIdx 1 out of range"},
+		{"unknown method", `{"jsonrpc":"2.0","method":"eth_sendRawTransaction","params":[],"id":1}`, true, http.StatusOK, "does not exist"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, response := relayRequest(t, server, c.body, c.sign)
+			if status != c.status {
+				t.Fatalf("expected status %d but found %d", c.status, status)
+			}
+			rpcErr, ok := response["error"].(map[string]interface{})
+			if !ok || !strings.Contains(rpcErr["message"].(string), c.error) {
+				t.Fatalf("expected error '%s' but found %v", c.error, response)
+			}
+		})
+	}
+
+	// the signature of another body
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(ethBundle))
+	key, _ := crypto.HexToECDSA(testSigningKey)
+	sig, _ := crypto.Sign(accounts.TextHash([]byte(crypto.Keccak256Hash([]byte(mevBundle)).Hex())), key)
+	req.Header.Set(flashbotsSignatureHeader, crypto.PubkeyToAddress(key.PublicKey).Hex()+":"+hexutil.Encode(sig))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected the signature to be rejected but found %d %s", rec.Code, rec.Body.String())
+	}
+
+	query := func(params string) []*relayBundle {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/bundles"+params, nil))
+		var bundles []*relayBundle
+		if err := json.Unmarshal(rec.Body.Bytes(), &bundles); err != nil {
+			t.Fatal(err)
+		}
+		return bundles
+	}
+
+	accepted := query("?accepted=true")
+	if len(accepted) != 2 {
+		t.Fatalf("expected 2 accepted bundles but found %d", len(accepted))
+	}
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	if accepted[0].Method != "eth_sendBundle" || *accepted[0].Signer != signer || len(accepted[0].Txs) != 2 || *accepted[0].Txs[1].From != signer || *accepted[0].Txs[1].Nonce != 1 {
+		t.Fatalf("unexpected bundle %+v", accepted[0])
+	}
+	if accepted[1].Method != "mev_sendBundle" || accepted[1].Block != 2 || !accepted[1].Txs[0].CanRevert {
+		t.Fatalf("unexpected bundle %+v", accepted[1])
+	}
+	// the rejected bundles are logged too
+	if bundles := query("?method=mev_sendBundle"); len(bundles) != 3 {
+		t.Fatalf("expected 3 mev_sendBundle bundles but found %d", len(bundles))
+	}
+	if bundles := query("?block=0x2&signer=" + signer.Hex()); len(bundles) != 1 {
+		t.Fatalf("expected 1 bundle for block 2 but found %d", len(bundles))
+	}
+
+	data, err := os.ReadFile(r.logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != len(query("")) {
+		t.Fatalf("expected a line per bundle in the log but found %d", lines)
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/bundles", nil))
+	if bundles := query(""); len(bundles) != 0 {
+		t.Fatalf("expected the log to be cleared but found %d bundles", len(bundles))
+	}
+}
+
+func TestOpenAI(t *testing.T) {
+	script, err := loadOpenAIScript("../../test/fixtures/openai_script.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	script.Completions = append([]*scriptedCompletion{
+		{Match: "Retry", Times: 1, Status: http.StatusTooManyRequests},
+		{Match: "Retry", Content: "Done"},
+	}, script.Completions...)
+	server := &openAIServer{script: script}
+
+	request := func(body string, auth bool) (int, map[string]interface{}, string) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth {
+			req.Header.Set("Authorization", "Bearer test-key")
+		}
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+
+		var response map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &response)
+		return rec.Code, response, rec.Body.String()
+	}
+	// the body as encoded by ChatGPT.sol
+	body := func(model, content, temperature string) string {
+		return `{"model": "` + model + `", "messages": [{"role": "system", "content": "You are a helpful assistant."},{"role": "user", "content": "` + content + `"}], "temperature":` + temperature + `}`
+	}
+	content := func(response map[string]interface{}) interface{} {
+		choices, _ := response["choices"].([]interface{})
+		if len(choices) != 1 {
+			return nil
+		}
+		return choices[0].(map[string]interface{})["message"].(map[string]interface{})["content"]
+	}
+
+	status, response, _ := request(body("gpt-3.5-turbo", "Say this is a test!", "0.7"), true)
+	if status != http.StatusOK || content(response) != "This is a test!" {
+		t.Fatalf("unexpected response %d %v", status, response)
+	}
+
+	cases := []struct {
+		name   string
+		body   string
+		auth   bool
+		status int
+		error  string
+	}{
+		{"missing key", body("gpt-4", "Say this is a test!", "0.7"), false, http.StatusUnauthorized, "API key"},
+		{"invalid model", body("gpt-unknown", "Say this is a test!", "0.7"), true, http.StatusNotFound, "gpt-unknown"},
+		{"invalid temperature", body("gpt-4", "Say this is a test!", "2.5"), true, http.StatusBadRequest, "temperature"},
+		{"invalid role", strings.Replace(body("gpt-4", "Say this is a test!", "0.7"), `"system"`, `"bot"`, 1), true, http.StatusBadRequest, "messages.0.role"},
+		{"invalid json", body("gpt-4", `"quoted"`, "0.7"), true, http.StatusBadRequest, "parse the JSON body"},
+		{"rate limit", body("gpt-4", "Rate limited", "0.7"), true, http.StatusTooManyRequests, "Rate limit reached"},
+		{"server error", body("gpt-4", "Server error", "0.7"), true, http.StatusInternalServerError, "The server had an error"},
+		{"no match", body("gpt-4", "Unknown", "0.7"), true, http.StatusInternalServerError, "no scripted completion"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, response, raw := request(c.body, c.auth)
+			if status != c.status {
+				t.Fatalf("expected status %d but found %d: %s", c.status, status, raw)
+			}
+			rpcErr, ok := response["error"].(map[string]interface{})
+			if !ok || !strings.Contains(rpcErr["message"].(string), c.error) {
+				t.Fatalf("expected error '%s' but found %s", c.error, raw)
+			}
+		})
+	}
+
+	if _, _, raw := request(body("gpt-4", "Malformed", "0.7"), true); json.Valid([]byte(raw)) {
+		t.Fatalf("expected malformed JSON but found %s", raw)
+	}
+
+	// the rate limit is only returned for the first request
+	if status, _, _ := request(body("gpt-4", "Retry", "0.7"), true); status != http.StatusTooManyRequests {
+		t.Fatalf("expected the rate limit but found %d", status)
+	}
+	if status, response, _ := request(body("gpt-4", "Retry", "0.7"), true); status != http.StatusOK || content(response) != "Done" {
+		t.Fatalf("unexpected response %d %v", status, response)
+	}
+}
+
+// builderTx returns a transaction to the Example contract of the builder fixtures.
+func builderTx(t *testing.T, nonce uint64, data []byte) *types.Transaction {
+	t.Helper()
+
+	key, _ := crypto.HexToECDSA("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1337)), &types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: big.NewInt(875000000),
+		Gas:      1000000,
+		To:       &to,
+		Data:     data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// suavexTx returns a signed transaction encoded as by Transactions.encodeJSON.
+func suavexTx(t *testing.T, nonce uint64, data []byte) string {
+	t.Helper()
+
+	tx := builderTx(t, nonce, data)
+	v, r, s := tx.RawSignatureValues()
+	return `{"input":"` + hexutil.Encode(data) + `","to":"` + strings.ToLower(tx.To().Hex()) + `","gas":"0xf4240","gasPrice":"0x342770c0","nonce":"` + hexutil.EncodeUint64(nonce) +
+		`","value":"0x0","chainId":"0x539","r":"` + common.BigToHash(r).Hex() + `","s":"` + common.BigToHash(s).Hex() + `","v":"` + hexutil.EncodeBig(v) + `"}`
+}
+
+func TestSuavex(t *testing.T) {
+	fixture, err := loadSuavexFixture("../../test/fixtures/suavex_builder.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := jsonRPCServer(newSuavexServer(fixture).handle)
+
+	call := func(method string, params ...string) map[string]interface{} {
+		t.Helper()
+		return ethRPCRequest(t, server, `{"jsonrpc":"2.0","method":"`+method+`","params":[`+strings.Join(params, ",")+`],"id":1}`)
+	}
+	result := func(response map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		res, ok := response["result"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("expected a result but found %v", response)
+		}
+		return res
+	}
+
+	// the args as encoded by Types.encodeBuildBlockArgs
+	args := `{"slot":"0x1","proposerPubkey":"0x1234","parent":"0x0000000000000000000000000000000000000000000000000000000000000000","timestamp":"0x7b","feeRecipient":"0x0000000000000000000000000000000000001234","gasLimit":"0x7b","random":"0x1234000000000000000000000000000000000000000000000000000000000000","withdrawals":[],"extra":"0x1234","beaconRoot":"0x1234000000000000000000000000000000000000000000000000000000000000","fillPending":true}`
+	session, err := json.Marshal(call("suavex_newSession", args)["result"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// get(1234) emits SomeEvent(1234)
+	getData := append(crypto.Keccak256([]byte("get(uint256)"))[:4], common.BigToHash(big.NewInt(1234)).Bytes()...)
+	tx := suavexTx(t, 1, getData)
+
+	res := result(call("suavex_addTransaction", string(session), tx))
+	expected, err := os.ReadFile("../../test/fixtures/suave_builder_simulateTransactionResult_success.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expectedRes map[string]interface{}
+	json.Unmarshal(expected, &expectedRes)
+	expectedRes["egp"] = "0x342770c0"
+	if found, _ := json.Marshal(res); !bytes.Equal(found, must(json.Marshal(expectedRes))) {
+		t.Fatalf("expected %s but found %s", must(json.Marshal(expectedRes)), found)
+	}
+
+	// the nonce is used
+	res = result(call("suavex_addTransaction", string(session), tx))
+	if res["success"] != false || !strings.Contains(res["error"].(string), "nonce too low") {
+		t.Fatalf("expected the nonce to be used but found %v", res)
+	}
+
+	// scripted failure
+	res = result(call("suavex_addTransaction", string(session), suavexTx(t, 2, hexutil.MustDecode("0xdeadbeef"))))
+	if res["success"] != false || res["error"] != "some error" {
+		t.Fatalf("expected the scripted failure but found %v", res)
+	}
+
+	// the code reverts for unknown selectors
+	res = result(call("suavex_addTransaction", string(session), suavexTx(t, 2, hexutil.MustDecode("0x12345678"))))
+	if res["success"] != false || !strings.Contains(res["error"].(string), "execution reverted") {
+		t.Fatalf("expected the transaction to revert but found %v", res)
+	}
+
+	// the calls run on the state of the session
+	if response := call("suavex_call", string(session), `{"to":"0x5fbdb2315678afecb367f032d93f642f64180aa3","data":"`+hexutil.Encode(getData)+`"}`); response["error"] != nil || response["result"] != "0x" {
+		t.Fatalf("unexpected call response %v", response)
+	}
+	response := call("suavex_call", string(session), `{"to":"0x5fbdb2315678afecb367f032d93f642f64180aa3","data":"0x12345678","value":"0x1"}`)
+	if rpcErr, ok := response["error"].(map[string]interface{}); !ok || rpcErr["code"] != float64(3) || !strings.Contains(rpcErr["message"].(string), "execution reverted") {
+		t.Fatalf("expected the call to revert but found %v", response)
+	}
+
+	// the sessions are independent
+	other, _ := json.Marshal(call("suavex_newBuilder", args)["result"])
+	if res := result(call("suavex_addTransaction", string(other), tx)); res["success"] != true {
+		t.Fatalf("expected the transaction to succeed in a new session but found %v", res)
+	}
+
+	if response := call("suavex_bid", string(session), `"0xb6b973370f9684a2bc0b89f873b772b01269277196e84b69fe8ebad8908e777c09cdfad9d4a2f849e12ecd12ba9dce20"`); response["error"] == nil {
+		t.Fatalf("expected an error for a bid before building the block but found %v", response)
+	}
+	if response := call("suavex_buildBlock", string(session)); response["error"] != nil {
+		t.Fatalf("unexpected error %v", response)
+	}
+	bid := result(call("suavex_bid", string(session), `"0xb6b973370f9684a2bc0b89f873b772b01269277196e84b69fe8ebad8908e777c09cdfad9d4a2f849e12ecd12ba9dce20"`))
+	if bid["root"] == nil || bid["message"].(map[string]interface{})["numTxs"] != "0x1" {
+		t.Fatalf("unexpected bid %v", bid)
+	}
+
+	if response := call("suavex_addTransaction", `"unknown"`, tx); response["error"] == nil {
+		t.Fatalf("expected an error for an unknown session but found %v", response)
+	}
+	if response := call("suavex_newSession", strings.Replace(args, `"slot":"0x1"`, `"slot":1`, 1)); response["error"].(map[string]interface{})["code"] != float64(rpcInvalidParams) {
+		t.Fatalf("expected invalid params but found %v", response)
+	}
+}
+
+func TestSuavex_GasLimit(t *testing.T) {
+	fixture, err := loadSuavexFixture("../../test/fixtures/suavex_builder.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the gas limit of the node allows a single transaction
+	fixture.GasLimit = 1010000
+	server := jsonRPCServer(newSuavexServer(fixture).handle)
+
+	call := func(method string, params ...string) map[string]interface{} {
+		t.Helper()
+		return ethRPCRequest(t, server, `{"jsonrpc":"2.0","method":"`+method+`","params":[`+strings.Join(params, ",")+`],"id":1}`)
+	}
+
+	// the gasLimit of the args does not limit the block, as in suave-geth
+	args := `{"slot":"0x1","proposerPubkey":"0x1234","parent":"0x0000000000000000000000000000000000000000000000000000000000000000","timestamp":"0x7b","feeRecipient":"0x0000000000000000000000000000000000001234","gasLimit":"0x7b","random":"0x1234000000000000000000000000000000000000000000000000000000000000","withdrawals":[],"extra":"0x1234","beaconRoot":"0x1234000000000000000000000000000000000000000000000000000000000000","fillPending":true}`
+	session, _ := json.Marshal(call("suavex_newSession", args)["result"])
+
+	getData := append(crypto.Keccak256([]byte("get(uint256)"))[:4], common.BigToHash(big.NewInt(1234)).Bytes()...)
+	if res := call("suavex_addTransaction", string(session), suavexTx(t, 1, getData))["result"].(map[string]interface{}); res["success"] != true {
+		t.Fatalf("expected the first transaction to succeed but found %v", res)
+	}
+	res := call("suavex_addTransaction", string(session), suavexTx(t, 2, getData))["result"].(map[string]interface{})
+	if res["success"] != false || !strings.Contains(res["error"].(string), "gas limit reached") {
+		t.Fatalf("expected the gas limit to be reached but found %v", res)
+	}
+
+	call("suavex_buildBlock", string(session))
+	bid := call("suavex_bid", string(session), `"0xb6b973370f9684a2bc0b89f873b772b01269277196e84b69fe8ebad8908e777c09cdfad9d4a2f849e12ecd12ba9dce20"`)["result"].(map[string]interface{})
+	if message := bid["message"].(map[string]interface{}); message["gasLimit"] != "0xf6950" {
+		t.Fatalf("expected the gas limit of the node in the bid but found %v", message)
+	}
+}
+
+func TestSimulateTransaction(t *testing.T) {
+	t.Setenv(stateDirEnv, t.TempDir())
+	t.Setenv(stateEnv, "../../test/fixtures/suave_state.json")
+
+	output, err := runSession(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, _ := sessionOutputs.Unpack(output)
+	session := common.Hash(values[0].([32]byte)).Hex()
+
+	call := func(addr common.Address, input []byte) ([]byte, error) {
+		return run([]string{"--session", session, addr.Hex(), hexutil.Encode(input)})
+	}
+	newBuilder := func() string {
+		t.Helper()
+		output, err := call(suavelib.NewBuilderAddr, must(suavelib.PackNewBuilderInputs()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, _ := suavelib.UnpackNewBuilderOutputs(output)
+		return id
+	}
+	simulate := func(builder string, tx *types.Transaction) suavelib.SimulateTransactionResult {
+		t.Helper()
+		output, err := call(suavelib.SimulateTransactionAddr, must(suavelib.PackSimulateTransactionInputs(builder, must(tx.MarshalBinary()))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := suavelib.UnpackSimulateTransactionOutputs(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	builder := newBuilder()
+	getData := append(crypto.Keccak256([]byte("get(uint256)"))[:4], common.BigToHash(big.NewInt(1234)).Bytes()...)
+	tx := builderTx(t, 1, getData)
+
+	result := simulate(builder, tx)
+	if !result.Success || result.Egp != 875000000 || len(result.Logs) != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if log := result.Logs[0]; log.Addr != *tx.To() || common.BytesToHash(log.Data) != common.BigToHash(big.NewInt(1234)) {
+		t.Fatalf("unexpected log %+v", log)
+	}
+
+	// the state of the builder is kept between the calls
+	if result := simulate(builder, tx); result.Success || !strings.Contains(result.Error, "nonce too low") {
+		t.Fatalf("expected the nonce to be used but found %+v", result)
+	}
+	// a reverted transaction is not added to the block
+	if result := simulate(builder, builderTx(t, 2, hexutil.MustDecode("0x12345678"))); result.Success || result.Error != "execution failed: execution reverted" {
+		t.Fatalf("expected the transaction to revert but found %+v", result)
+	}
+	if result := simulate(builder, builderTx(t, 2, getData)); !result.Success {
+		t.Fatalf("expected the next nonce to succeed but found %+v", result)
+	}
+
+	// the builders are independent
+	other := newBuilder()
+	if other == builder {
+		t.Fatal("expected a new builder id")
+	}
+	if result := simulate(other, tx); !result.Success {
+		t.Fatalf("expected the transaction to succeed in a new builder but found %+v", result)
+	}
+
+	// the effective gas price does not fit in the uint64 of the result
+	key, _ := crypto.HexToECDSA("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	expensive, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1337)), &types.LegacyTx{
+		Nonce:    3,
+		GasPrice: new(big.Int).Lsh(big.NewInt(1), 64),
+		Gas:      1000000,
+		To:       tx.To(),
+		Data:     getData,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call(suavelib.SimulateTransactionAddr, must(suavelib.PackSimulateTransactionInputs(builder, must(expensive.MarshalBinary())))); err == nil || !strings.Contains(err.Error(), "overflows uint64") {
+		t.Fatalf("expected an overflow error but found %v", err)
+	}
+
+	if _, err := call(suavelib.SimulateTransactionAddr, must(suavelib.PackSimulateTransactionInputs("unknown", must(tx.MarshalBinary())))); err == nil {
+		t.Fatal("expected an error for an unknown builder")
+	}
+	if _, err := run([]string{suavelib.NewBuilderAddr.Hex(), hexutil.Encode(must(suavelib.PackNewBuilderInputs()))}); err == nil {
+		t.Fatal("expected an error for a builder without a session")
+	}
+}
+
+func TestSimulateBundle(t *testing.T) {
+	t.Setenv(stateEnv, "../../test/fixtures/suave_state.json")
+
+	getData := append(crypto.Keccak256([]byte("get(uint256)"))[:4], common.BigToHash(big.NewInt(1234)).Bytes()...)
+	tx1 := hexutil.Encode(must(builderTx(t, 1, getData).MarshalBinary()))
+	reverted := builderTx(t, 2, hexutil.MustDecode("0x12345678"))
+	tx2 := hexutil.Encode(must(reverted.MarshalBinary()))
+
+	simulateBundle := func(bundle string) (uint64, error) {
+		output, err := run([]string{suavelib.SimulateBundleAddr.Hex(), hexutil.Encode(must(suavelib.PackSimulateBundleInputs([]byte(bundle))))})
+		if err != nil {
+			return 0, err
+		}
+		return suavelib.UnpackSimulateBundleOutputs(output)
+	}
+
+	// the coinbase only gets the fees, so the egp is the gas price
+	if egp, err := simulateBundle(`{"blockNumber":"0x2","txs":["` + tx1 + `"]}`); err != nil || egp != 875000000 {
+		t.Fatalf("expected the gas price but found %d (%v)", egp, err)
+	}
+	if _, err := simulateBundle(`{"txs":["` + tx1 + `","` + tx2 + `"]}`); err == nil || !strings.Contains(err.Error(), "execution reverted") {
+		t.Fatalf("expected the bundle to revert but found %v", err)
+	}
+	if egp, err := simulateBundle(`{"txs":["` + tx1 + `","` + tx2 + `"],"revertingHashes":["` + reverted.Hash().Hex() + `"]}`); err != nil || egp != 875000000 {
+		t.Fatalf("expected the reverting hash to be allowed but found %d (%v)", egp, err)
+	}
+	// the transactions run on top of the state file and not on the previous bundles
+	if _, err := simulateBundle(`{"txs":["` + tx2 + `"]}`); err == nil || !strings.Contains(err.Error(), "nonce too high") {
+		t.Fatalf("expected the nonce to be too high but found %v", err)
+	}
+	if _, err := simulateBundle(`{"txs":[]}`); err == nil {
+		t.Fatal("expected an error for an empty bundle")
+	}
+}
+
+func TestLoadChainState(t *testing.T) {
+	// a state dump of geth has decimal balances, numeric nonces and short storage values
+	dump := `{"root":"0x1234","accounts":{"0x5fbdb2315678afecb367f032d93f642f64180aa3":{"balance":"1000","nonce":5,"root":"0x1234","codeHash":"0x1234","code":"0x6000","storage":{"0x0000000000000000000000000000000000000000000000000000000000000001":"0a"}}}}`
+	path := filepath.Join(t.TempDir(), "dump.json")
+	if err := os.WriteFile(path, []byte(dump), 0644); err != nil {
+		t.Fatal(err)
+	}
+	chain, err := loadChainState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := common.HexToAddress("0x5fbdb2315678afecb367f032d93f642f64180aa3")
+	if balance := chain.state.GetBalance(addr).Uint64(); balance != 1000 {
+		t.Fatalf("unexpected balance %d", balance)
+	}
+	if nonce := chain.state.GetNonce(addr); nonce != 5 {
+		t.Fatalf("unexpected nonce %d", nonce)
+	}
+	if value := chain.state.GetState(addr, common.HexToHash("0x1")); value != common.HexToHash("0xa") {
+		t.Fatalf("unexpected storage value %s", value.Hex())
+	}
+	if chain.env.ChainID != 1 || chain.env.Number != 1 || chain.env.GasLimit != defaultBlockGasLimit {
+		t.Fatalf("unexpected block %+v", chain.env)
+	}
+
+	// the genesis of the tests
+	chain, err = loadChainState("../../test/fixtures/suave_state.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain.env.ChainID != 1337 || chain.env.Number != 2 || chain.env.Coinbase != common.HexToAddress("0x8943545177806ed17b9f23f0a21ee5948ecaa776") {
+		t.Fatalf("unexpected block %+v", chain.env)
+	}
+	if nonce := chain.state.GetNonce(common.HexToAddress("0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266")); nonce != 1 {
+		t.Fatalf("unexpected nonce %d", nonce)
+	}
+}
+
+func must(data []byte, err error) []byte {
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
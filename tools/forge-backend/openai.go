@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// openAIModels are the models accepted by the mock if the script does not list them.
+var openAIModels = []string{"gpt-3.5-turbo", "gpt-4", "gpt-4-turbo", "gpt-4o", "gpt-4o-mini"}
+
+// openAIRoles are the roles of the messages of a chat completion request.
+var openAIRoles = []string{"system", "user", "assistant", "tool"}
+
+// openAIScript are the responses of the mock OpenAI API.
+type openAIScript struct {
+	// Models are the accepted models
+	Models []string `json:"models,omitempty"`
+	// Completions are matched in order with the requests
+	Completions []*scriptedCompletion `json:"completions"`
+}
+
+// scriptedCompletion is a response of the mock OpenAI API.
+type scriptedCompletion struct {
+	// Match is the content of the last message of the requests that get this
+	// response. An empty Match matches any request.
+	Match string `json:"match,omitempty"`
+	// Times is the number of requests that get this response, 0 means any number.
+	// It allows a rate limit error to be followed by a completion.
+	Times int `json:"times,omitempty"`
+
+	// Content is the content of the message of the completion
+	Content string `json:"content,omitempty"`
+	// Status is the http status of an error response (i.e. 429 for a rate limit)
+	Status int `json:"status,omitempty"`
+	// Error is the message of the error response
+	Error string `json:"error,omitempty"`
+	// Raw is the body of the response instead of a completion (i.e. malformed JSON)
+	Raw *string `json:"raw,omitempty"`
+
+	used int
+}
+
+type chatCompletionRequest struct {
+	Model       string         `json:"model"`
+	Messages    []*chatMessage `json:"messages"`
+	Temperature *float64       `json:"temperature"`
+	TopP        *float64       `json:"top_p"`
+	MaxTokens   *int           `json:"max_tokens"`
+	N           *int           `json:"n"`
+	Stream      bool           `json:"stream"`
+	Stop        interface{}    `json:"stop"`
+	User        string         `json:"user"`
+}
+
+type chatMessage struct {
+	Role    string  `json:"role"`
+	Content *string `json:"content"`
+	Name    string  `json:"name,omitempty"`
+}
+
+// openAIError is the body of the error responses of the OpenAI API.
+type openAIError struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    *string `json:"code"`
+}
+
+func loadOpenAIScript(path string) (*openAIScript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &openAIScript{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(s); err != nil {
+		return nil, fmt.Errorf("failed to decode the script %s: %v", path, err)
+	}
+	if len(s.Models) == 0 {
+		s.Models = slices.Clone(openAIModels)
+	}
+	for indx, c := range s.Completions {
+		if c.Status != 0 && (c.Status < 400 || c.Status > 599) {
+			return nil, fmt.Errorf("completion %d: status %d is not an error status", indx, c.Status)
+		}
+	}
+	return s, nil
+}
+
+// openAIServer is a mock of the chat completions endpoint of the OpenAI API.
+type openAIServer struct {
+	lock   sync.Mutex
+	script *openAIScript
+}
+
+func (s *openAIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/chat/completions" {
+		writeOpenAIError(w, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("Invalid URL (%s %s)", r.Method, r.URL.Path), "", "")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "invalid_request_error", "Only POST requests are accepted", "", "")
+		return
+	}
+	if key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); !found || strings.TrimSpace(key) == "" {
+		writeOpenAIError(w, http.StatusUnauthorized, "invalid_request_error", "You didn't provide an API key, set it in the Authorization header as 'Bearer <key>'", "", "invalid_api_key")
+		return
+	}
+	if !strings.HasPrefix(strings.TrimSpace(r.Header.Get("Content-Type")), "application/json") {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", "The Content-Type header must be application/json", "", "")
+		return
+	}
+
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(r.Body); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error(), "", "")
+		return
+	}
+	req, param, err := s.validate(body.Bytes())
+	if err != nil {
+		// the API answers the requests for an unknown model with a 404
+		var notFound *modelNotFoundError
+		if errors.As(err, &notFound) {
+			writeOpenAIError(w, http.StatusNotFound, "invalid_request_error", err.Error(), "", "model_not_found")
+			return
+		}
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error(), param, "")
+		return
+	}
+
+	completion := s.next(*req.Messages[len(req.Messages)-1].Content)
+	switch {
+	case completion == nil:
+		writeOpenAIError(w, http.StatusInternalServerError, "server_error", "no scripted completion matches the request", "", "")
+	case completion.Raw != nil:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(*completion.Raw))
+	case completion.Status == http.StatusTooManyRequests:
+		msg := completion.Error
+		if msg == "" {
+			msg = fmt.Sprintf("Rate limit reached for %s on requests per min (RPM): Limit 3, Used 3, Requested 1.", req.Model)
+		}
+		w.Header().Set("Retry-After", "20")
+		writeOpenAIError(w, completion.Status, "requests", msg, "", "rate_limit_exceeded")
+	case completion.Status != 0:
+		writeOpenAIError(w, completion.Status, "server_error", completion.Error, "", "")
+	default:
+		writeJSON(w, http.StatusOK, newChatCompletion(req, body.Bytes(), completion.Content))
+	}
+}
+
+// modelNotFoundError is the error for a model that is not in the script.
+type modelNotFoundError struct {
+	model  string
+	models []string
+}
+
+func (e *modelNotFoundError) Error() string {
+	return fmt.Sprintf("The model `%s` does not exist or you do not have access to it. Expected one of %s", e.model, strings.Join(e.models, ", "))
+}
+
+// validate checks the request body as the OpenAI API does. It returns the
+// invalid parameter with the error.
+func (s *openAIServer) validate(body []byte) (*chatCompletionRequest, string, error) {
+	req := &chatCompletionRequest{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(req); err != nil {
+		return nil, "", fmt.Errorf("We could not parse the JSON body of your request: %v", err)
+	}
+	if req.Model == "" {
+		return nil, "model", fmt.Errorf("you must provide a model parameter")
+	}
+	if !slices.Contains(s.script.Models, req.Model) {
+		return nil, "model", &modelNotFoundError{model: req.Model, models: s.script.Models}
+	}
+	if req.Temperature != nil && (*req.Temperature < 0 || *req.Temperature > 2) {
+		return nil, "temperature", fmt.Errorf("%v is not in [0, 2] - 'temperature'", *req.Temperature)
+	}
+	if req.Stream {
+		return nil, "stream", fmt.Errorf("streaming is not supported by the mock")
+	}
+	if len(req.Messages) == 0 {
+		return nil, "messages", fmt.Errorf("[] is too short - 'messages'")
+	}
+	for indx, msg := range req.Messages {
+		if !slices.Contains(openAIRoles, msg.Role) {
+			return nil, fmt.Sprintf("messages.[%d].role", indx), fmt.Errorf("'%s' is not one of %s - 'messages.%d.role'", msg.Role, strings.Join(openAIRoles, ", "), indx)
+		}
+		if msg.Content == nil {
+			return nil, fmt.Sprintf("messages.[%d].content", indx), fmt.Errorf("None is not of type 'string' - 'messages.%d.content'", indx)
+		}
+	}
+	return req, "", nil
+}
+
+// next returns the first scripted completion that matches the content of the last message.
+func (s *openAIServer) next(content string) *scriptedCompletion {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, c := range s.script.Completions {
+		if c.Match != "" && c.Match != content {
+			continue
+		}
+		if c.Times != 0 && c.used >= c.Times {
+			continue
+		}
+		c.used++
+		return c
+	}
+	return nil
+}
+
+func newChatCompletion(req *chatCompletionRequest, body []byte, content string) map[string]interface{} {
+	// the words are a good enough approximation of the tokens
+	promptTokens := 0
+	for _, msg := range req.Messages {
+		promptTokens += len(strings.Fields(*msg.Content))
+	}
+	completionTokens := len(strings.Fields(content))
+
+	return map[string]interface{}{
+		"id":      fmt.Sprintf("chatcmpl-%x", crypto.Keccak256(body)[:12]),
+		"object":  "chat.completion",
+		"created": time.Now().Unix(),
+		"model":   req.Model,
+		"choices": []interface{}{
+			map[string]interface{}{
+				"index":         0,
+				"message":       map[string]interface{}{"role": "assistant", "content": content},
+				"logprobs":      nil,
+				"finish_reason": "stop",
+			},
+		},
+		"usage": map[string]interface{}{
+			"prompt_tokens":     promptTokens,
+			"completion_tokens": completionTokens,
+			"total_tokens":      promptTokens + completionTokens,
+		},
+	}
+}
+
+func writeOpenAIError(w http.ResponseWriter, status int, typ, msg, param, code string) {
+	e := &openAIError{Message: msg, Type: typ}
+	if param != "" {
+		e.Param = &param
+	}
+	if code != "" {
+		e.Code = &code
+	}
+	writeJSON(w, status, map[string]interface{}{"error": e})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	// the contents are parsed as they are by the contracts
+	enc.SetEscapeHTML(false)
+	enc.Encode(value)
+}
+
+// runOpenAI runs the 'openai' command. It serves the scripted completions with a
+// mock of the OpenAI chat completions API until the process is stopped.
+//
+//	forge-backend openai --script openai.json [--listen 127.0.0.1:8547]
+func runOpenAI(args []string) error {
+	var scriptPath, listenAddr string
+
+	fs := flag.NewFlagSet("openai", flag.ContinueOnError)
+	fs.StringVar(&scriptPath, "script", "", "path to the script with the completions")
+	fs.StringVar(&listenAddr, "listen", "127.0.0.1:8547", "address of the server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if scriptPath == "" {
+		return fmt.Errorf("the --script flag is required")
+	}
+
+	script, err := loadOpenAIScript(scriptPath)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Serving the script %s on http://%s/v1/chat/completions", scriptPath, listenAddr)
+	return http.ListenAndServe(listenAddr, &openAIServer{script: script})
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/flashbots/suave-std/tools/suavelib"
+)
+
+// runRandomBytes returns deterministic bytes derived from the seed of the session
+// or secure random bytes if there is no session.
+func runRandomBytes(b *backend, input []byte) ([]byte, error) {
+	numBytes, err := suavelib.UnpackRandomBytesInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	if b.session != nil {
+		return suavelib.PackRandomBytesOutputs(b.session.randomBytes(int(numBytes)))
+	}
+	value := make([]byte, numBytes)
+	if _, err := rand.Read(value); err != nil {
+		return nil, err
+	}
+	return suavelib.PackRandomBytesOutputs(value)
+}
+
+// runAesEncrypt encrypts the message with AES-GCM. The random nonce
+// is prepended to the ciphertext as in suave-geth.
+func runAesEncrypt(b *backend, input []byte) ([]byte, error) {
+	key, message, err := suavelib.UnpackAesEncryptInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	aesgcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aesgcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return suavelib.PackAesEncryptOutputs(aesgcm.Seal(nonce, nonce, message, nil))
+}
+
+func runAesDecrypt(b *backend, input []byte) ([]byte, error) {
+	key, ciphertext, err := suavelib.UnpackAesDecryptInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	aesgcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aesgcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext is shorter than the nonce")
+	}
+	nonce, ciphertext := ciphertext[:aesgcm.NonceSize()], ciphertext[aesgcm.NonceSize():]
+	message, err := aesgcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	return suavelib.PackAesDecryptOutputs(message)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func runSignMessage(b *backend, input []byte) ([]byte, error) {
+	digest, cryptoType, signingKey, err := suavelib.UnpackSignMessageInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	if cryptoType != suavelib.CryptoSignatureSECP256 {
+		return nil, fmt.Errorf("signature type %d is not supported", cryptoType)
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(signingKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %v", err)
+	}
+	signature, err := crypto.Sign(digest, key)
+	if err != nil {
+		return nil, err
+	}
+	return suavelib.PackSignMessageOutputs(signature)
+}
+
+func runSignEthTransaction(b *backend, input []byte) ([]byte, error) {
+	txn, chainIdStr, signingKey, err := suavelib.UnpackSignEthTransactionInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(signingKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %v", err)
+	}
+	chainId, err := parseChainId(chainIdStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(txn); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %v", err)
+	}
+	signedTx, err := types.SignTx(&tx, types.LatestSignerForChainID(chainId), key)
+	if err != nil {
+		return nil, err
+	}
+	signedTxn, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return suavelib.PackSignEthTransactionOutputs(signedTxn)
+}
+
+// parseChainId parses a chain id in hex (with the 0x prefix) or in decimal.
+func parseChainId(str string) (*big.Int, error) {
+	if strings.HasPrefix(str, "0x") {
+		chainId, err := hexutil.DecodeBig(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chain id '%s': %v", str, err)
+		}
+		return chainId, nil
+	}
+	chainId, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid chain id '%s'", str)
+	}
+	return chainId, nil
+}
+
+func runPrivateKeyGen(b *backend, input []byte) ([]byte, error) {
+	cryptoType, err := suavelib.UnpackPrivateKeyGenInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	if cryptoType != suavelib.CryptoSignatureSECP256 {
+		return nil, fmt.Errorf("signature type %d is not supported", cryptoType)
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return suavelib.PackPrivateKeyGenOutputs(hex.EncodeToString(crypto.FromECDSA(key)))
+}
+
+// runGetInsecureTime returns the Unix time in milliseconds of the session clock.
+func runGetInsecureTime(b *backend, input []byte) ([]byte, error) {
+	if err := suavelib.UnpackGetInsecureTimeInputs(input); err != nil {
+		return nil, err
+	}
+	return suavelib.PackGetInsecureTimeOutputs(new(big.Int).SetUint64(b.insecureTime()))
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// flashbotsSignatureHeader is the header with the signature of the body of the request.
+const flashbotsSignatureHeader = "X-Flashbots-Signature"
+
+// mevShareHints are the hints of the privacy settings of a mev_sendBundle bundle.
+var mevShareHints = []string{"calldata", "contract_address", "logs", "function_selector", "hash", "tx_hash", "default_logs"}
+
+// relay is a mock Flashbots relay. It validates the eth_sendBundle and mev_sendBundle
+// requests and keeps a log of the received bundles.
+type relay struct {
+	// allowUnsigned accepts the requests without the X-Flashbots-Signature header
+	allowUnsigned bool
+	// logPath is the file where the bundles are appended as JSON lines
+	logPath string
+
+	lock    sync.Mutex
+	bundles []*relayBundle
+}
+
+// relayBundle is a bundle received by the relay.
+type relayBundle struct {
+	Method string `json:"method"`
+	// Signer is the address that signed the request with the X-Flashbots-Signature header
+	Signer *common.Address `json:"signer,omitempty"`
+	// BundleHash is the hash returned to the sender, it is empty if the bundle was rejected
+	BundleHash *common.Hash    `json:"bundleHash,omitempty"`
+	Block      hexutil.Uint64  `json:"block,omitempty"`
+	Txs        []*relayTx      `json:"txs,omitempty"`
+	Params     json.RawMessage `json:"params"`
+	// Error is the reason why the bundle was rejected
+	Error string `json:"error,omitempty"`
+}
+
+// relayTx is a transaction of a bundle.
+type relayTx struct {
+	Hash common.Hash `json:"hash"`
+	// From, To and Nonce are only set for the signed transactions and not for
+	// the mev_sendBundle bodies that refer to a transaction by its hash
+	From      *common.Address `json:"from,omitempty"`
+	To        *common.Address `json:"to,omitempty"`
+	Nonce     *hexutil.Uint64 `json:"nonce,omitempty"`
+	CanRevert bool            `json:"canRevert,omitempty"`
+}
+
+// ethBundleParams are the params of eth_sendBundle.
+type ethBundleParams struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	BlockNumber       *hexutil.Uint64 `json:"blockNumber"`
+	MinTimestamp      *uint64         `json:"minTimestamp"`
+	MaxTimestamp      *uint64         `json:"maxTimestamp"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"`
+	ReplacementUuid   string          `json:"replacementUuid"`
+	Builders          []string        `json:"builders"`
+}
+
+// mevBundleParams are the params of mev_sendBundle (v0.1).
+type mevBundleParams struct {
+	Version   string `json:"version"`
+	Inclusion *struct {
+		Block    *hexutil.Uint64 `json:"block"`
+		MaxBlock *hexutil.Uint64 `json:"maxBlock"`
+	} `json:"inclusion"`
+	Body     []*mevBundleBody `json:"body"`
+	Validity *struct {
+		Refund []struct {
+			BodyIdx int `json:"bodyIdx"`
+			Percent int `json:"percent"`
+		} `json:"refund"`
+		RefundConfig []struct {
+			Address common.Address `json:"address"`
+			Percent int            `json:"percent"`
+		} `json:"refundConfig"`
+	} `json:"validity"`
+	Privacy *struct {
+		Hints    []string `json:"hints"`
+		Builders []string `json:"builders"`
+	} `json:"privacy"`
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+type mevBundleBody struct {
+	Tx        *hexutil.Bytes  `json:"tx"`
+	Hash      *common.Hash    `json:"hash"`
+	Bundle    json.RawMessage `json:"bundle"`
+	CanRevert bool            `json:"canRevert"`
+}
+
+type signerKey struct{}
+
+// verifySignature checks the X-Flashbots-Signature header of the request and
+// passes the address of the signer to the JSON-RPC handlers.
+func (r *relay) verifySignature(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		header := req.Header.Get(flashbotsSignatureHeader)
+		if header == "" {
+			if !r.allowUnsigned {
+				writeRPCError(w, http.StatusUnauthorized, "missing "+flashbotsSignatureHeader+" header")
+				return
+			}
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		signer, err := recoverFlashbotsSigner(header, body)
+		if err != nil {
+			writeRPCError(w, http.StatusForbidden, fmt.Sprintf("invalid %s header: %v", flashbotsSignatureHeader, err))
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), signerKey{}, signer)))
+	})
+}
+
+// recoverFlashbotsSigner checks that the header '<address>:<signature>' is the
+// signature by the address of the hex encoded hash of the body, signed as a text message.
+func recoverFlashbotsSigner(header string, body []byte) (common.Address, error) {
+	addrHex, sigHex, found := strings.Cut(header, ":")
+	if !found {
+		return common.Address{}, fmt.Errorf("expected '<address>:<signature>'")
+	}
+	if !common.IsHexAddress(addrHex) {
+		return common.Address{}, fmt.Errorf("invalid address '%s'", addrHex)
+	}
+	sig, err := hexutil.Decode(sigHex)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature: %v", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length %d, expected %d", len(sig), crypto.SignatureLength)
+	}
+	// the recovery id is either 0/1 or 27/28
+	sig = slices.Clone(sig)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	hash := accounts.TextHash([]byte(crypto.Keccak256Hash(body).Hex()))
+	pubKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover the signer: %v", err)
+	}
+	signer := crypto.PubkeyToAddress(*pubKey)
+	if signer != common.HexToAddress(addrHex) {
+		return common.Address{}, fmt.Errorf("signed by %s and not by %s", signer.Hex(), common.HexToAddress(addrHex).Hex())
+	}
+	return signer, nil
+}
+
+func writeRPCError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse(nil, &rpcError{Code: rpcInvalidRequest, Message: msg}))
+}
+
+func (r *relay) handle(req *http.Request, method string, params json.RawMessage) (interface{}, error) {
+	var bundle *relayBundle
+	var err error
+
+	switch method {
+	case "eth_sendBundle":
+		bundle, err = validateEthBundle(params)
+	case "mev_sendBundle":
+		bundle, err = validateMevBundle(params)
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("the method %s does not exist/is not available", method)}
+	}
+
+	if bundle == nil {
+		bundle = &relayBundle{}
+	}
+	bundle.Method = method
+	bundle.Params = params
+	if signer, ok := req.Context().Value(signerKey{}).(common.Address); ok {
+		bundle.Signer = &signer
+	}
+	if err != nil {
+		bundle.Error = err.Error()
+	} else {
+		hash := bundleHash(bundle.Txs)
+		bundle.BundleHash = &hash
+	}
+	if logErr := r.record(bundle); logErr != nil {
+		return nil, logErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"bundleHash": bundle.BundleHash}, nil
+}
+
+func validateEthBundle(params json.RawMessage) (*relayBundle, error) {
+	var args ethBundleParams
+	if err := parsePositionalParams(params, 1, &args); err != nil {
+		return nil, err
+	}
+	if len(args.Txs) == 0 {
+		return nil, invalidParams("bundle missing txs")
+	}
+	if args.BlockNumber == nil {
+		return nil, invalidParams("bundle missing blockNumber")
+	}
+	if args.MinTimestamp != nil && args.MaxTimestamp != nil && *args.MinTimestamp > *args.MaxTimestamp {
+		return nil, invalidParams("minTimestamp %d is after maxTimestamp %d", *args.MinTimestamp, *args.MaxTimestamp)
+	}
+
+	bundle := &relayBundle{Block: *args.BlockNumber}
+	for indx, raw := range args.Txs {
+		tx, err := decodeBundleTx(raw)
+		if err != nil {
+			return nil, invalidParams("invalid transaction %d: %v", indx, err)
+		}
+		tx.CanRevert = slices.Contains(args.RevertingTxHashes, tx.Hash)
+		bundle.Txs = append(bundle.Txs, tx)
+	}
+	return bundle, nil
+}
+
+func validateMevBundle(params json.RawMessage) (*relayBundle, error) {
+	var args mevBundleParams
+	if err := parsePositionalParams(params, 1, &args); err != nil {
+		return nil, err
+	}
+	if args.Version != "v0.1" {
+		return nil, invalidParams("unsupported version '%s', expected 'v0.1'", args.Version)
+	}
+	if args.Inclusion == nil || args.Inclusion.Block == nil {
+		return nil, invalidParams("bundle missing inclusion.block")
+	}
+	if args.Inclusion.MaxBlock != nil && *args.Inclusion.MaxBlock < *args.Inclusion.Block {
+		return nil, invalidParams("inclusion.maxBlock %d is before inclusion.block %d", *args.Inclusion.MaxBlock, *args.Inclusion.Block)
+	}
+	if len(args.Body) == 0 {
+		return nil, invalidParams("bundle missing body")
+	}
+
+	bundle := &relayBundle{Block: *args.Inclusion.Block}
+	for indx, body := range args.Body {
+		var tx *relayTx
+		switch {
+		case body.Tx != nil && body.Hash == nil && body.Bundle == nil:
+			var err error
+			if tx, err = decodeBundleTx(*body.Tx); err != nil {
+				return nil, invalidParams("invalid transaction in body %d: %v", indx, err)
+			}
+		case body.Hash != nil && body.Tx == nil && body.Bundle == nil:
+			tx = &relayTx{Hash: *body.Hash}
+		case body.Bundle != nil && body.Tx == nil && body.Hash == nil:
+			return nil, invalidParams("body %d: nested bundles are not supported", indx)
+		default:
+			return nil, invalidParams("body %d must have exactly one of tx, hash or bundle", indx)
+		}
+		tx.CanRevert = body.CanRevert
+		bundle.Txs = append(bundle.Txs, tx)
+	}
+
+	if args.Validity != nil {
+		total := 0
+		for _, refund := range args.Validity.Refund {
+			if refund.BodyIdx < 0 || refund.BodyIdx >= len(args.Body) {
+				return nil, invalidParams("refund bodyIdx %d out of range, the body has %d elements", refund.BodyIdx, len(args.Body))
+			}
+			if refund.Percent < 0 || refund.Percent > 100 {
+				return nil, invalidParams("refund percent %d out of range [0, 100]", refund.Percent)
+			}
+			total += refund.Percent
+		}
+		if total > 100 {
+			return nil, invalidParams("total refund percent %d is above 100", total)
+		}
+		for _, config := range args.Validity.RefundConfig {
+			if config.Percent < 0 || config.Percent > 100 {
+				return nil, invalidParams("refundConfig percent %d out of range [0, 100]", config.Percent)
+			}
+		}
+	}
+	if args.Privacy != nil {
+		for _, hint := range args.Privacy.Hints {
+			if !slices.Contains(mevShareHints, hint) {
+				return nil, invalidParams("unknown hint '%s', expected one of %s", hint, strings.Join(mevShareHints, ", "))
+			}
+		}
+	}
+	return bundle, nil
+}
+
+// decodeBundleTx decodes a signed transaction (legacy or typed) and recovers its sender.
+func decodeBundleTx(raw []byte) (*relayTx, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode: %v", err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %v", err)
+	}
+	nonce := hexutil.Uint64(tx.Nonce())
+	return &relayTx{
+		Hash:  tx.Hash(),
+		From:  &from,
+		To:    tx.To(),
+		Nonce: &nonce,
+	}, nil
+}
+
+// bundleHash is the hash of the concatenated hashes of the transactions, as in the Flashbots relay.
+func bundleHash(txs []*relayTx) common.Hash {
+	hashes := []byte{}
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash.Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+func (r *relay) record(bundle *relayBundle) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.bundles = append(r.bundles, bundle)
+	if r.logPath == "" {
+		return nil
+	}
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// serveBundles serves the log of bundles. GET returns the bundles that match the
+// 'method', 'signer', 'block' and 'accepted' query parameters, and DELETE clears the log.
+func (r *relay) serveBundles(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	switch req.Method {
+	case http.MethodGet:
+		query := req.URL.Query()
+		bundles := []*relayBundle{}
+		for _, bundle := range r.bundles {
+			if method := query.Get("method"); method != "" && bundle.Method != method {
+				continue
+			}
+			if signer := query.Get("signer"); signer != "" && (bundle.Signer == nil || *bundle.Signer != common.HexToAddress(signer)) {
+				continue
+			}
+			if block := query.Get("block"); block != "" && bundle.Block.String() != block {
+				continue
+			}
+			if accepted := query.Get("accepted"); accepted != "" && (bundle.Error == "") != (accepted == "true") {
+				continue
+			}
+			bundles = append(bundles, bundle)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(bundles)
+	case http.MethodDelete:
+		r.bundles = nil
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "expected GET or DELETE", http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *relay) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bundles", r.serveBundles)
+	mux.Handle("/", r.verifySignature(jsonRPCServer(r.handle)))
+	return mux
+}
+
+// runRelay runs the 'relay' command. It serves a mock Flashbots relay until the
+// process is stopped.
+//
+//	forge-backend relay [--listen 127.0.0.1:8546] [--log bundles.jsonl] [--allow-unsigned]
+func runRelay(args []string) error {
+	r := &relay{}
+	var listenAddr string
+
+	fs := flag.NewFlagSet("relay", flag.ContinueOnError)
+	fs.StringVar(&listenAddr, "listen", "127.0.0.1:8546", "address of the server")
+	fs.StringVar(&r.logPath, "log", "", "file where the received bundles are appended as JSON lines")
+	fs.BoolVar(&r.allowUnsigned, "allow-unsigned", false, "accept the requests without the "+flashbotsSignatureHeader+" header")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	log.Printf("Serving the relay on http://%s", listenAddr)
+	return http.ListenAndServe(listenAddr, r.handler())
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// stateDirEnv is the environment variable that overrides the directory of the session files.
+const stateDirEnv = "SUAVE_FORGE_STATE_DIR"
+
+// sessionTTL is the time after which the files of old sessions are removed.
+const sessionTTL = 24 * time.Hour
+
+// session is the state of the backend during a forge test. The Connector creates
+// a session (with the 'session' command) the first time that a test calls a precompile
+// and passes its id to every following call, so that each test has its own state.
+type session struct {
+	ID common.Hash `json:"id"`
+	// Seed is the seed of the randomBytes precompile
+	Seed common.Hash `json:"seed"`
+	// RandomCounter is the number of randomBytes calls in the session
+	RandomCounter uint64 `json:"randomCounter"`
+	// Clock is the clock of getInsecureTime, it is nil until the test updates it
+	Clock *clock `json:"clock,omitempty"`
+	// Builders are the blocks started with newBuilder, by id
+	Builders map[string]*savedBuilder `json:"builders,omitempty"`
+	// HTTPCounters are the number of http requests of the session, by the hash of the
+	// fields matched with the cassette
+	HTTPCounters map[string]int `json:"httpCounters,omitempty"`
+
+	path string
+}
+
+// stateDir returns the directory of the session files. By default, it is
+// 'cache/forge-backend' next to the foundry.toml file.
+func stateDir(configPath string) string {
+	if dir := os.Getenv(stateDirEnv); dir != "" {
+		return dir
+	}
+	root := "."
+	if configPath != "" {
+		root = filepath.Dir(configPath)
+	}
+	return filepath.Join(root, "cache", "forge-backend")
+}
+
+// newSession creates a session with a random id. The seed is the one in the
+// configuration or a random one if it is not set.
+func newSession(dir string, cfg *config) (*session, error) {
+	s := &session{}
+	if _, err := rand.Read(s.ID[:]); err != nil {
+		return nil, err
+	}
+	if cfg.RandomSeed != "" {
+		seed, err := parseSeed(cfg.RandomSeed)
+		if err != nil {
+			return nil, err
+		}
+		s.Seed = seed
+	} else if _, err := rand.Read(s.Seed[:]); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	removeOldSessions(dir)
+
+	s.path = filepath.Join(dir, s.ID.Hex()+".json")
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// loadSession loads the session with the id in hex.
+func loadSession(dir string, idHex string) (*session, error) {
+	idBytes, err := hexutil.Decode(idHex)
+	if err != nil || len(idBytes) != common.HashLength {
+		return nil, fmt.Errorf("invalid session id '%s'", idHex)
+	}
+	id := common.BytesToHash(idBytes)
+
+	path := filepath.Join(dir, id.Hex()+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("session %s not found in %s", id.Hex(), dir)
+		}
+		return nil, err
+	}
+	s := &session{path: path}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to decode session %s: %v", id.Hex(), err)
+	}
+	return s, nil
+}
+
+func (s *session) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// randomBytes returns the next n random bytes of the session. The bytes only
+// depend on the seed and on the number of previous calls in the session.
+func (s *session) randomBytes(n int) []byte {
+	s.RandomCounter++
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], s.RandomCounter)
+
+	value := []byte{}
+	for block := uint32(0); len(value) < n; block++ {
+		var blockNum [4]byte
+		binary.BigEndian.PutUint32(blockNum[:], block)
+		value = append(value, crypto.Keccak256(s.Seed[:], counter[:], blockNum[:])...)
+	}
+	return value[:n]
+}
+
+// removeOldSessions removes the session files older than sessionTTL.
+func removeOldSessions(dir string) {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > sessionTTL {
+			os.Remove(path)
+		}
+	}
+}
+
+var sessionOutputs = abi.Arguments{
+	{Name: "id", Type: mustType("bytes32")},
+	{Name: "seed", Type: mustType("bytes32")},
+}
+
+// runSession runs the 'session' command. It creates a session and returns
+// its id and seed abi encoded as (bytes32, bytes32).
+func runSession(args []string) ([]byte, error) {
+	var configPath string
+
+	fs := flag.NewFlagSet("session", flag.ContinueOnError)
+	fs.StringVar(&configPath, "config", "", "path to the foundry.toml file")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSession(stateDir(configPath), cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sessionOutputs.Pack(s.ID, s.Seed)
+}
+
+func mustType(typ string) abi.Type {
+	t, err := abi.NewType(typ, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/flashbots/suave-std/tools/suavelib"
+)
+
+// defaultBlockGasLimit is the gas limit of the simulated block if the state file does not set it.
+const defaultBlockGasLimit = 30_000_000
+
+// stateFile is the chain of the simulation precompiles. It can be a geth genesis,
+// a geth state dump ('geth dump') or a fixture of the mock servers, the keys of
+// the three formats are accepted and the unknown keys are ignored.
+type stateFile struct {
+	// Config is the chain config of a genesis
+	Config *struct {
+		ChainID *fileUint64 `json:"chainId"`
+	} `json:"config"`
+	ChainID *fileUint64 `json:"chainId"`
+
+	// Number (genesis) or BlockNumber (fixture) is the head block, the
+	// transactions are simulated in the next block
+	Number      fileUint64     `json:"number"`
+	BlockNumber fileUint64     `json:"blockNumber"`
+	Timestamp   fileUint64     `json:"timestamp"`
+	GasLimit    fileUint64     `json:"gasLimit"`
+	Coinbase    common.Address `json:"coinbase"`
+
+	// Alloc are the accounts of a genesis
+	Alloc map[string]*fileAccount `json:"alloc"`
+	// Accounts are the accounts of a state dump or a fixture
+	Accounts map[string]*fileAccount `json:"accounts"`
+}
+
+// fileUint64 is a number of a state file. It can be a JSON number or a string
+// in hex or decimal, as in the genesis files and the state dumps of geth.
+type fileUint64 uint64
+
+func (n *fileUint64) UnmarshalJSON(data []byte) error {
+	var num uint64
+	if err := json.Unmarshal(data, &num); err == nil {
+		*n = fileUint64(num)
+		return nil
+	}
+	var str math.HexOrDecimal64
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	*n = fileUint64(str)
+	return nil
+}
+
+// fileAccount is an account of a state file. The balance is in hex or decimal and
+// the storage values can be shorter than 32 bytes, as in the state dumps.
+type fileAccount struct {
+	Balance *math.HexOrDecimal256  `json:"balance"`
+	Nonce   fileUint64             `json:"nonce"`
+	Code    hexutil.Bytes          `json:"code"`
+	Storage map[common.Hash]string `json:"storage"`
+}
+
+// chainState is the state and the block in which the transactions are simulated.
+type chainState struct {
+	env   *blockEnv
+	state *memState
+}
+
+func loadChainState(path string) (*chainState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &stateFile{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to decode the state file %s: %v", path, err)
+	}
+	if f.Alloc != nil && f.Accounts != nil {
+		return nil, fmt.Errorf("state file %s has both 'alloc' and 'accounts'", path)
+	}
+
+	env := &blockEnv{
+		ChainID:  1,
+		Number:   uint64(max(f.Number, f.BlockNumber)) + 1,
+		Time:     uint64(f.Timestamp) + 12,
+		GasLimit: uint64(f.GasLimit),
+		Coinbase: f.Coinbase,
+	}
+	if f.Config != nil && f.Config.ChainID != nil {
+		env.ChainID = uint64(*f.Config.ChainID)
+	} else if f.ChainID != nil {
+		env.ChainID = uint64(*f.ChainID)
+	}
+	if env.GasLimit == 0 {
+		env.GasLimit = defaultBlockGasLimit
+	}
+
+	state := newMemState()
+	accounts := f.Alloc
+	if accounts == nil {
+		accounts = f.Accounts
+	}
+	for key, account := range accounts {
+		if !common.IsHexAddress(key) {
+			return nil, fmt.Errorf("state file %s: invalid address '%s'", path, key)
+		}
+		addr := common.HexToAddress(key)
+		acc := &stateAccount{
+			Nonce:   hexutil.Uint64(account.Nonce),
+			Code:    account.Code,
+			Storage: map[common.Hash]common.Hash{},
+		}
+		if account.Balance != nil {
+			acc.Balance = (*hexutil.Big)(account.Balance)
+		}
+		for slot, value := range account.Storage {
+			if acc.Storage[slot], err = parseStorageValue(value); err != nil {
+				return nil, fmt.Errorf("state file %s: account %s: %v", path, addr.Hex(), err)
+			}
+		}
+		state.setAccount(addr, acc)
+	}
+	return &chainState{env: env, state: state}, nil
+}
+
+// parseStorageValue parses a storage value in hex, with or without the 0x prefix
+// and the leading zeros.
+func parseStorageValue(value string) (common.Hash, error) {
+	digits := strings.TrimPrefix(value, "0x")
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+	b, err := hex.DecodeString(digits)
+	if err != nil || len(b) > common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid storage value '%s'", value)
+	}
+	return common.BytesToHash(b), nil
+}
+
+// applyTransaction applies the transaction to the state if it fits in the gas
+// left in the block, gasUsed is the gas used by the previous transactions.
+func (c *chainState) applyTransaction(tx *types.Transaction, gasUsed uint64) (*txResult, error) {
+	if gasUsed+tx.Gas() > c.env.GasLimit {
+		return nil, fmt.Errorf("gas limit reached: tx gas %d, gas left in the block %d", tx.Gas(), c.env.GasLimit-gasUsed)
+	}
+	return c.state.applyTransaction(c.env, tx)
+}
+
+// executionError returns the error of a transaction that failed, with the
+// revert reason if there is one.
+func executionError(result *txResult) error {
+	if result.Err == nil {
+		return nil
+	}
+	if reason, err := abi.UnpackRevert(result.ReturnData); err == nil {
+		return fmt.Errorf("execution reverted: %s", reason)
+	}
+	return fmt.Errorf("execution failed: %v", result.Err)
+}
+
+// savedBuilder is a block being built with newBuilder, it is saved in the session
+// so that the following simulateTransaction calls of the test build on it.
+type savedBuilder struct {
+	// Accounts are the state after the transactions of the block, they are
+	// not set until a transaction is added
+	Accounts map[common.Address]*stateAccount `json:"accounts,omitempty"`
+	Txs      []common.Hash                    `json:"txs"`
+	GasUsed  hexutil.Uint64                   `json:"gasUsed"`
+}
+
+// chainState loads the state file of the configuration.
+func (b *backend) chainState() (*chainState, error) {
+	return loadChainState(b.resolvePath(b.config.State))
+}
+
+// runNewBuilder starts a block on top of the state file. The id of the builder
+// is derived from the seed of the session, so it is reproducible like randomBytes.
+func runNewBuilder(b *backend, input []byte) ([]byte, error) {
+	if err := suavelib.UnpackNewBuilderInputs(input); err != nil {
+		return nil, err
+	}
+	if b.config.State == "" {
+		return b.delegate(suavelib.NewBuilderAddr)
+	}
+	if b.session == nil {
+		return nil, fmt.Errorf("the builders are kept in the session of the test but the Connector does not use sessions")
+	}
+	// check the state file before the builder is used
+	if _, err := b.chainState(); err != nil {
+		return nil, err
+	}
+
+	if b.session.Builders == nil {
+		b.session.Builders = map[string]*savedBuilder{}
+	}
+	var index [8]byte
+	binary.BigEndian.PutUint64(index[:], uint64(len(b.session.Builders)))
+	id := uuidString(crypto.Keccak256(b.session.Seed[:], index[:])[:16])
+
+	b.session.Builders[id] = &savedBuilder{Txs: []common.Hash{}}
+	return suavelib.PackNewBuilderOutputs(id)
+}
+
+// runSimulateTransaction adds the transaction to the block of the builder. A transaction
+// that is not valid or fails is not added and its result has the error.
+func runSimulateTransaction(b *backend, input []byte) ([]byte, error) {
+	sessionid, txn, err := suavelib.UnpackSimulateTransactionInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	if b.config.State == "" {
+		return b.delegate(suavelib.SimulateTransactionAddr)
+	}
+	var builder *savedBuilder
+	if b.session != nil {
+		builder = b.session.Builders[sessionid]
+	}
+	if builder == nil {
+		return nil, fmt.Errorf("builder session '%s' not found", sessionid)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(txn); err != nil {
+		return nil, fmt.Errorf("invalid transaction: %v", err)
+	}
+
+	chain, err := b.chainState()
+	if err != nil {
+		return nil, err
+	}
+	if builder.Accounts != nil {
+		chain.state = newMemState()
+		for addr, account := range builder.Accounts {
+			chain.state.setAccount(addr, account)
+		}
+	}
+
+	egp := tx.EffectiveGasTipValue(new(big.Int))
+	if !egp.IsUint64() {
+		return nil, fmt.Errorf("effective gas price %v overflows uint64", egp)
+	}
+	simResult := suavelib.SimulateTransactionResult{
+		Egp:  egp.Uint64(),
+		Logs: []suavelib.SimulatedLog{},
+	}
+	result, err := chain.applyTransaction(tx, uint64(builder.GasUsed))
+	if err == nil {
+		err = executionError(result)
+	}
+	if err != nil {
+		// the state of the builder is not saved
+		simResult.Error = err.Error()
+		return suavelib.PackSimulateTransactionOutputs(simResult)
+	}
+
+	builder.Accounts = chain.state.dump()
+	builder.Txs = append(builder.Txs, tx.Hash())
+	builder.GasUsed += hexutil.Uint64(result.GasUsed)
+
+	simResult.Success = true
+	for _, log := range result.Logs {
+		topics := make([][32]byte, len(log.Topics))
+		for indx, topic := range log.Topics {
+			topics[indx] = topic
+		}
+		simResult.Logs = append(simResult.Logs, suavelib.SimulatedLog{Data: log.Data, Addr: log.Address, Topics: topics})
+	}
+	return suavelib.PackSimulateTransactionOutputs(simResult)
+}
+
+// simulatedBundle is the bundle of simulateBundle. The transactions are RLP encoded
+// in hex or JSON objects, the other fields of the bundle are ignored.
+type simulatedBundle struct {
+	Txs []*bundleTx `json:"txs"`
+	// RevertingHashes are the transactions that are allowed to fail
+	RevertingHashes []common.Hash `json:"revertingHashes"`
+}
+
+type bundleTx struct {
+	*types.Transaction
+}
+
+func (tx *bundleTx) UnmarshalJSON(data []byte) error {
+	tx.Transaction = new(types.Transaction)
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return tx.Transaction.UnmarshalJSON(data)
+	}
+	raw, err := hexutil.Decode(str)
+	if err != nil {
+		return fmt.Errorf("invalid transaction '%s': %v", str, err)
+	}
+	return tx.UnmarshalBinary(raw)
+}
+
+// runSimulateBundle runs the transactions of the bundle on top of the state file
+// and returns the effective gas price of the bundle: the payment to the coinbase
+// (fees and direct transfers) divided by the gas used.
+func runSimulateBundle(b *backend, input []byte) ([]byte, error) {
+	bundleData, err := suavelib.UnpackSimulateBundleInputs(input)
+	if err != nil {
+		return nil, err
+	}
+	if b.config.State == "" {
+		return b.delegate(suavelib.SimulateBundleAddr)
+	}
+	var bundle simulatedBundle
+	if err := json.Unmarshal(bundleData, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %v", err)
+	}
+	if len(bundle.Txs) == 0 {
+		return nil, fmt.Errorf("the bundle has no transactions")
+	}
+
+	chain, err := b.chainState()
+	if err != nil {
+		return nil, err
+	}
+	balanceBefore := chain.state.GetBalance(chain.env.Coinbase).ToBig()
+
+	var gasUsed uint64
+	for indx, tx := range bundle.Txs {
+		result, err := chain.applyTransaction(tx.Transaction, gasUsed)
+		if err != nil {
+			return nil, fmt.Errorf("tx %d (%s): %v", indx, tx.Hash().Hex(), err)
+		}
+		if err := executionError(result); err != nil && !slices.Contains(bundle.RevertingHashes, tx.Hash()) {
+			return nil, fmt.Errorf("tx %d (%s): %v", indx, tx.Hash().Hex(), err)
+		}
+		gasUsed += result.GasUsed
+	}
+
+	profit := new(big.Int).Sub(chain.state.GetBalance(chain.env.Coinbase).ToBig(), balanceBefore)
+	egp := new(big.Int).Div(profit, new(big.Int).SetUint64(gasUsed))
+	if !egp.IsUint64() {
+		return nil, fmt.Errorf("effective gas price %v overflows uint64", egp)
+	}
+	return suavelib.PackSimulateBundleOutputs(egp.Uint64())
+}
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// suavexFixture is the chain and the scripted outcomes of the suavex emulator.
+type suavexFixture struct {
+	ChainID hexutil.Uint64 `json:"chainId"`
+	// BlockNumber is the number of the head block, the sessions build on top of it
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	// GasLimit is the gas limit of the blocks of the sessions. Like in suave-geth, it is
+	// set by the node and not by the gasLimit of the args of the session.
+	GasLimit hexutil.Uint64                   `json:"gasLimit"`
+	Accounts map[common.Address]*stateAccount `json:"accounts"`
+	// Outcomes are the results of the transactions returned without running them
+	Outcomes []*scriptedOutcome `json:"outcomes"`
+}
+
+// scriptedOutcome is the result of the transactions that match all the set fields.
+type scriptedOutcome struct {
+	Match struct {
+		Hash     *common.Hash    `json:"hash"`
+		From     *common.Address `json:"from"`
+		To       *common.Address `json:"to"`
+		Nonce    *hexutil.Uint64 `json:"nonce"`
+		Selector *hexutil.Bytes  `json:"selector"`
+	} `json:"match"`
+	Success bool            `json:"success"`
+	Error   string          `json:"error,omitempty"`
+	Logs    []*simulatedLog `json:"logs,omitempty"`
+	GasUsed hexutil.Uint64  `json:"gasUsed,omitempty"`
+}
+
+func (o *scriptedOutcome) matches(tx *types.Transaction, from common.Address) bool {
+	m := o.Match
+	switch {
+	case m.Hash != nil && *m.Hash != tx.Hash():
+		return false
+	case m.From != nil && *m.From != from:
+		return false
+	case m.To != nil && (tx.To() == nil || *m.To != *tx.To()):
+		return false
+	case m.Nonce != nil && uint64(*m.Nonce) != tx.Nonce():
+		return false
+	case m.Selector != nil && !bytes.HasPrefix(tx.Data(), *m.Selector):
+		return false
+	}
+	return true
+}
+
+// simulateTransactionResult is the result of suavex_addTransaction, with the
+// shape of Types.SimulateTransactionResult.
+type simulateTransactionResult struct {
+	Egp     hexutil.Uint64  `json:"egp"`
+	Logs    []*simulatedLog `json:"logs"`
+	Success bool            `json:"success"`
+	Error   string          `json:"error"`
+}
+
+type simulatedLog struct {
+	Data   hexutil.Bytes  `json:"data"`
+	Addr   common.Address `json:"addr"`
+	Topics []common.Hash  `json:"topics"`
+}
+
+// looseUint64 is a quantity in hex that can have leading zeros, as encoded
+// with LibString.toHexString.
+type looseUint64 uint64
+
+func (q *looseUint64) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("quantity must be a hex string")
+	}
+	digits, found := strings.CutPrefix(str, "0x")
+	if !found || digits == "" {
+		return fmt.Errorf("invalid quantity '%s', expected hex with the 0x prefix", str)
+	}
+	value, err := strconv.ParseUint(digits, 16, 64)
+	if err != nil {
+		return fmt.Errorf("invalid quantity '%s': %v", str, err)
+	}
+	*q = looseUint64(value)
+	return nil
+}
+
+// buildBlockArgs are the params of suavex_newSession, as encoded by Types.encodeBuildBlockArgs.
+type buildBlockArgs struct {
+	Slot           looseUint64    `json:"slot"`
+	ProposerPubkey hexutil.Bytes  `json:"proposerPubkey"`
+	Parent         common.Hash    `json:"parent"`
+	Timestamp      looseUint64    `json:"timestamp"`
+	FeeRecipient   common.Address `json:"feeRecipient"`
+	GasLimit       looseUint64    `json:"gasLimit"`
+	Random         common.Hash    `json:"random"`
+	Withdrawals    []struct {
+		Index     looseUint64    `json:"index"`
+		Validator looseUint64    `json:"validator"`
+		Address   common.Address `json:"Address"`
+		Amount    looseUint64    `json:"amount"`
+	} `json:"withdrawals"`
+	Extra       hexutil.Bytes `json:"extra"`
+	BeaconRoot  common.Hash   `json:"beaconRoot"`
+	FillPending bool          `json:"fillPending"`
+}
+
+// builderSession is a block being built by suavex_newSession.
+type builderSession struct {
+	args   *buildBlockArgs
+	env    *blockEnv
+	parent common.Hash
+	state  *memState
+
+	txs     types.Transactions
+	gasUsed uint64
+	// block is set by suavex_buildBlock
+	block *types.Header
+}
+
+// suavexServer emulates the suavex JSON-RPC namespace of suave-geth.
+type suavexServer struct {
+	fixture *suavexFixture
+	genesis *memState
+
+	lock     sync.Mutex
+	sessions map[string]*builderSession
+}
+
+func loadSuavexFixture(path string) (*suavexFixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &suavexFixture{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(f); err != nil {
+		return nil, fmt.Errorf("failed to decode the fixture %s: %v", path, err)
+	}
+	if f.ChainID == 0 {
+		f.ChainID = 1
+	}
+	if f.GasLimit == 0 {
+		f.GasLimit = defaultBlockGasLimit
+	}
+	return f, nil
+}
+
+func newSuavexServer(fixture *suavexFixture) *suavexServer {
+	genesis := newMemState()
+	for addr, account := range fixture.Accounts {
+		genesis.setAccount(addr, account)
+	}
+	return &suavexServer{
+		fixture:  fixture,
+		genesis:  genesis,
+		sessions: map[string]*builderSession{},
+	}
+}
+
+// headHash is the hash of the head block, as returned by the BLOCKHASH opcode.
+func (s *suavexServer) headHash() common.Hash {
+	return crypto.Keccak256Hash(new(big.Int).SetUint64(uint64(s.fixture.BlockNumber)).Bytes())
+}
+
+func (s *suavexServer) handle(_ *http.Request, method string, params json.RawMessage) (interface{}, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch method {
+	case "suavex_newSession", "suavex_newBuilder":
+		var args buildBlockArgs
+		if err := parsePositionalParams(params, 1, &args); err != nil {
+			return nil, err
+		}
+		return s.newSession(&args)
+
+	case "suavex_addTransaction":
+		var id string
+		var tx types.Transaction
+		if err := parsePositionalParams(params, 2, &id, &tx); err != nil {
+			return nil, err
+		}
+		session, err := s.session(id)
+		if err != nil {
+			return nil, err
+		}
+		return s.addTransaction(session, &tx)
+
+	case "suavex_buildBlock":
+		var id string
+		if err := parsePositionalParams(params, 1, &id); err != nil {
+			return nil, err
+		}
+		session, err := s.session(id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, session.buildBlock()
+
+	case "suavex_bid":
+		var id string
+		var blsPubKey hexutil.Bytes
+		if err := parsePositionalParams(params, 2, &id, &blsPubKey); err != nil {
+			return nil, err
+		}
+		session, err := s.session(id)
+		if err != nil {
+			return nil, err
+		}
+		return session.bid(blsPubKey)
+
+	case "suavex_call":
+		var id string
+		var args callArgs
+		if err := parsePositionalParams(params, 2, &id, &args); err != nil {
+			return nil, err
+		}
+		session, err := s.session(id)
+		if err != nil {
+			return nil, err
+		}
+		return session.call(&args)
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("the method %s does not exist/is not available", method)}
+}
+
+func (s *suavexServer) newSession(args *buildBlockArgs) (string, error) {
+	if args.Parent != (common.Hash{}) && args.Parent != s.headHash() {
+		return "", invalidParams("unknown parent block %s, the head is %s", args.Parent.Hex(), s.headHash().Hex())
+	}
+
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	idStr := uuidString(id[:])
+
+	s.sessions[idStr] = &builderSession{
+		args: args,
+		env: &blockEnv{
+			ChainID:  uint64(s.fixture.ChainID),
+			Number:   uint64(s.fixture.BlockNumber) + 1,
+			Time:     uint64(args.Timestamp),
+			GasLimit: uint64(s.fixture.GasLimit),
+			Coinbase: args.FeeRecipient,
+		},
+		parent: s.headHash(),
+		state:  s.genesis.copy(),
+	}
+	return idStr, nil
+}
+
+// uuidString formats 16 bytes as an uuid, like the session ids of suave-geth.
+func uuidString(id []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
+
+func (s *suavexServer) session(id string) (*builderSession, error) {
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, &rpcError{Code: rpcServerError, Message: fmt.Sprintf("session '%s' not found", id)}
+	}
+	return session, nil
+}
+
+// addTransaction adds the transaction to the block of the session. A transaction
+// that is not valid or reverts is not added and its result has the error.
+func (s *suavexServer) addTransaction(session *builderSession, tx *types.Transaction) (*simulateTransactionResult, error) {
+	if session.block != nil {
+		return nil, &rpcError{Code: rpcServerError, Message: "the block of the session is already built"}
+	}
+
+	signer := types.LatestSignerForChainID(new(big.Int).SetUint64(session.env.ChainID))
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return &simulateTransactionResult{Logs: []*simulatedLog{}, Error: fmt.Sprintf("invalid sender: %v", err)}, nil
+	}
+	tip := tx.EffectiveGasTipValue(new(big.Int))
+	if !tip.IsUint64() {
+		return nil, fmt.Errorf("effective gas price %v overflows uint64", tip)
+	}
+	egp := hexutil.Uint64(tip.Uint64())
+
+	// the transaction must fit in the gas left in the block, as in chainState.applyTransaction
+	if gasLeft := session.gasLeft(); tx.Gas() > gasLeft {
+		return &simulateTransactionResult{Egp: egp, Logs: []*simulatedLog{}, Error: fmt.Sprintf("gas limit reached: tx gas %d, gas left in the block %d", tx.Gas(), gasLeft)}, nil
+	}
+
+	for _, outcome := range s.fixture.Outcomes {
+		if !outcome.matches(tx, from) {
+			continue
+		}
+		logs := outcome.Logs
+		if logs == nil {
+			logs = []*simulatedLog{}
+		}
+		if outcome.Success {
+			// the transaction is in the block but it does not run
+			session.state.SetNonce(from, tx.Nonce()+1)
+			session.txs = append(session.txs, tx)
+			session.gasUsed += uint64(outcome.GasUsed)
+		}
+		return &simulateTransactionResult{Egp: egp, Logs: logs, Success: outcome.Success, Error: outcome.Error}, nil
+	}
+
+	snapshot := session.state.copy()
+	result, err := session.state.applyTransaction(session.env, tx)
+	if err == nil {
+		err = executionError(result)
+	}
+	if err != nil {
+		session.state = snapshot
+		return &simulateTransactionResult{Egp: egp, Logs: []*simulatedLog{}, Error: err.Error()}, nil
+	}
+
+	session.txs = append(session.txs, tx)
+	session.gasUsed += result.GasUsed
+
+	logs := []*simulatedLog{}
+	for _, log := range result.Logs {
+		logs = append(logs, &simulatedLog{Data: log.Data, Addr: log.Address, Topics: log.Topics})
+	}
+	return &simulateTransactionResult{Egp: egp, Logs: logs, Success: true}, nil
+}
+
+// gasLeft returns the gas left in the block of the session.
+func (session *builderSession) gasLeft() uint64 {
+	if session.gasUsed >= session.env.GasLimit {
+		return 0
+	}
+	return session.env.GasLimit - session.gasUsed
+}
+
+// buildBlock seals the block of the session with its transactions.
+func (session *builderSession) buildBlock() error {
+	if session.block != nil {
+		return &rpcError{Code: rpcServerError, Message: "the block of the session is already built"}
+	}
+	session.block = &types.Header{
+		ParentHash:  session.parent,
+		Coinbase:    session.args.FeeRecipient,
+		Number:      new(big.Int).SetUint64(session.env.Number),
+		GasLimit:    session.env.GasLimit,
+		GasUsed:     session.gasUsed,
+		Time:        uint64(session.args.Timestamp),
+		Extra:       session.args.Extra,
+		MixDigest:   session.args.Random,
+		BaseFee:     new(big.Int),
+		TxHash:      txsHash(session.txs),
+		UncleHash:   types.EmptyUncleHash,
+		ReceiptHash: types.EmptyReceiptsHash,
+		Difficulty:  new(big.Int),
+	}
+	return nil
+}
+
+// txsHash is the hash of the concatenated hashes of the transactions. The emulator
+// does not compute the trie root since the trie package needs a database.
+func txsHash(txs types.Transactions) common.Hash {
+	hashes := []byte{}
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// builderBid is the result of suavex_bid.
+type builderBid struct {
+	// Root is the hash that the builder signs, the emulator does not compute
+	// the SSZ signing root of the relay but the hash of the message
+	Root    common.Hash        `json:"root"`
+	Message *builderBidMessage `json:"message"`
+}
+
+type builderBidMessage struct {
+	Slot                 hexutil.Uint64 `json:"slot"`
+	ParentHash           common.Hash    `json:"parentHash"`
+	BlockHash            common.Hash    `json:"blockHash"`
+	BuilderPubkey        hexutil.Bytes  `json:"builderPubkey"`
+	ProposerPubkey       hexutil.Bytes  `json:"proposerPubkey"`
+	ProposerFeeRecipient common.Address `json:"proposerFeeRecipient"`
+	GasLimit             hexutil.Uint64 `json:"gasLimit"`
+	GasUsed              hexutil.Uint64 `json:"gasUsed"`
+	NumTxs               hexutil.Uint64 `json:"numTxs"`
+}
+
+func (session *builderSession) bid(blsPubKey []byte) (*builderBid, error) {
+	if session.block == nil {
+		return nil, &rpcError{Code: rpcServerError, Message: "the block of the session is not built, call suavex_buildBlock first"}
+	}
+	if len(blsPubKey) != 48 {
+		return nil, invalidParams("invalid bls public key length %d, expected 48", len(blsPubKey))
+	}
+	msg := &builderBidMessage{
+		Slot:                 hexutil.Uint64(session.args.Slot),
+		ParentHash:           session.block.ParentHash,
+		BlockHash:            session.block.Hash(),
+		BuilderPubkey:        blsPubKey,
+		ProposerPubkey:       session.args.ProposerPubkey,
+		ProposerFeeRecipient: session.args.FeeRecipient,
+		GasLimit:             hexutil.Uint64(session.block.GasLimit),
+		GasUsed:              hexutil.Uint64(session.block.GasUsed),
+		NumTxs:               hexutil.Uint64(len(session.txs)),
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &builderBid{Root: crypto.Keccak256Hash(data), Message: msg}, nil
+}
+
+// call runs a call on the state of the session without modifying it.
+func (session *builderSession) call(args *callArgs) (hexutil.Bytes, error) {
+	if args.To == nil {
+		return nil, invalidParams("contract creation is not supported, \"to\" is required")
+	}
+	data, err := args.data()
+	if err != nil {
+		return nil, err
+	}
+	var from common.Address
+	if args.From != nil {
+		from = *args.From
+	}
+	gas := uint64(defaultCallGas)
+	if args.Gas != nil {
+		gas = uint64(*args.Gas)
+	}
+	state := session.state.copy()
+	value := new(uint256.Int)
+	if args.Value != nil {
+		var overflow bool
+		if value, overflow = uint256.FromBig((*big.Int)(args.Value)); overflow {
+			return nil, invalidParams("value overflows")
+		}
+		// like go-ethereum, the sender gets the value of the call
+		state.AddBalance(from, value)
+	}
+
+	output, _, err := state.call(session.env, from, *args.To, data, value, gas)
+	if errors.Is(err, vm.ErrExecutionReverted) {
+		return nil, revertRPCError(output)
+	} else if err != nil {
+		return nil, &rpcError{Code: rpcServerError, Message: "execution failed: " + err.Error()}
+	}
+	return output, nil
+}
+
+// runSuavex runs the 'suavex' command. It emulates the suavex namespace of suave-geth
+// on the state of the fixture until the process is stopped.
+//
+//	forge-backend suavex --fixture suavex.json [--listen 127.0.0.1:8548]
+func runSuavex(args []string) error {
+	var fixturePath, listenAddr string
+
+	fs := flag.NewFlagSet("suavex", flag.ContinueOnError)
+	fs.StringVar(&fixturePath, "fixture", "", "path to the fixture with the accounts and the scripted outcomes")
+	fs.StringVar(&listenAddr, "listen", "127.0.0.1:8548", "address of the server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fixturePath == "" {
+		return fmt.Errorf("the --fixture flag is required")
+	}
+
+	fixture, err := loadSuavexFixture(fixturePath)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Serving the fixture %s on http://%s", fixturePath, listenAddr)
+	return http.ListenAndServe(listenAddr, jsonRPCServer(newSuavexServer(fixture).handle))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// abiArgument is an input or output argument in the JSON format
+// of the contract ABI.
+type abiArgument struct {
+	Name         string         `json:"name"`
+	Type         string         `json:"type"`
+	InternalType string         `json:"internalType"`
+	Components   []*abiArgument `json:"components,omitempty"`
+}
+
+// abiEntry is a function, event or error in the JSON format of the contract ABI.
+type abiEntry struct {
+	Type    string         `json:"type"`
+	Name    string         `json:"name"`
+	Inputs  []*abiArgument `json:"inputs"`
+	Outputs []*abiArgument `json:"outputs"`
+}
+
+// canonicalType returns the type of the argument as used in the function
+// signatures (i.e. '(bytes16,string)[]' for a tuple array).
+func (a *abiArgument) canonicalType() string {
+	if len(a.Components) == 0 {
+		return a.Type
+	}
+	return "(" + canonicalTypes(a.Components) + ")" + strings.TrimPrefix(a.Type, "tuple")
+}
+
+func canonicalTypes(args []*abiArgument) string {
+	types := []string{}
+	for _, arg := range args {
+		types = append(types, arg.canonicalType())
+	}
+	return strings.Join(types, ",")
+}
+
+// typeResolver resolves the ABI type of the type names in the AST
+// using the declarations (structs, enums and user defined value types)
+// of the contract.
+type typeResolver struct {
+	decls map[int64]*astNode
+}
+
+func newTypeResolver(contract *astNode) *typeResolver {
+	decls := map[int64]*astNode{}
+	contract.Walk(func(node *astNode) {
+		switch node.NodeType {
+		case "StructDefinition", "EnumDefinition", "UserDefinedValueTypeDefinition":
+			decls[node.ID] = node
+		}
+	})
+	return &typeResolver{decls: decls}
+}
+
+// resolveArguments resolves the ABI arguments of a list of variable declarations.
+func (t *typeResolver) resolveArguments(vars []*astNode) ([]*abiArgument, error) {
+	args := []*abiArgument{}
+	for _, v := range vars {
+		arg, err := t.resolve(v.TypeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve type of '%s': %v", v.Name, err)
+		}
+		arg.Name = v.Name
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+func (t *typeResolver) resolve(typeName *astNode) (*abiArgument, error) {
+	if typeName == nil {
+		return nil, fmt.Errorf("type name not found")
+	}
+
+	switch typeName.NodeType {
+	case "ElementaryTypeName":
+		// the type string is the canonical name of the type (i.e. 'uint' is 'uint256')
+		typ := typeName.typeString()
+		if typ == "address payable" {
+			typ = "address"
+		}
+		return &abiArgument{Type: typ, InternalType: typ}, nil
+
+	case "ArrayTypeName":
+		elem, err := t.resolve(typeName.BaseType)
+		if err != nil {
+			return nil, err
+		}
+		suffix := "[]"
+		if typeName.Length != nil {
+			length, ok := typeName.Length.stringValue()
+			if !ok {
+				return nil, fmt.Errorf("array length is not a literal")
+			}
+			suffix = "[" + length + "]"
+		}
+		return &abiArgument{
+			Type:         elem.Type + suffix,
+			InternalType: elem.InternalType + suffix,
+			Components:   elem.Components,
+		}, nil
+
+	case "UserDefinedTypeName":
+		decl, ok := t.decls[typeName.ReferencedDeclaration]
+		if !ok {
+			return nil, fmt.Errorf("declaration %d not found", typeName.ReferencedDeclaration)
+		}
+
+		switch decl.NodeType {
+		case "StructDefinition":
+			components, err := t.resolveArguments(decl.Members)
+			if err != nil {
+				return nil, fmt.Errorf("struct %s: %v", decl.Name, err)
+			}
+			return &abiArgument{
+				Type:         "tuple",
+				InternalType: "struct " + decl.CanonicalName,
+				Components:   components,
+			}, nil
+
+		case "EnumDefinition":
+			return &abiArgument{Type: "uint8", InternalType: "enum " + decl.CanonicalName}, nil
+
+		case "UserDefinedValueTypeDefinition":
+			underlying, err := t.resolve(decl.UnderlyingType)
+			if err != nil {
+				return nil, fmt.Errorf("user defined type %s: %v", decl.Name, err)
+			}
+			return &abiArgument{Type: underlying.Type, InternalType: decl.CanonicalName}, nil
+		}
+		return nil, fmt.Errorf("unexpected declaration type %s", decl.NodeType)
+	}
+
+	return nil, fmt.Errorf("type name %s not supported", typeName.NodeType)
+}
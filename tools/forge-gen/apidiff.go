@@ -0,0 +1,528 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/flashbots/suave-std/tools/suavelib/diff"
+)
+
+// suaveSolPath is the path of Suave.sol relative to the suave std
+const suaveSolPath = "src/suavelib/Suave.sol"
+
+// runAPIDiff runs the 'diff' command. It compares the API of two versions of Suave.sol
+// and writes a Markdown report of the changes.
+func runAPIDiff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.StringVar(&suaveStdPath, "suave-std", resolvePath("../.."), "path to the suave std")
+	output := flags.String("output", "", "write the report to a file instead of the standard output")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: forge-gen diff [flags] <from> <to>\n\n")
+		fmt.Fprintf(flags.Output(), "<from> and <to> are either a path to Suave.sol, a path to its compiler artifact (.json)\n")
+		fmt.Fprintf(flags.Output(), "or a git ref of the suave std repository.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	from, to := flags.Arg(0), flags.Arg(1)
+
+	fromLib, err := loadSuaveLibVersion(from)
+	if err != nil {
+		fmt.Printf("failed to load Suave.sol from '%s': %v\n", from, err)
+		os.Exit(1)
+	}
+	toLib, err := loadSuaveLibVersion(to)
+	if err != nil {
+		fmt.Printf("failed to load Suave.sol from '%s': %v\n", to, err)
+		os.Exit(1)
+	}
+
+	// a connector of a precompile that is not in a version is reported as a removal,
+	// the error is kept as a warning of the report
+	warnings := []string{}
+	if err := resolveConnectors(fromLib.Precompiles); err != nil {
+		warnings = append(warnings, fmt.Sprintf("`%s`: %v", from, err))
+	}
+	if err := resolveConnectors(toLib.Precompiles); err != nil {
+		warnings = append(warnings, fmt.Sprintf("`%s`: %v", to, err))
+	}
+
+	changes := compareSuaveLibs(fromLib, toLib)
+
+	usages, err := findSuaveUsages(filepath.Join(suaveStdPath, "src"))
+	if err != nil {
+		fmt.Printf("failed to find the usages of Suave.sol: %v\n", err)
+		os.Exit(1)
+	}
+	markBreakingChanges(changes, fromLib, usages)
+
+	report := renderAPIDiff(from, to, changes, warnings)
+	if *output == "" {
+		fmt.Print(report)
+		return
+	}
+	if err := os.WriteFile(*output, []byte(report), 0644); err != nil {
+		fmt.Printf("failed to write %s: %v\n", *output, err)
+		os.Exit(1)
+	}
+}
+
+// loadSuaveLibVersion loads the model of a version of Suave.sol. The version
+// is a compiler artifact, a Suave.sol file or a git ref of the suave std.
+func loadSuaveLibVersion(version string) (*suaveLib, error) {
+	var art *artifact
+
+	if _, err := os.Stat(version); err == nil {
+		if filepath.Ext(version) == ".json" {
+			if art, err = readArtifact(version); err != nil {
+				return nil, err
+			}
+		} else {
+			src, err := os.ReadFile(version)
+			if err != nil {
+				return nil, err
+			}
+			if art, err = compileSuaveSol(src); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		cmd := exec.Command("git", "-C", suaveStdPath, "show", version+":"+suaveSolPath)
+
+		var errBuf bytes.Buffer
+		cmd.Stderr = &errBuf
+
+		src, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a file and it is not a git ref: %v, %s", version, err, errBuf.String())
+		}
+		if art, err = compileSuaveSol(src); err != nil {
+			return nil, err
+		}
+	}
+
+	return parseSuaveLib(art)
+}
+
+// compileSuaveSol compiles Suave.sol in a temporary forge project and returns its artifact.
+func compileSuaveSol(src []byte) (*artifact, error) {
+	dir, err := os.MkdirTemp("", "forge-gen-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "Suave.sol"), src, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "foundry.toml"), []byte("[profile.default]\nast = true\n"), 0644); err != nil {
+		return nil, err
+	}
+
+	if _, err := execForgeCommand([]string{"build", "--root", dir}, ""); err != nil {
+		return nil, err
+	}
+	return readArtifact(filepath.Join(dir, "out", "Suave.sol", "Suave.json"))
+}
+
+type apiChangeKind string
+
+const (
+	apiChangeAdded   apiChangeKind = "Added"
+	apiChangeRemoved apiChangeKind = "Removed"
+	apiChangeChanged apiChangeKind = "Changed"
+)
+
+// apiChange is a change in a declaration of Suave.sol
+type apiChange struct {
+	Kind apiChangeKind
+	// Item is the kind of declaration (i.e. 'precompile' or 'struct')
+	Item string
+	Name string
+
+	// Before and After are the text of the declaration in each version
+	Before, After string
+
+	// Usages are the locations in src/ that use the declaration
+	// (or a declaration that depends on it) if the change is breaking.
+	Usages []*suaveUsage
+}
+
+// compareSuaveLibs returns the changes from one version of Suave.sol to another.
+func compareSuaveLibs(from, to *suaveLib) []*apiChange {
+	changes := []*apiChange{}
+
+	compare := func(item string, before, after map[string]string) {
+		names := []string{}
+		for name := range before {
+			names = append(names, name)
+		}
+		for name := range after {
+			if _, ok := before[name]; !ok {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			b, inBefore := before[name]
+			a, inAfter := after[name]
+
+			change := &apiChange{Item: item, Name: name, Before: b, After: a}
+			switch {
+			case !inBefore:
+				change.Kind = apiChangeAdded
+			case !inAfter:
+				change.Kind = apiChangeRemoved
+			case a != b:
+				change.Kind = apiChangeChanged
+			default:
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	compare("precompile", precompileDecls(from), precompileDecls(to))
+	compare("function", functionDecls(from), functionDecls(to))
+	compare("struct", structDecls(from), structDecls(to))
+	compare("enum", enumDecls(from), enumDecls(to))
+	compare("type", userTypeDecls(from), userTypeDecls(to))
+	compare("constant", constantDecls(from), constantDecls(to))
+	return changes
+}
+
+func precompileDecls(lib *suaveLib) map[string]string {
+	res := map[string]string{}
+	for _, p := range lib.Precompiles {
+		res[p.Name] = strings.ToLower(p.Address)
+	}
+	return res
+}
+
+func functionDecls(lib *suaveLib) map[string]string {
+	res := map[string]string{}
+	for _, p := range lib.Precompiles {
+		fn := p.Function
+
+		decl := fmt.Sprintf("function %s(%s)", fn.Name, declArguments(fn.Inputs))
+		if len(fn.Outputs) != 0 {
+			decl += fmt.Sprintf(" returns (%s)", declArguments(fn.Outputs))
+		}
+		if fn.OutputEncoding == outputEncodingRaw {
+			decl += " // raw output"
+		}
+		res[fn.Name] = decl
+	}
+	return res
+}
+
+func structDecls(lib *suaveLib) map[string]string {
+	res := map[string]string{}
+	for _, s := range lib.Structs {
+		fields := []string{}
+		for _, field := range s.Fields {
+			fields = append(fields, "    "+declArgument(field)+";")
+		}
+		res[s.Name] = fmt.Sprintf("struct %s {\n%s\n}", s.Name, strings.Join(fields, "\n"))
+	}
+	return res
+}
+
+func enumDecls(lib *suaveLib) map[string]string {
+	res := map[string]string{}
+	for _, e := range lib.Enums {
+		res[e.Name] = fmt.Sprintf("enum %s { %s }", e.Name, strings.Join(e.Values, ", "))
+	}
+	return res
+}
+
+func userTypeDecls(lib *suaveLib) map[string]string {
+	res := map[string]string{}
+	for _, u := range lib.UserTypes {
+		res[u.Name] = fmt.Sprintf("type %s is %s", u.Name, u.Underlying)
+	}
+	return res
+}
+
+func constantDecls(lib *suaveLib) map[string]string {
+	res := map[string]string{}
+	for _, c := range lib.Constants {
+		if c.Kind == constantKindPrecompile {
+			if _, ok := ignoredPrecompiles[c.Name]; !ok {
+				// listed as a precompile
+				continue
+			}
+		}
+		res[c.Name] = fmt.Sprintf("%s constant %s = %s", c.Type, c.Name, c.Value)
+	}
+	return res
+}
+
+func declArguments(args []*abiArgument) string {
+	decls := []string{}
+	for _, arg := range args {
+		decls = append(decls, declArgument(arg))
+	}
+	return strings.Join(decls, ", ")
+}
+
+func declArgument(arg *abiArgument) string {
+	typ := strings.TrimPrefix(strings.TrimPrefix(arg.InternalType, "struct "), "enum ")
+	typ = strings.TrimPrefix(typ, "Suave.")
+	if arg.Name == "" {
+		return typ
+	}
+	return typ + " " + arg.Name
+}
+
+// suaveUsage is a reference to a declaration of Suave.sol in the source code.
+type suaveUsage struct {
+	File string
+	Line int
+	// Name is the referenced declaration
+	Name string
+}
+
+var suaveUsageRegexp = regexp.MustCompile(`\bSuave\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// findSuaveUsages finds the references ('Suave.<name>') to Suave.sol in the
+// hand-written Solidity files of a directory. The files generated by forge-gen
+// are skipped since they are regenerated with the new version.
+func findSuaveUsages(dir string) (map[string][]*suaveUsage, error) {
+	root := filepath.Dir(dir)
+	usages := map[string][]*suaveUsage{}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".sol" || filepath.Base(path) == "Suave.sol" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(content, []byte("Code generated by forge-gen")) {
+			return nil
+		}
+
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for line := 1; scanner.Scan(); line++ {
+			for _, match := range suaveUsageRegexp.FindAllStringSubmatch(scanner.Text(), -1) {
+				usages[match[1]] = append(usages[match[1]], &suaveUsage{File: name, Line: line, Name: match[1]})
+			}
+		}
+		return scanner.Err()
+	})
+	return usages, err
+}
+
+// markBreakingChanges sets the usages of the removed and changed declarations. A change
+// in a type also affects the structs that contain it and the functions that use any of
+// them, and a change in a function also affects the connector that serves its precompile.
+func markBreakingChanges(changes []*apiChange, from *suaveLib, usages map[string][]*suaveUsage) {
+	connectorUsages := map[string][]*suaveUsage{}
+	for _, p := range from.Precompiles {
+		if p.Connector == defaultConnector {
+			continue
+		}
+		usage := &suaveUsage{File: filepath.Join("src", "forge", p.Connector+".sol"), Name: p.Name}
+		connectorUsages[p.Name] = append(connectorUsages[p.Name], usage)
+		connectorUsages[p.Function.Name] = append(connectorUsages[p.Function.Name], usage)
+	}
+
+	for _, change := range changes {
+		if change.Kind == apiChangeAdded {
+			continue
+		}
+		if change.Kind == apiChangeChanged && change.Item == "precompile" {
+			// the source code references the precompiles by name, a new address is not breaking
+			continue
+		}
+
+		affected := []string{change.Name}
+		switch change.Item {
+		case "struct", "enum", "type":
+			// the structs that contain the type and the functions (and its precompiles) that use it
+			types := containingTypes(from, change.Name)
+			affected = append(affected, types[1:]...)
+			for _, p := range from.Precompiles {
+				if slices.ContainsFunc(types, func(name string) bool { return usesType(p.Function, name) }) {
+					affected = append(affected, p.Function.Name, p.Name)
+				}
+			}
+		case "function":
+			for _, p := range from.Precompiles {
+				if p.Function.Name == change.Name {
+					affected = append(affected, p.Name)
+				}
+			}
+		}
+
+		seen := map[string]bool{}
+		for _, name := range affected {
+			for _, usage := range append(usages[name], connectorUsages[name]...) {
+				key := fmt.Sprintf("%s:%d:%s", usage.File, usage.Line, usage.Name)
+				if !seen[key] {
+					seen[key] = true
+					change.Usages = append(change.Usages, usage)
+				}
+			}
+		}
+		sort.SliceStable(change.Usages, func(i, j int) bool {
+			if change.Usages[i].File != change.Usages[j].File {
+				return change.Usages[i].File < change.Usages[j].File
+			}
+			return change.Usages[i].Line < change.Usages[j].Line
+		})
+	}
+}
+
+func usesType(fn *function, name string) bool {
+	return argumentsUseType(fn.Inputs, name) || argumentsUseType(fn.Outputs, name)
+}
+
+func argumentsUseType(args []*abiArgument, name string) bool {
+	for _, arg := range args {
+		typ := strings.TrimPrefix(strings.TrimPrefix(arg.InternalType, "struct "), "enum ")
+		if strings.TrimRight(typ, "[]0123456789") == "Suave."+name || argumentsUseType(arg.Components, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// containingTypes returns the type followed by the structs that contain it,
+// either in a field or in a field of another struct.
+func containingTypes(lib *suaveLib, name string) []string {
+	types := []string{name}
+	for i := 0; i < len(types); i++ {
+		for _, s := range lib.Structs {
+			if !slices.Contains(types, s.Name) && argumentsUseType(s.Fields, types[i]) {
+				types = append(types, s.Name)
+			}
+		}
+	}
+	return types
+}
+
+// renderAPIDiff renders the changes as Markdown.
+func renderAPIDiff(from, to string, changes []*apiChange, warnings []string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## Suave.sol API changes\n\n")
+	fmt.Fprintf(&b, "Changes from `%s` to `%s`.\n\n", from, to)
+
+	if len(warnings) != 0 {
+		fmt.Fprintf(&b, "### Warnings\n\n")
+		for _, warning := range warnings {
+			fmt.Fprintf(&b, "- %s\n", warning)
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(&b, "No API changes.\n")
+		return b.String()
+	}
+
+	breaking := []*apiChange{}
+	for _, change := range changes {
+		if len(change.Usages) != 0 {
+			breaking = append(breaking, change)
+		}
+	}
+	if len(breaking) != 0 {
+		fmt.Fprintf(&b, "### :warning: Breaking changes\n\n")
+		fmt.Fprintf(&b, "The following declarations are removed or changed and they are used by the source code:\n\n")
+		for _, change := range breaking {
+			fmt.Fprintf(&b, "- %s %s `%s`, used in:\n", change.Kind, change.Item, change.Name)
+			for _, usage := range change.Usages {
+				if usage.Line == 0 {
+					fmt.Fprintf(&b, "  - `%s` (connector of `%s`)\n", usage.File, usage.Name)
+				} else {
+					fmt.Fprintf(&b, "  - `%s:%d` (`Suave.%s`)\n", usage.File, usage.Line, usage.Name)
+				}
+			}
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	// precompiles in a table
+	precompiles := filterChanges(changes, "precompile")
+	if len(precompiles) != 0 {
+		fmt.Fprintf(&b, "### Precompiles\n\n")
+		fmt.Fprintf(&b, "| Change | Precompile | Address |\n")
+		fmt.Fprintf(&b, "| --- | --- | --- |\n")
+		for _, change := range precompiles {
+			address := "`" + change.After + "`"
+			switch change.Kind {
+			case apiChangeRemoved:
+				address = "`" + change.Before + "`"
+			case apiChangeChanged:
+				address = fmt.Sprintf("`%s` → `%s`", change.Before, change.After)
+			}
+			fmt.Fprintf(&b, "| %s | `%s` | %s |\n", change.Kind, change.Name, address)
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+
+	// declarations as a diff
+	sections := []struct{ item, title string }{
+		{"function", "Functions"},
+		{"struct", "Structs"},
+		{"enum", "Enums"},
+		{"type", "User defined value types"},
+		{"constant", "Constants"},
+	}
+	for _, section := range sections {
+		items := filterChanges(changes, section.item)
+		if len(items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "### %s\n\n", section.title)
+		for _, change := range items {
+			fmt.Fprintf(&b, "- %s `%s`\n\n", change.Kind, change.Name)
+			fmt.Fprintf(&b, "```diff\n")
+			for _, op := range diff.Lines(splitLines(change.Before), splitLines(change.After)) {
+				fmt.Fprintf(&b, "%c%s\n", op.Kind, op.Line)
+			}
+			fmt.Fprintf(&b, "```\n\n")
+		}
+	}
+	return b.String()
+}
+
+func filterChanges(changes []*apiChange, item string) []*apiChange {
+	res := []*apiChange{}
+	for _, change := range changes {
+		if change.Item == item {
+			res = append(res, change)
+		}
+	}
+	return res
+}
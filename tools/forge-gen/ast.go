@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// artifact is the output of 'forge build' for a single contract.
+// It requires the 'ast' option enabled in foundry.toml.
+type artifact struct {
+	Abi []*abiEntry `json:"abi"`
+	Ast *astNode    `json:"ast"`
+}
+
+func readArtifact(path string) (*artifact, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var artifact artifact
+	if err := json.Unmarshal(content, &artifact); err != nil {
+		return nil, err
+	}
+	if artifact.Ast == nil {
+		return nil, fmt.Errorf("artifact %s has no ast, is 'ast = true' set in foundry.toml?", filepath.Base(path))
+	}
+	return &artifact, nil
+}
+
+// astNode is a node of the solc compact AST. It only decodes the fields
+// that forge-gen requires. Fields that have a different type depending on
+// the node type (i.e. 'parameters' or 'value') are decoded lazily.
+type astNode struct {
+	ID                    int64
+	AbsolutePath          string
+	Name                  string
+	CanonicalName         string
+	NodeType              string
+	Nodes                 []*astNode
+	Constant              bool
+	Documentation         *astDocumentation
+	Members               []*astNode
+	TypeName              *astNode
+	BaseType              *astNode
+	UnderlyingType        *astNode
+	Length                *astNode
+	ReferencedDeclaration int64
+	TypeDescriptions      *astTypeDescriptions
+	Parameters            json.RawMessage
+	ReturnParameters      *astNode
+	Value                 json.RawMessage
+	Body                  json.RawMessage
+}
+
+type astDocumentation struct {
+	Text string
+}
+
+type astTypeDescriptions struct {
+	TypeIdentifier string
+	TypeString     string
+}
+
+// typeString returns the Solidity type of a declaration.
+func (a *astNode) typeString() string {
+	if a.TypeDescriptions == nil {
+		return ""
+	}
+	return a.TypeDescriptions.TypeString
+}
+
+func (a *astNode) docs() string {
+	if a.Documentation == nil {
+		return ""
+	}
+	return a.Documentation.Text
+}
+
+// parameterList returns the parameters of a 'ParameterList' node.
+func (a *astNode) parameterList() ([]*astNode, error) {
+	var params []*astNode
+	if err := json.Unmarshal(a.Parameters, &params); err != nil {
+		return nil, fmt.Errorf("failed to decode parameter list: %v", err)
+	}
+	return params, nil
+}
+
+// inputParameters returns the input parameters of a 'FunctionDefinition' node.
+func (a *astNode) inputParameters() ([]*astNode, error) {
+	var list astNode
+	if err := json.Unmarshal(a.Parameters, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode parameters of %s: %v", a.Name, err)
+	}
+	return list.parameterList()
+}
+
+// outputParameters returns the return parameters of a 'FunctionDefinition' node.
+func (a *astNode) outputParameters() ([]*astNode, error) {
+	if a.ReturnParameters == nil {
+		return []*astNode{}, nil
+	}
+	return a.ReturnParameters.parameterList()
+}
+
+// literalValue returns the value of the 'Literal' assigned to a constant 'VariableDeclaration'.
+func (a *astNode) literalValue() (string, bool) {
+	if len(a.Value) == 0 {
+		return "", false
+	}
+	var literal struct {
+		NodeType string
+		Value    string
+	}
+	if err := json.Unmarshal(a.Value, &literal); err != nil {
+		return "", false
+	}
+	if literal.NodeType != "Literal" {
+		return "", false
+	}
+	return literal.Value, true
+}
+
+// stringValue returns the value of a 'Literal' node.
+func (a *astNode) stringValue() (string, bool) {
+	var value string
+	if err := json.Unmarshal(a.Value, &value); err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+func (a *astNode) Walk(handle func(*astNode)) {
+	handle(a)
+	for _, node := range a.Nodes {
+		node.Walk(handle)
+	}
+}
+
+func (a *astNode) Filter(check func(*astNode) bool) []*astNode {
+	res := []*astNode{}
+	a.Walk(func(b *astNode) {
+		if check(b) {
+			res = append(res, b)
+		}
+	})
+	return res
+}
+
+// walkRaw walks an undecoded section of the AST (i.e. the body of a function)
+// and calls handle for each of the nodes (any object with a 'nodeType' field).
+func walkRaw(raw json.RawMessage, handle func(map[string]interface{})) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	var obj interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return err
+	}
+
+	var walk func(interface{})
+	walk = func(obj interface{}) {
+		switch obj := obj.(type) {
+		case map[string]interface{}:
+			if _, ok := obj["nodeType"]; ok {
+				handle(obj)
+			}
+			for _, v := range obj {
+				walk(v)
+			}
+		case []interface{}:
+			for _, v := range obj {
+				walk(v)
+			}
+		}
+	}
+	walk(obj)
+	return nil
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"text/template"
+)
+
+type goBindings struct {
+	// NeedsBig is true if any of the types is encoded as a big.Int
+	NeedsBig bool
+
+	Provenance *provenance
+
+	UserTypes   []*goUserType
+	Enums       []*enumDef
+	Structs     []*goStruct
+	Precompiles []*goPrecompile
+}
+
+type goUserType struct {
+	Name string
+	Type string
+}
+
+type goStruct struct {
+	Name        string
+	Description string
+	Fields      []*goField
+}
+
+type goField struct {
+	Name        string
+	Type        string
+	Tag         string
+	Description string
+}
+
+type goPrecompile struct {
+	Name        string
+	FuncName    string
+	VarName     string
+	Address     string
+	Description string
+	Inputs      []*goArg
+	Outputs     []*goArg
+	InputsABI   string
+	OutputsABI  string
+	RawOutput   bool
+}
+
+type goArg struct {
+	Name string
+	Type string
+	// Underlying is the go type decoded by go-ethereum for an enum,
+	// which has to be converted to the enum type
+	Underlying string
+}
+
+func renderGoBindings(lib *suaveLib) (string, error) {
+	bindings := &goBindings{Provenance: lib.Provenance}
+
+	for _, typ := range lib.UserTypes {
+		goTyp, err := goType(&abiArgument{Type: typ.Underlying, InternalType: typ.Underlying})
+		if err != nil {
+			return "", fmt.Errorf("user defined type %s: %v", typ.Name, err)
+		}
+		bindings.UserTypes = append(bindings.UserTypes, &goUserType{Name: typ.Name, Type: goTyp})
+	}
+
+	bindings.Enums = lib.Enums
+
+	for _, s := range lib.Structs {
+		goS := &goStruct{
+			Name:        s.Name,
+			Description: s.Description,
+		}
+		for _, field := range s.Fields {
+			typ, err := goType(field)
+			if err != nil {
+				return "", fmt.Errorf("struct %s field %s: %v", s.Name, field.Name, err)
+			}
+			goS.Fields = append(goS.Fields, &goField{
+				// go-ethereum matches the tuple components with the struct fields in camel case
+				Name:        toCamelCase(field.Name),
+				Type:        typ,
+				Tag:         fmt.Sprintf("`json:\"%s\"`", field.Name),
+				Description: s.FieldDescriptions[field.Name],
+			})
+		}
+		bindings.Structs = append(bindings.Structs, goS)
+	}
+
+	for _, p := range lib.Precompiles {
+		fn := p.Function
+
+		goP := &goPrecompile{
+			Name:        p.Name,
+			FuncName:    toCamelCase(fn.Name),
+			VarName:     fn.Name,
+			Address:     p.Address,
+			Description: fn.Description,
+			RawOutput:   fn.OutputEncoding == outputEncodingRaw,
+		}
+
+		var err error
+		if goP.Inputs, err = goArgs(fn.Inputs, "in"); err != nil {
+			return "", fmt.Errorf("function %s: %v", fn.Name, err)
+		}
+		if goP.Outputs, err = goArgs(fn.Outputs, "out"); err != nil {
+			return "", fmt.Errorf("function %s: %v", fn.Name, err)
+		}
+		if goP.InputsABI, err = marshalArguments(fn.Inputs); err != nil {
+			return "", err
+		}
+		if goP.OutputsABI, err = marshalArguments(fn.Outputs); err != nil {
+			return "", err
+		}
+		bindings.Precompiles = append(bindings.Precompiles, goP)
+	}
+
+	// check whether the 'math/big' package has to be imported
+	for _, p := range bindings.Precompiles {
+		for _, args := range [][]*goArg{p.Inputs, p.Outputs} {
+			for _, arg := range args {
+				if strings.Contains(arg.Type, "*big.Int") {
+					bindings.NeedsBig = true
+				}
+			}
+		}
+	}
+	for _, s := range bindings.Structs {
+		for _, field := range s.Fields {
+			if strings.Contains(field.Type, "*big.Int") {
+				bindings.NeedsBig = true
+			}
+		}
+	}
+
+	t, err := template.New("template").Parse(goBindingsTemplate)
+	if err != nil {
+		return "", err
+	}
+	var outputRaw bytes.Buffer
+	if err = t.Execute(&outputRaw, bindings); err != nil {
+		return "", err
+	}
+
+	output, err := format.Source(outputRaw.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format the go bindings: %v", err)
+	}
+	return string(output), nil
+}
+
+// goReservedNames are the names used by the generated functions that
+// cannot be used as argument names.
+var goReservedNames = map[string]bool{
+	"data":   true,
+	"values": true,
+	"err":    true,
+}
+
+func goArgs(args []*abiArgument, prefix string) ([]*goArg, error) {
+	res := []*goArg{}
+	for indx, arg := range args {
+		typ, err := goType(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %v", arg.Name, err)
+		}
+
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("%s%d", prefix, indx)
+		}
+		if token.IsKeyword(name) || goReservedNames[name] {
+			name = name + "Arg"
+		}
+		goArg := &goArg{Name: name, Type: typ}
+		if strings.HasPrefix(arg.InternalType, "enum ") && !strings.HasSuffix(arg.InternalType, "]") {
+			goArg.Underlying = arg.Type
+		}
+		res = append(res, goArg)
+	}
+	return res, nil
+}
+
+func marshalArguments(args []*abiArgument) (string, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// goType returns the Go type used by go-ethereum to encode and decode an ABI type.
+func goType(arg *abiArgument) (string, error) {
+	typ, internalType := arg.Type, arg.InternalType
+
+	if strings.HasSuffix(typ, "]") {
+		indx := strings.LastIndex(typ, "[")
+		internalIndx := strings.LastIndex(internalType, "[")
+		if internalIndx == -1 {
+			return "", fmt.Errorf("internal type %s is not an array", internalType)
+		}
+		elem, err := goType(&abiArgument{Type: typ[:indx], InternalType: internalType[:internalIndx], Components: arg.Components})
+		if err != nil {
+			return "", err
+		}
+		return typ[indx:] + elem, nil
+	}
+
+	// structs, enums and user defined value types are named after the Solidity type
+	if strings.HasPrefix(internalType, "struct ") || strings.HasPrefix(internalType, "enum ") || internalType != typ {
+		return internalType[strings.LastIndex(internalType, ".")+1:], nil
+	}
+
+	switch {
+	case typ == "address":
+		return "common.Address", nil
+	case typ == "bool" || typ == "string":
+		return typ, nil
+	case typ == "bytes":
+		return "[]byte", nil
+	case strings.HasPrefix(typ, "bytes"):
+		return fmt.Sprintf("[%s]byte", strings.TrimPrefix(typ, "bytes")), nil
+	case strings.HasPrefix(typ, "uint") || strings.HasPrefix(typ, "int"):
+		switch strings.TrimLeft(typ, "uint") {
+		case "8", "16", "32", "64":
+			return typ, nil
+		}
+		return "*big.Int", nil
+	}
+	return "", fmt.Errorf("type %s not supported", typ)
+}
+
+// toCamelCase converts a Solidity name to an exported Go name. It matches
+// the conversion go-ethereum does to find the fields of a tuple in a struct.
+func toCamelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for indx, part := range parts {
+		if len(part) > 0 {
+			parts[indx] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+var goBindingsTemplate = `// Code generated by forge-gen. DO NOT EDIT.
+
+// Package suavelib contains the Go bindings of the Suave.sol library. It includes
+// the types, the address of each precompile and the functions to encode and decode
+// the inputs and outputs of the precompiles with the same ABI encoding as Suave.sol.
+package suavelib
+
+import (
+	"encoding/json"
+	{{- if .NeedsBig}}
+	"math/big"
+	{{- end}}
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+{{range .UserTypes}}
+// {{.Name}} is the Suave.{{.Name}} user defined value type.
+type {{.Name}} {{.Type}}
+{{end}}
+
+{{range .Enums}}
+{{$enum := .Name}}
+// {{.Name}} is the Suave.{{.Name}} enum.
+type {{.Name}} uint8
+
+const (
+{{- range $indx, $value := .Values}}
+	{{$enum}}{{$value}} {{$enum}} = {{$indx}}
+{{- end}}
+)
+{{end}}
+
+{{range .Structs}}
+// {{.Name}} is the Suave.{{.Name}} struct. {{.Description}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}} {{if .Description}}// {{.Description}}{{end}}
+{{- end}}
+}
+{{end}}
+
+// Provenance of the Suave.sol file used to generate the bindings.
+const (
+	// SuaveSolHash is the sha256 hash of Suave.sol
+	SuaveSolHash = "{{.Provenance.SuaveSolHash}}"
+	// SuaveGethCommit is the suave-geth commit that Suave.sol was synced from (empty if unknown)
+	SuaveGethCommit = "{{.Provenance.SuaveGethCommit}}"
+)
+
+// Addresses of the Suave precompiles.
+var (
+{{- range .Precompiles}}
+	{{.FuncName}}Addr = common.HexToAddress("{{.Address}}")
+{{- end}}
+)
+
+// PrecompileNames maps the address of each precompile to its name in Suave.sol.
+var PrecompileNames = map[common.Address]string{
+{{- range .Precompiles}}
+	{{.FuncName}}Addr: "{{.Name}}",
+{{- end}}
+}
+
+// Precompile is the ABI of a precompile, to decode its inputs and outputs
+// without knowing the precompile in advance.
+type Precompile struct {
+	// Name is the name of the address in Suave.sol
+	Name string
+	// Function is the function of Suave.sol that calls the precompile
+	Function string
+	Inputs   abi.Arguments
+	Outputs  abi.Arguments
+	// RawOutput is true if the precompile returns the output as is, without abi encoding
+	RawOutput bool
+}
+
+// Precompiles maps the address of each precompile to its ABI.
+var Precompiles = map[common.Address]*Precompile{
+{{- range .Precompiles}}
+	{{.FuncName}}Addr: {Name: "{{.Name}}", Function: "{{.VarName}}", Inputs: {{.VarName}}Inputs, Outputs: {{.VarName}}Outputs{{if .RawOutput}}, RawOutput: true{{end}}},
+{{- end}}
+}
+
+var (
+{{- range .Precompiles}}
+	{{.VarName}}Inputs = mustArguments(` + "`{{.InputsABI}}`" + `)
+	{{.VarName}}Outputs = mustArguments(` + "`{{.OutputsABI}}`" + `)
+{{- end}}
+)
+
+{{range .Precompiles}}
+{{$p := .}}
+// Pack{{.FuncName}}Inputs encodes the inputs of the {{.Name}} precompile.
+// {{.Description}}
+func Pack{{.FuncName}}Inputs({{range $indx, $arg := .Inputs}}{{if $indx}}, {{end}}{{$arg.Name}} {{$arg.Type}}{{end}}) ([]byte, error) {
+	return {{.VarName}}Inputs.Pack({{range $indx, $arg := .Inputs}}{{if $indx}}, {{end}}{{$arg.Name}}{{end}})
+}
+
+// Unpack{{.FuncName}}Inputs decodes the inputs of the {{.Name}} precompile.
+func Unpack{{.FuncName}}Inputs(data []byte) ({{range .Inputs}}{{.Name}} {{.Type}}, {{end}}err error) {
+	{{- if .Inputs}}
+	values, err := {{.VarName}}Inputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	{{- range $indx, $arg := .Inputs}}
+	{{- if $arg.Underlying}}
+	{{$arg.Name}} = {{$arg.Type}}(*abi.ConvertType(values[{{$indx}}], new({{$arg.Underlying}})).(*{{$arg.Underlying}}))
+	{{- else}}
+	{{$arg.Name}} = *abi.ConvertType(values[{{$indx}}], new({{$arg.Type}})).(*{{$arg.Type}})
+	{{- end}}
+	{{- end}}
+	return
+	{{- else}}
+	_, err = {{.VarName}}Inputs.Unpack(data)
+	return
+	{{- end}}
+}
+
+// Pack{{.FuncName}}Outputs encodes the outputs of the {{.Name}} precompile.
+{{- if .RawOutput}}
+// The precompile returns the output as is, without abi encoding.
+{{- end}}
+func Pack{{.FuncName}}Outputs({{range $indx, $arg := .Outputs}}{{if $indx}}, {{end}}{{$arg.Name}} {{$arg.Type}}{{end}}) ([]byte, error) {
+	{{- if .RawOutput}}
+	return {{(index .Outputs 0).Name}}, nil
+	{{- else}}
+	return {{.VarName}}Outputs.Pack({{range $indx, $arg := .Outputs}}{{if $indx}}, {{end}}{{$arg.Name}}{{end}})
+	{{- end}}
+}
+
+// Unpack{{.FuncName}}Outputs decodes the outputs of the {{.Name}} precompile.
+{{- if .RawOutput}}
+// The precompile returns the output as is, without abi encoding.
+{{- end}}
+func Unpack{{.FuncName}}Outputs(data []byte) ({{range .Outputs}}{{.Name}} {{.Type}}, {{end}}err error) {
+	{{- if .RawOutput}}
+	{{(index .Outputs 0).Name}} = data
+	return
+	{{- else if .Outputs}}
+	values, err := {{.VarName}}Outputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	{{- range $indx, $arg := .Outputs}}
+	{{- if $arg.Underlying}}
+	{{$arg.Name}} = {{$arg.Type}}(*abi.ConvertType(values[{{$indx}}], new({{$arg.Underlying}})).(*{{$arg.Underlying}}))
+	{{- else}}
+	{{$arg.Name}} = *abi.ConvertType(values[{{$indx}}], new({{$arg.Type}})).(*{{$arg.Type}})
+	{{- end}}
+	{{- end}}
+	return
+	{{- else}}
+	_, err = {{.VarName}}Outputs.Unpack(data)
+	return
+	{{- end}}
+}
+{{end}}
+
+func mustArguments(str string) abi.Arguments {
+	var args abi.Arguments
+	if err := json.Unmarshal([]byte(str), &args); err != nil {
+		panic(err)
+	}
+	return args
+}
+`
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"text/template"
+)
+
+// confStoreConnector is the connector that forwards the precompile calls to the
+// ConfidentialStore contract (src/forge/ConfidentialStore.sol).
+const confStoreConnector = "ConfidentialStoreConnector"
+
+// confStoreCall is the call to a ConfidentialStore method that serves a precompile.
+type confStoreCall struct {
+	Name   string
+	Inputs []*solArg
+	// Signature is the signature of the ConfidentialStore method
+	Signature string
+	// WithSender is true if the method takes the caller of the precompile as the last argument
+	WithSender bool
+	// UnwrapOutput is true if the precompile returns the raw output
+	// while the method returns it abi encoded
+	UnwrapOutput bool
+}
+
+func renderConfidentialStoreConnector(lib *suaveLib) (string, error) {
+	// ConfidentialStore.sol must be compiled with 'forge build' before running forge-gen
+	store, err := readArtifact(filepath.Join(suaveStdPath, "out", "ConfidentialStore.sol", "ConfidentialStore.json"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read ConfidentialStore.sol artifact: %v", err)
+	}
+
+	calls := []*confStoreCall{}
+	for _, p := range lib.Precompiles {
+		if p.Connector != confStoreConnector {
+			continue
+		}
+		call, err := resolveConfStoreCall(p, store.Abi)
+		if err != nil {
+			return "", err
+		}
+		calls = append(calls, call)
+	}
+
+	t, err := template.New("template").Parse(confStoreConnectorTemplate)
+	if err != nil {
+		return "", err
+	}
+	var outputRaw bytes.Buffer
+	if err = t.Execute(&outputRaw, map[string]interface{}{"Calls": calls}); err != nil {
+		return "", err
+	}
+	return formatSolidity(outputRaw.String())
+}
+
+// resolveConfStoreCall finds the ConfidentialStore method with the same name and signature
+// as the function of the precompile. The method can take an extra 'address' argument
+// with the caller of the precompile. It fails if there is no such method.
+func resolveConfStoreCall(p *precompile, storeAbi []*abiEntry) (*confStoreCall, error) {
+	fn := p.Function
+
+	inputs, err := solArgs(fn.Inputs)
+	if err != nil {
+		return nil, fmt.Errorf("function %s: %v", fn.Name, err)
+	}
+	call := &confStoreCall{
+		Name:   p.Name,
+		Inputs: inputs,
+	}
+
+	inputTypes := canonicalTypes(fn.Inputs)
+	withSenderTypes := inputTypes + ",address"
+	if len(fn.Inputs) == 0 {
+		withSenderTypes = "address"
+	}
+
+	var method *abiEntry
+	for _, entry := range storeAbi {
+		if entry.Type != "function" || entry.Name != fn.Name {
+			continue
+		}
+		// prefer the method with the sender
+		switch canonicalTypes(entry.Inputs) {
+		case withSenderTypes:
+			method, call.WithSender = entry, true
+		case inputTypes:
+			if method == nil {
+				method = entry
+			}
+		}
+	}
+	if method == nil {
+		return nil, fmt.Errorf("precompile '%s' is served by the %s but ConfidentialStore does not have a method '%s(%s)' nor '%s(%s)'", p.Name, confStoreConnector, fn.Name, inputTypes, fn.Name, withSenderTypes)
+	}
+	call.Signature = fmt.Sprintf("%s(%s)", method.Name, canonicalTypes(method.Inputs))
+
+	// the outputs of the method must match the outputs of the precompile
+	if found, expected := canonicalTypes(method.Outputs), canonicalTypes(fn.Outputs); found != expected {
+		return nil, fmt.Errorf("ConfidentialStore method '%s' returns (%s) but precompile '%s' returns (%s)", call.Signature, found, p.Name, expected)
+	}
+	call.UnwrapOutput = fn.OutputEncoding == outputEncodingRaw
+
+	return call, nil
+}
+
+var confStoreConnectorTemplate = `// SPDX-License-Identifier: UNLICENSED
+// DO NOT edit this file. Code generated by forge-gen.
+pragma solidity ^0.8.8;
+
+import "../suavelib/Suave.sol";
+
+// ConfidentialStoreConnector forwards the calls to the confidential store precompiles
+// to the ConfidentialStore contract deployed by the Registry.
+contract ConfidentialStoreConnector {
+	fallback() external {
+		address confidentialStoreAddr = 0x0101010101010101010101010101010101010101;
+
+		address addr = address(this);
+		bytes memory input;
+
+		{{range $indx, $call := .Calls -}}
+		{{if $indx}} else {{end}}if (addr == Suave.{{.Name}}) {
+			bytes4 selector = bytes4(keccak256("{{.Signature}}"));
+			{{- if .WithSender}}
+			{{- if .Inputs}}
+			({{range $i, $arg := .Inputs}}{{if $i}}, {{end}}{{$arg.Decl}}{{end}}) = abi.decode(msg.data, ({{range $i, $arg := .Inputs}}{{if $i}}, {{end}}{{$arg.Type}}{{end}}));
+			{{- end}}
+
+			input = abi.encodeWithSelector(selector{{range .Inputs}}, {{.Name}}{{end}}, msg.sender);
+			{{- else}}
+			input = abi.encodePacked(selector, msg.data);
+			{{- end}}
+		}
+		{{- end}} else {
+			revert("function signature not found in the confidential store");
+		}
+
+		(bool success, bytes memory output) = confidentialStoreAddr.call(input);
+		if (!success) {
+			revert("Call to confidentialStore failed");
+		}
+		{{range .Calls}}
+		{{- if .UnwrapOutput}}
+		if (addr == Suave.{{.Name}}) {
+			// the precompile returns the raw output but the method returns it abi encoded
+			output = abi.decode(output, (bytes));
+		}
+		{{- end}}
+		{{- end}}
+
+		assembly {
+			let location := output
+			let length := mload(output)
+			return(add(location, 0x20), length)
+		}
+	}
+}`
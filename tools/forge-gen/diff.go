@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flashbots/suave-std/tools/suavelib/diff"
+)
+
+// diffContextLines is the number of unchanged lines around each hunk.
+const diffContextLines = 3
+
+// unifiedDiff returns the unified diff between two texts or an empty
+// string if both are equal.
+func unifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+	ops := diff.Lines(fileLines(from), fileLines(to))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+
+	// line numbers (1-indexed) in 'from' and 'to' at the start of each op
+	fromLine, toLine := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	fromLine[0], toLine[0] = 1, 1
+	for i, op := range ops {
+		fromLine[i+1], toLine[i+1] = fromLine[i], toLine[i]
+		if op.Kind != '+' {
+			fromLine[i+1]++
+		}
+		if op.Kind != '-' {
+			toLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ' ' {
+			i++
+			continue
+		}
+
+		// extend the hunk while the changes are close to each other
+		start := max(i-diffContextLines, 0)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].Kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContextLines {
+				break
+			}
+		}
+		end = min(end+diffContextLines, len(ops))
+
+		fromCount, toCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.Kind != '+' {
+				fromCount++
+			}
+			if op.Kind != '-' {
+				toCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(fromLine[start], fromCount), hunkRange(toLine[start], toCount))
+		for _, op := range ops[start:end] {
+			fmt.Fprintf(&b, "%c%s\n", op.Kind, op.Line)
+		}
+		i = end
+	}
+	return b.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// the hunk is empty in this side, the range points to the line before
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// noNewlineMarker is appended to the last line of a text that does not end with a
+// newline, so that it differs from the same line with the newline and the diff
+// shows it like diff and git do.
+const noNewlineMarker = "\n\\ No newline at end of file"
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// fileLines splits the content of a file in lines with the marker in the last line
+// if the file does not end with a newline.
+func fileLines(s string) []string {
+	lines := splitLines(s)
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		lines[len(lines)-1] += noNewlineMarker
+	}
+	return lines
+}
+
+// trimSpaceAtEOL removes the spaces at the end of each line (i.e. the carriage returns),
+// which are ignored when the generated files are checked like git diff --ignore-space-at-eol.
+func trimSpaceAtEOL(s string) string {
+	lines := strings.Split(s, "\n")
+	for indx, line := range lines {
+		lines[indx] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,3 +1,7 @@
 module github.com/flashbots/suave-std/tools/forge-gen
 
 go 1.21.0
+
+require github.com/flashbots/suave-std/tools/suavelib v0.0.0
+
+replace github.com/flashbots/suave-std/tools/suavelib => ../suavelib
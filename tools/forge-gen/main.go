@@ -4,34 +4,175 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
-	"html/template"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"runtime"
+	"sort"
 	"strings"
+	"text/template"
 )
 
-var applyFlag bool
+var (
+	applyFlag    bool
+	checkFlag    bool
+	suaveStdPath string
+	templatesDir string
+)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		runAPIDiff(os.Args[2:])
+		return
+	}
+
 	flag.BoolVar(&applyFlag, "apply", false, "write to file")
+	flag.BoolVar(&checkFlag, "check", false, "check that the generated files are up to date")
+	flag.StringVar(&suaveStdPath, "suave-std", resolvePath("../.."), "path to the suave std")
+	flag.StringVar(&templatesDir, "templates", "", "directory with user templates to render instead of the suave std files")
 	flag.Parse()
 
-	precompileNames, err := getPrecompileNames()
+	if applyFlag && checkFlag {
+		fmt.Println("the apply and check flags cannot be used together")
+		os.Exit(1)
+	}
+
+	// Suave.sol must be compiled with 'forge build' before running forge-gen
+	artifact, err := readArtifact(filepath.Join(suaveStdPath, "out", "Suave.sol", "Suave.json"))
 	if err != nil {
-		fmt.Printf("failed to get precompile names: %v\n", err)
+		fmt.Printf("failed to read Suave.sol artifact: %v\n", err)
 		os.Exit(1)
 	}
 
-	if err := applyTemplate(precompileNames); err != nil {
-		fmt.Printf("failed to apply template: %v\n", err)
+	lib, err := parseSuaveLib(artifact)
+	if err != nil {
+		fmt.Printf("failed to parse Suave.sol: %v\n", err)
 		os.Exit(1)
 	}
+
+	if lib.Provenance, err = readProvenance(suaveStdPath, artifact); err != nil {
+		fmt.Printf("failed to read the provenance of Suave.sol: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := resolveConnectors(lib.Precompiles); err != nil {
+		fmt.Printf("failed to resolve connectors: %v\n", err)
+		os.Exit(1)
+	}
+
+	files := generatedFiles
+	if templatesDir != "" {
+		if files, err = loadUserTemplates(templatesDir); err != nil {
+			fmt.Printf("failed to load templates: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	outdated := 0
+	for _, file := range files {
+		str, err := file.Render(lib)
+		if err != nil {
+			fmt.Printf("failed to generate %s: %v\n", file.Path, err)
+			os.Exit(1)
+		}
+
+		if checkFlag {
+			diff, err := checkFile(file, str)
+			if err != nil {
+				fmt.Printf("failed to check %s: %v\n", file.Path, err)
+				os.Exit(1)
+			}
+			if diff != "" {
+				fmt.Print(diff)
+				outdated++
+			}
+		} else if applyFlag {
+			path := file.outputPath()
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				fmt.Printf("failed to create the directory of %s: %v\n", file.Path, err)
+				os.Exit(1)
+			}
+			if err := os.WriteFile(path, []byte(str), 0644); err != nil {
+				fmt.Printf("failed to write %s: %v\n", file.Path, err)
+				os.Exit(1)
+			}
+		} else {
+			fmt.Println(str)
+		}
+	}
+
+	if outdated != 0 {
+		fmt.Printf("%d generated files are out of date, run forge-gen with the --apply flag\n", outdated)
+		os.Exit(1)
+	}
+}
+
+// defaultConnector is the contract etched in the address of any precompile
+// that is not listed in the connectors map.
+const defaultConnector = "Connector"
+
+// connectors maps the name of a precompile to the contract that implements
+// it in forge. The connector must live in 'src/forge/<Connector>.sol'.
+var connectors = map[string]string{
+	"CONFIDENTIAL_RETRIEVE": confStoreConnector,
+	"CONFIDENTIAL_STORE":    confStoreConnector,
+	"NEW_DATA_RECORD":       confStoreConnector,
+	"FETCH_DATA_RECORDS":    confStoreConnector,
+	"CONTEXT_GET":           "ContextConnector",
 }
 
-var templateFile = `// SPDX-License-Identifier: UNLICENSED
+// resolveConnectors assigns the forge connector to each of the precompiles.
+// It fails if the connectors map references a precompile that does not exist.
+func resolveConnectors(precompiles []*precompile) error {
+	found := map[string]bool{}
+
+	for _, p := range precompiles {
+		connector, ok := connectors[p.Name]
+		if !ok {
+			connector = defaultConnector
+		}
+		found[p.Name] = true
+
+		p.Connector = connector
+	}
+
+	missing := []string{}
+	for name := range connectors {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("connectors set for precompiles which are not found in Suave.sol: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+type generatedFile struct {
+	// Path is the output path, either absolute or relative to the suave std
+	Path   string
+	Render func(lib *suaveLib) (string, error)
+}
+
+// outputPath returns the path where the file is written.
+func (f generatedFile) outputPath() string {
+	if filepath.IsAbs(f.Path) {
+		return f.Path
+	}
+	return filepath.Join(suaveStdPath, f.Path)
+}
+
+var generatedFiles = []generatedFile{
+	{Path: "src/forge/SuaveAddrs.sol", Render: renderSolidity(suaveAddrsTemplate)},
+	{Path: "src/forge/Registry.sol", Render: renderSolidity(registryTemplate)},
+	{Path: "src/forge/MockRegistry.sol", Render: renderMockRegistry},
+	{Path: "src/forge/ConfidentialStoreConnector.sol", Render: renderConfidentialStoreConnector},
+	{Path: "src/forge/precompiles.json", Render: renderManifest},
+	{Path: "tools/suavelib/suavelib.go", Render: renderGoBindings},
+}
+
+var suaveAddrsTemplate = `// SPDX-License-Identifier: UNLICENSED
 // DO NOT edit this file. Code generated by forge-gen.
 pragma solidity ^0.8.8;
 
@@ -39,70 +180,122 @@ import "../suavelib/Suave.sol";
 
 library SuaveAddrs {
 	function getSuaveAddrs() external pure returns (address[] memory) {
-		address[] memory addrList = new address[]({{ len .PrecompileNames }});
-		{{range $indx, $elem := .PrecompileNames}}
-		addrList[{{ $indx }}] = Suave.{{ $elem}};
+		address[] memory addrList = new address[]({{ len .Precompiles }});
+		{{range $indx, $elem := .Precompiles}}
+		addrList[{{ $indx }}] = Suave.{{ $elem.Name }};
 		{{- end}}
 
 		return addrList;
 	}
 }`
 
-func applyTemplate(precompileNames []string) error {
-	t, err := template.New("template").Parse(templateFile)
-	if err != nil {
-		return err
+var registryTemplate = `// SPDX-License-Identifier: UNLICENSED
+// DO NOT edit this file. Code generated by forge-gen.
+pragma solidity ^0.8.8;
+
+import "../suavelib/Suave.sol";
+import "./ConfidentialStore.sol";
+{{- range .Connectors}}
+import "./{{ . }}.sol";
+{{- end}}
+
+interface registryVM {
+	function etch(address, bytes calldata) external;
+}
+
+library Registry {
+	registryVM constant vm = registryVM(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+	address public constant confidentialStoreAddr = 0x0101010101010101010101010101010101010101;
+
+	// sha256 hash of the Suave.sol file used to generate the registry
+	string public constant suaveSolHash = "{{ .Provenance.SuaveSolHash }}";
+	// suave-geth commit that Suave.sol was synced from (empty if unknown)
+	string public constant suaveGethCommit = "{{ .Provenance.SuaveGethCommit }}";
+
+	function enable() public {
+		// enable the confidential store
+		deployCodeTo(type(ConfidentialStore).creationCode, confidentialStoreAddr);
+
+		// enable the connector of each precompile
+		{{- range .Precompiles}}
+		vm.etch(Suave.{{ .Name }}, type({{ .Connector }}).runtimeCode);
+		{{- end}}
 	}
 
-	input := map[string]interface{}{
-		"PrecompileNames": precompileNames,
+	function deployCodeTo(bytes memory creationCode, address where) internal {
+		vm.etch(where, creationCode);
+		(bool success, bytes memory runtimeBytecode) = where.call("");
+		require(success, "StdCheats deployCodeTo(string,bytes,uint256,address): Failed to create runtime bytecode.");
+		vm.etch(where, runtimeBytecode);
 	}
+}`
 
-	var outputRaw bytes.Buffer
-	if err = t.Execute(&outputRaw, input); err != nil {
-		return err
+// checkFile compares the rendered content of a generated file with the
+// file on disk and returns the unified diff between both.
+func checkFile(file generatedFile, expected string) (string, error) {
+	path, err := filepath.Abs(file.outputPath())
+	if err != nil {
+		return "", err
 	}
 
-	str := outputRaw.String()
-	if str, err = formatSolidity(str); err != nil {
-		return err
+	// name the file relative to the suave std in the diff
+	root, err := filepath.Abs(suaveStdPath)
+	if err != nil {
+		return "", err
+	}
+	name, err := filepath.Rel(root, path)
+	if err != nil {
+		return "", err
+	}
+	fromName, toName := "a/"+name, "b/"+name
+	if strings.HasPrefix(name, "..") {
+		// the file is outside of the suave std (i.e. the output of a user template)
+		fromName, toName = path, path
 	}
 
-	if applyFlag {
-		if err := os.WriteFile(resolvePath("../../src/forge/SuaveAddrs.sol"), []byte(str), 0644); err != nil {
-			return err
-		}
-	} else {
-		fmt.Println(str)
+	current, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		fromName = "/dev/null"
+	} else if err != nil {
+		return "", err
 	}
-	return nil
+	if trimSpaceAtEOL(string(current)) == trimSpaceAtEOL(expected) {
+		return "", nil
+	}
+	return unifiedDiff(fromName, toName, string(current), expected), nil
 }
 
-func getPrecompileNames() ([]string, error) {
-	content, err := os.ReadFile("./src/suavelib/Suave.sol")
-	if err != nil {
-		return nil, err
-	}
+// renderSolidity renders a Solidity template and formats the output with 'forge fmt'.
+func renderSolidity(templateFile string) func(lib *suaveLib) (string, error) {
+	return func(lib *suaveLib) (string, error) {
+		t, err := template.New("template").Parse(templateFile)
+		if err != nil {
+			return "", err
+		}
 
-	addrRegexp := regexp.MustCompile(`constant\s+([A-Za-z_]\w*)\s+=`)
+		// unique and sorted list of the connector contracts to import
+		connectorsMap := map[string]struct{}{}
+		for _, p := range lib.Precompiles {
+			connectorsMap[p.Connector] = struct{}{}
+		}
+		connectorNames := []string{}
+		for name := range connectorsMap {
+			connectorNames = append(connectorNames, name)
+		}
+		sort.Strings(connectorNames)
 
-	matches := addrRegexp.FindAllStringSubmatch(string(content), -1)
+		input := map[string]interface{}{
+			"Precompiles": lib.Precompiles,
+			"Connectors":  connectorNames,
+			"Provenance":  lib.Provenance,
+		}
 
-	names := []string{}
-	for _, match := range matches {
-		if len(match) > 1 {
-			name := strings.TrimSpace(match[1])
-			if name == "ANYALLOWED" {
-				continue
-			}
-			if name == "CONFIDENTIAL_INPUTS" {
-				continue
-			}
-			names = append(names, name)
+		var outputRaw bytes.Buffer
+		if err = t.Execute(&outputRaw, input); err != nil {
+			return "", err
 		}
+		return formatSolidity(outputRaw.String())
 	}
-
-	return names, nil
 }
 
 func formatSolidity(code string) (string, error) {
@@ -137,6 +330,10 @@ func execForgeCommand(args []string, stdin string) (string, error) {
 }
 
 func resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
 	// Get the caller's file path.
 	_, filename, _, _ := runtime.Caller(1)
 
@@ -0,0 +1,501 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readTestSuaveLib(t *testing.T) *suaveLib {
+	t.Helper()
+
+	artifact, err := readArtifact("./testdata/Suave.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lib, err := parseSuaveLib(artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lib
+}
+
+func TestParseSuaveLib_Constants(t *testing.T) {
+	lib := readTestSuaveLib(t)
+
+	expected := map[string]constantKind{
+		"ANYALLOWED":            constantKindAddress,
+		"CONFIDENTIAL_INPUTS":   constantKindPrecompile,
+		"CONFIDENTIAL_RETRIEVE": constantKindPrecompile,
+		"DO_HTTPREQUEST2":       constantKindPrecompile,
+		"RANDOM_BYTES":          constantKindPrecompile,
+	}
+	if len(lib.Constants) != len(expected) {
+		t.Fatalf("expected %d constants but found %d", len(expected), len(lib.Constants))
+	}
+	for _, c := range lib.Constants {
+		if kind := expected[c.Name]; kind != c.Kind {
+			t.Fatalf("constant %s: expected kind %s but found %s", c.Name, kind, c.Kind)
+		}
+	}
+}
+
+func TestParseSuaveLib_Precompiles(t *testing.T) {
+	lib := readTestSuaveLib(t)
+
+	// CONFIDENTIAL_INPUTS is in the ignored precompiles
+	expected := []struct {
+		name, addr, function string
+	}{
+		{"CONFIDENTIAL_RETRIEVE", "0x0000000000000000000000000000000042020001", "confidentialRetrieve"},
+		{"DO_HTTPREQUEST2", "0x0000000000000000000000000000000043200003", "doHTTPRequest2"},
+		{"RANDOM_BYTES", "0x000000000000000000000000000000007770000b", "randomBytes"},
+	}
+	if len(lib.Precompiles) != len(expected) {
+		t.Fatalf("expected %d precompiles but found %d", len(expected), len(lib.Precompiles))
+	}
+	for indx, p := range lib.Precompiles {
+		if p.Name != expected[indx].name || p.Address != expected[indx].addr || p.Function.Name != expected[indx].function {
+			t.Fatalf("unexpected precompile %d: %s %s %s", indx, p.Name, p.Address, p.Function.Name)
+		}
+	}
+}
+
+func TestParseSuaveLib_Function(t *testing.T) {
+	lib := readTestSuaveLib(t)
+
+	cases := []struct {
+		function string
+		inputs   []string
+		outputs  []string
+		encoding outputEncoding
+	}{
+		{"confidentialRetrieve", []string{"dataId bytes16 Suave.DataId", "key string string"}, []string{"value bytes bytes"}, outputEncodingRaw},
+		{"doHTTPRequest2", []string{"request tuple struct Suave.HttpRequest"}, []string{"httpResponse tuple struct Suave.HttpResponse"}, outputEncodingABI},
+		{"randomBytes", []string{"numBytes uint8 uint8"}, []string{"value bytes bytes"}, outputEncodingABI},
+	}
+
+	format := func(args []*abiArgument) []string {
+		res := []string{}
+		for _, arg := range args {
+			res = append(res, arg.Name+" "+arg.Type+" "+arg.InternalType)
+		}
+		return res
+	}
+
+	for indx, c := range cases {
+		fn := lib.Precompiles[indx].Function
+		if fn.Name != c.function {
+			t.Fatalf("expected function %s but found %s", c.function, fn.Name)
+		}
+		if !reflect.DeepEqual(format(fn.Inputs), c.inputs) {
+			t.Fatalf("%s: unexpected inputs %v", fn.Name, format(fn.Inputs))
+		}
+		if !reflect.DeepEqual(format(fn.Outputs), c.outputs) {
+			t.Fatalf("%s: unexpected outputs %v", fn.Name, format(fn.Outputs))
+		}
+		if fn.OutputEncoding != c.encoding {
+			t.Fatalf("%s: unexpected output encoding %s", fn.Name, fn.OutputEncoding)
+		}
+	}
+
+	// the struct components are resolved recursively
+	request := lib.Precompiles[1].Function.Inputs[0]
+	if len(request.Components) != 6 || request.Components[2].Name != "headers" || request.Components[2].Type != "string[]" {
+		t.Fatalf("unexpected components of HttpRequest")
+	}
+}
+
+func TestResolveConnectors(t *testing.T) {
+	precompiles := []*precompile{
+		{Name: "CONFIDENTIAL_RETRIEVE"},
+		{Name: "RANDOM_BYTES"},
+	}
+	if err := resolveConnectors(precompiles); err == nil {
+		t.Fatal("expected error for connectors of precompiles not in Suave.sol")
+	}
+
+	for name := range connectors {
+		precompiles = append(precompiles, &precompile{Name: name})
+	}
+	if err := resolveConnectors(precompiles); err != nil {
+		t.Fatal(err)
+	}
+	if precompiles[0].Connector != "ConfidentialStoreConnector" {
+		t.Fatalf("unexpected connector %s", precompiles[0].Connector)
+	}
+	if precompiles[1].Connector != defaultConnector {
+		t.Fatalf("unexpected connector %s", precompiles[1].Connector)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	to := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+
+	expected := `--- a/file
++++ b/file
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -9,3 +9,4 @@
+ i
+ j
+ k
++l
+`
+	if diff := unifiedDiff("a/file", "b/file", from, to); diff != expected {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+	if diff := unifiedDiff("a/file", "b/file", from, from); diff != "" {
+		t.Fatalf("expected no diff but found:\n%s", diff)
+	}
+
+	expected = `--- /dev/null
++++ b/file
+@@ -0,0 +1,2 @@
++a
++b
+`
+	if diff := unifiedDiff("/dev/null", "b/file", "", "a\nb\n"); diff != expected {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+
+	// the missing newline at the end of the file is a difference
+	expected = `--- a/file
++++ b/file
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`
+	if diff := unifiedDiff("a/file", "b/file", "a\nb", "a\nb\n"); diff != expected {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+}
+
+func TestTrimSpaceAtEOL(t *testing.T) {
+	if trimSpaceAtEOL("a \r\nb\t\n") != trimSpaceAtEOL("a\nb\n") {
+		t.Fatal("expected the spaces at the end of the lines to be ignored")
+	}
+	if trimSpaceAtEOL("a\nb") == trimSpaceAtEOL("a\nb\n") {
+		t.Fatal("expected the missing newline at the end of the file to be a difference")
+	}
+}
+
+func TestRenderManifest(t *testing.T) {
+	lib := readTestSuaveLib(t)
+	// the error is ignored since the test library only has some of the precompiles with a connector
+	resolveConnectors(lib.Precompiles)
+
+	data, err := renderManifest(lib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m manifest
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	found := []string{}
+	for _, p := range m.Precompiles {
+		found = append(found, fmt.Sprintf("%s %s %s %s", p.Name, p.Address, p.Connector, p.Signature))
+	}
+	expected := []string{
+		"CONFIDENTIAL_RETRIEVE 0x0000000000000000000000000000000042020001 ConfidentialStoreConnector confidentialRetrieve(bytes16,string)",
+		"DO_HTTPREQUEST2 0x0000000000000000000000000000000043200003 Connector doHTTPRequest2((string,string,string[],bytes,bool,uint64))",
+		"RANDOM_BYTES 0x000000000000000000000000000000007770000b Connector randomBytes(uint8)",
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Fatalf("unexpected precompiles %v", found)
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	defer func(path string) { suaveStdPath = path }(suaveStdPath)
+	suaveStdPath = t.TempDir()
+
+	// the output is resolved and named relative to the suave std
+	file := generatedFile{Path: "src/forge/Registry.sol"}
+	if err := os.MkdirAll(filepath.Join(suaveStdPath, "src", "forge"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(suaveStdPath, file.Path), []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := checkFile(file, "a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+
+	diff, err = checkFile(file, "b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(diff, "--- a/src/forge/Registry.sol\n+++ b/src/forge/Registry.sol\n") {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+}
+
+func TestGoType(t *testing.T) {
+	cases := []struct {
+		typ, internalType, goTyp string
+	}{
+		{"address", "address", "common.Address"},
+		{"bytes", "bytes", "[]byte"},
+		{"bytes32[]", "bytes32[]", "[][32]byte"},
+		{"uint64", "uint64", "uint64"},
+		{"uint256", "uint256", "*big.Int"},
+		{"bytes16", "Suave.DataId", "DataId"},
+		{"uint8", "enum Suave.CryptoSignature", "CryptoSignature"},
+		{"tuple[]", "struct Suave.Withdrawal[]", "[]Withdrawal"},
+		{"tuple[2][]", "struct Suave.Withdrawal[2][]", "[][2]Withdrawal"},
+	}
+	for _, c := range cases {
+		goTyp, err := goType(&abiArgument{Type: c.typ, InternalType: c.internalType})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if goTyp != c.goTyp {
+			t.Fatalf("%s: expected %s but found %s", c.typ, c.goTyp, goTyp)
+		}
+	}
+}
+
+func TestSolType(t *testing.T) {
+	cases := []struct {
+		internalType, solTyp, location string
+	}{
+		{"address", "address", ""},
+		{"bytes", "bytes", "memory"},
+		{"address[]", "address[]", "memory"},
+		{"Suave.DataId", "Suave.DataId", ""},
+		{"enum Suave.CryptoSignature", "Suave.CryptoSignature", ""},
+		{"struct Suave.HttpRequest", "Suave.HttpRequest", "memory"},
+		{"struct Suave.DataRecord[]", "Suave.DataRecord[]", "memory"},
+	}
+	for _, c := range cases {
+		solTyp, location, err := solType(&abiArgument{Name: "arg", InternalType: c.internalType})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if solTyp != c.solTyp || location != c.location {
+			t.Fatalf("%s: expected '%s %s' but found '%s %s'", c.internalType, c.solTyp, c.location, solTyp, location)
+		}
+	}
+}
+
+func TestUserTemplates(t *testing.T) {
+	lib := readTestSuaveLib(t)
+
+	files, err := loadUserTemplates("./testdata/templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected one template but found %d", len(files))
+	}
+	if !strings.HasSuffix(files[0].Path, filepath.Join("testdata", "templates", "out", "addresses.ts")) {
+		t.Fatalf("unexpected output path %s", files[0].Path)
+	}
+
+	output, err := files[0].Render(lib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, `export const RandomBytesAddr = "0x000000000000000000000000000000007770000b";`) {
+		t.Fatalf("unexpected output:\n%s", output)
+	}
+}
+
+func TestUserTemplates_MissingOutput(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.tmpl"), []byte("{{len .Precompiles}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, templatesConfigFile), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadUserTemplates(dir); err == nil {
+		t.Fatal("expected an error for a template without output path")
+	}
+}
+
+func TestAPIDiff(t *testing.T) {
+	from := readTestSuaveLib(t)
+	// the test library only has some of the precompiles with a connector
+	err := resolveConnectors(from.Precompiles)
+	expectedErr := "connectors set for precompiles which are not found in Suave.sol: CONFIDENTIAL_STORE, CONTEXT_GET, FETCH_DATA_RECORDS, NEW_DATA_RECORD"
+	if err == nil || err.Error() != expectedErr {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	artifact, err := readArtifact("./testdata/SuaveV2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := parseSuaveLib(artifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := compareSuaveLibs(from, to)
+
+	found := []string{}
+	for _, change := range changes {
+		found = append(found, fmt.Sprintf("%s %s %s", change.Kind, change.Item, change.Name))
+	}
+	expected := []string{
+		"Removed precompile CONFIDENTIAL_RETRIEVE",
+		"Added precompile GET_INSECURE_TIME",
+		"Changed precompile RANDOM_BYTES",
+		"Removed function confidentialRetrieve",
+		"Added function getInsecureTime",
+		"Changed struct HttpRequest",
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Fatalf("unexpected changes %v", found)
+	}
+
+	usages := map[string][]*suaveUsage{
+		"doHTTPRequest2": {{File: "src/Gateway.sol", Line: 10, Name: "doHTTPRequest2"}},
+		"randomBytes":    {{File: "src/Random.sol", Line: 20, Name: "randomBytes"}},
+		"RANDOM_BYTES":   {{File: "src/Random.sol", Line: 21, Name: "RANDOM_BYTES"}},
+	}
+	markBreakingChanges(changes, from, usages)
+
+	breaking := map[string]int{}
+	for _, change := range changes {
+		breaking[change.Name] = len(change.Usages)
+	}
+	// the struct is used by doHTTPRequest2 and a new address of RANDOM_BYTES is not breaking
+	if breaking["HttpRequest"] != 1 || breaking["RANDOM_BYTES"] != 0 || breaking["GET_INSECURE_TIME"] != 0 {
+		t.Fatalf("unexpected breaking changes %v", breaking)
+	}
+
+	report := renderAPIDiff("a", "b", changes, []string{"`a`: " + expectedErr})
+	for _, line := range []string{
+		"- `a`: " + expectedErr,
+		"- Changed struct `HttpRequest`, used in:",
+		"  - `src/Gateway.sol:10` (`Suave.doHTTPRequest2`)",
+		"| Changed | `RANDOM_BYTES` | `0x000000000000000000000000000000007770000b` → `0x000000000000000000000000000000007770000d` |",
+		"+    uint8 retries;",
+	} {
+		if !strings.Contains(report, line+"\n") {
+			t.Fatalf("line '%s' not found in report:\n%s", line, report)
+		}
+	}
+}
+
+func TestMarkBreakingChanges_NestedStruct(t *testing.T) {
+	withdrawals := &abiArgument{Name: "withdrawals", Type: "tuple[]", InternalType: "struct Suave.Withdrawal[]"}
+	from := &suaveLib{
+		Structs: []*structDef{
+			{Name: "Withdrawal", Fields: []*abiArgument{{Name: "index", Type: "uint64", InternalType: "uint64"}}},
+			{Name: "BuildBlockArgs", Fields: []*abiArgument{withdrawals}},
+		},
+		Precompiles: []*precompile{{
+			Name:      "BUILD_ETH_BLOCK",
+			Connector: defaultConnector,
+			Function: &function{Name: "buildEthBlock", Inputs: []*abiArgument{
+				{Name: "blockArgs", Type: "tuple", InternalType: "struct Suave.BuildBlockArgs", Components: []*abiArgument{withdrawals}},
+			}},
+		}},
+	}
+
+	changes := []*apiChange{{Kind: apiChangeChanged, Item: "struct", Name: "Withdrawal"}}
+	usages := map[string][]*suaveUsage{
+		"BuildBlockArgs": {{File: "src/Builder.sol", Line: 5, Name: "BuildBlockArgs"}},
+		"buildEthBlock":  {{File: "src/Builder.sol", Line: 12, Name: "buildEthBlock"}},
+	}
+	markBreakingChanges(changes, from, usages)
+
+	// the change is breaking for the struct that contains it and the function that uses it
+	found := []string{}
+	for _, usage := range changes[0].Usages {
+		found = append(found, fmt.Sprintf("%s:%d", usage.File, usage.Line))
+	}
+	if !reflect.DeepEqual(found, []string{"src/Builder.sol:5", "src/Builder.sol:12"}) {
+		t.Fatalf("unexpected usages %v", found)
+	}
+}
+
+func TestResolveConfStoreCall(t *testing.T) {
+	lib := readTestSuaveLib(t)
+
+	var retrieve *precompile
+	for _, p := range lib.Precompiles {
+		if p.Name == "CONFIDENTIAL_RETRIEVE" {
+			retrieve = p
+		}
+	}
+
+	dataID := &abiArgument{Name: "dataId", Type: "bytes16", InternalType: "Suave.DataId"}
+	key := &abiArgument{Name: "key", Type: "string", InternalType: "string"}
+	sender := &abiArgument{Name: "sender", Type: "address", InternalType: "address"}
+	output := []*abiArgument{{Type: "bytes", InternalType: "bytes"}}
+
+	storeAbi := []*abiEntry{
+		{Type: "function", Name: "confidentialRetrieve", Inputs: []*abiArgument{dataID, key}, Outputs: output},
+		{Type: "function", Name: "confidentialRetrieve", Inputs: []*abiArgument{dataID, key, sender}, Outputs: output},
+	}
+	call, err := resolveConfStoreCall(retrieve, storeAbi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Signature != "confidentialRetrieve(bytes16,string,address)" || !call.WithSender || !call.UnwrapOutput {
+		t.Fatalf("unexpected call %+v", call)
+	}
+
+	// without the sender
+	call, err = resolveConfStoreCall(retrieve, storeAbi[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Signature != "confidentialRetrieve(bytes16,string)" || call.WithSender {
+		t.Fatalf("unexpected call %+v", call)
+	}
+
+	// the signature does not match
+	storeAbi = []*abiEntry{
+		{Type: "function", Name: "confidentialRetrieve", Inputs: []*abiArgument{key, dataID}, Outputs: output},
+	}
+	if _, err := resolveConfStoreCall(retrieve, storeAbi); err == nil {
+		t.Fatal("expected an error for a method with a different signature")
+	}
+
+	// the outputs do not match
+	storeAbi = []*abiEntry{
+		{Type: "function", Name: "confidentialRetrieve", Inputs: []*abiArgument{dataID, key}, Outputs: []*abiArgument{key}},
+	}
+	if _, err := resolveConfStoreCall(retrieve, storeAbi); err == nil {
+		t.Fatal("expected an error for a method with different outputs")
+	}
+}
+
+func TestCanonicalType(t *testing.T) {
+	arg := &abiArgument{
+		Type: "tuple[]",
+		Components: []*abiArgument{
+			{Type: "bytes16"},
+			{Type: "address[]"},
+			{Type: "tuple", Components: []*abiArgument{{Type: "uint64"}}},
+		},
+	}
+	if typ := arg.canonicalType(); typ != "(bytes16,address[],(uint64))[]" {
+		t.Fatalf("unexpected canonical type %s", typ)
+	}
+}
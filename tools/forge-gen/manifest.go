@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// manifest is the machine-readable list of the Suave precompiles.
+type manifest struct {
+	Precompiles []*manifestPrecompile `json:"precompiles"`
+}
+
+type manifestPrecompile struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	// Connector is the contract that serves the precompile in forge
+	Connector string `json:"connector"`
+	Function  string `json:"function"`
+	// Signature is the signature of the function in Suave.sol (i.e. 'randomBytes(uint8)')
+	Signature   string         `json:"signature"`
+	Description string         `json:"description"`
+	Inputs      []*abiArgument `json:"inputs"`
+	Outputs     []*abiArgument `json:"outputs"`
+
+	// OutputEncoding is 'raw' if the output of the precompile is not abi encoded
+	OutputEncoding outputEncoding `json:"outputEncoding"`
+}
+
+func renderManifest(lib *suaveLib) (string, error) {
+	m := &manifest{
+		Precompiles: []*manifestPrecompile{},
+	}
+	for _, p := range lib.Precompiles {
+		m.Precompiles = append(m.Precompiles, &manifestPrecompile{
+			Name:           p.Name,
+			Address:        strings.ToLower(p.Address),
+			Connector:      p.Connector,
+			Function:       p.Function.Name,
+			Signature:      fmt.Sprintf("%s(%s)", p.Function.Name, canonicalTypes(p.Function.Inputs)),
+			Description:    p.Function.Description,
+			Inputs:         p.Function.Inputs,
+			Outputs:        p.Function.Outputs,
+			OutputEncoding: p.Function.OutputEncoding,
+		})
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+type solMock struct {
+	Name         string
+	FuncName     string
+	ContractName string
+	Inputs       []*solArg
+	Outputs      []*solArg
+	RawOutput    bool
+	// DefaultReturn are the outputs returned by the mock when it is enabled (if any)
+	DefaultReturn string
+}
+
+// mockDefaults are the outputs that the mocks return until the test programs
+// another response, by precompile name.
+var mockDefaults = map[string]string{
+	// the code runs in a confidential context, like with suave-geth
+	"IS_CONFIDENTIAL_ADDR": "true",
+}
+
+type solArg struct {
+	Name string
+	// Type is the type used to decode the argument with abi.decode
+	Type string
+	// Location is the data location of the argument (if any)
+	Location string
+}
+
+// Decl returns the declaration of the argument as a function parameter.
+func (s *solArg) Decl() string {
+	if s.Location == "" {
+		return s.Type + " " + s.Name
+	}
+	return s.Type + " " + s.Location + " " + s.Name
+}
+
+func renderMockRegistry(lib *suaveLib) (string, error) {
+	mocks := []*solMock{}
+	for _, p := range lib.Precompiles {
+		fn := p.Function
+
+		mock := &solMock{
+			Name:         p.Name,
+			FuncName:     fn.Name,
+			ContractName: toCamelCase(fn.Name) + "Mock",
+			RawOutput:    fn.OutputEncoding == outputEncodingRaw,
+		}
+		mock.DefaultReturn = mockDefaults[p.Name]
+
+		var err error
+		if mock.Inputs, err = solArgs(fn.Inputs); err != nil {
+			return "", fmt.Errorf("function %s: %v", fn.Name, err)
+		}
+		if mock.Outputs, err = solArgs(fn.Outputs); err != nil {
+			return "", fmt.Errorf("function %s: %v", fn.Name, err)
+		}
+		mocks = append(mocks, mock)
+	}
+
+	t, err := template.New("template").Parse(mockRegistryTemplate)
+	if err != nil {
+		return "", err
+	}
+	var outputRaw bytes.Buffer
+	if err = t.Execute(&outputRaw, map[string]interface{}{"Mocks": mocks}); err != nil {
+		return "", err
+	}
+	return formatSolidity(outputRaw.String())
+}
+
+func solArgs(args []*abiArgument) ([]*solArg, error) {
+	res := []*solArg{}
+	for _, arg := range args {
+		typ, location, err := solType(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s: %v", arg.Name, err)
+		}
+		res = append(res, &solArg{Name: arg.Name, Type: typ, Location: location})
+	}
+	return res, nil
+}
+
+// solType returns the Solidity type of an ABI argument in a contract that
+// imports Suave.sol and its data location as a function parameter.
+func solType(arg *abiArgument) (string, string, error) {
+	if arg.Name == "" {
+		return "", "", fmt.Errorf("unnamed argument")
+	}
+
+	typ := arg.InternalType
+	typ = strings.TrimPrefix(typ, "struct ")
+	typ = strings.TrimPrefix(typ, "enum ")
+
+	// structs, arrays, strings and bytes are reference types
+	if strings.HasPrefix(arg.InternalType, "struct ") || strings.HasSuffix(typ, "]") || typ == "string" || typ == "bytes" {
+		return typ, "memory", nil
+	}
+	return typ, "", nil
+}
+
+var mockRegistryTemplate = `// SPDX-License-Identifier: UNLICENSED
+// DO NOT edit this file. Code generated by forge-gen.
+pragma solidity ^0.8.8;
+
+import "../suavelib/Suave.sol";
+import "./PrecompileMock.sol";
+
+interface mockRegistryVM {
+	function etch(address, bytes calldata) external;
+}
+
+// MockRegistry replaces each precompile with a mock that returns the responses
+// programmed by the test. Unlike Registry, it does not require suave-geth or ffi.
+library MockRegistry {
+	mockRegistryVM constant vm = mockRegistryVM(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+	function enable() public {
+		{{- range .Mocks}}
+		vm.etch(Suave.{{ .Name }}, type({{ .ContractName }}).runtimeCode);
+		{{- end}}
+		{{- range .Mocks}}
+		{{- if .DefaultReturn}}
+		{{ .FuncName }}().mockReturn({{ .DefaultReturn }});
+		{{- end}}
+		{{- end}}
+	}
+	{{- range .Mocks}}
+
+	function {{ .FuncName }}() internal pure returns ({{ .ContractName }}) {
+		return {{ .ContractName }}(Suave.{{ .Name }});
+	}
+	{{- end}}
+}
+{{- range .Mocks}}
+{{- $mock := .}}
+
+contract {{ .ContractName }} is PrecompileMock {
+	function precompileName() internal pure override returns (string memory) {
+		return "{{ .Name }}";
+	}
+	{{- if .Outputs}}
+
+	function mockReturn({{range $indx, $arg := .Outputs}}{{if $indx}}, {{end}}{{$arg.Decl}}{{end}}) public {
+		setDefaultResponse({{template "output" $mock}});
+	}
+
+	function mockReturnAt(uint256 callIndex{{range .Outputs}}, {{.Decl}}{{end}}) public {
+		setResponse(callIndex, {{template "output" $mock}});
+	}
+	{{- else}}
+
+	function hasOutputs() internal pure override returns (bool) {
+		return false;
+	}
+	{{- end}}
+
+	function expectCall({{range $indx, $arg := .Inputs}}{{if $indx}}, {{end}}{{$arg.Decl}}{{end}}) public {
+		addExpectedCall(abi.encode({{range $indx, $arg := .Inputs}}{{if $indx}}, {{end}}{{$arg.Name}}{{end}}));
+	}
+	{{- if .Inputs}}
+
+	function callAt(uint256 callIndex) public view returns ({{range $indx, $arg := .Inputs}}{{if $indx}}, {{end}}{{$arg.Decl}}{{end}}) {
+		({{range $indx, $arg := .Inputs}}{{if $indx}}, {{end}}{{$arg.Name}}{{end}}) = abi.decode(inputAt(callIndex), ({{range $indx, $arg := .Inputs}}{{if $indx}}, {{end}}{{$arg.Type}}{{end}}));
+	}
+	{{- end}}
+}
+{{- end}}
+
+{{- define "output"}}
+{{- if .RawOutput}}{{(index .Outputs 0).Name}}{{else}}abi.encode({{range $indx, $arg := .Outputs}}{{if $indx}}, {{end}}{{$arg.Name}}{{end}}){{end}}
+{{- end}}`
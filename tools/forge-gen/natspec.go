@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+)
+
+// natSpec is the documentation of a declaration in Suave.sol.
+// Only the tags used by forge-gen are decoded.
+type natSpec struct {
+	Notice string
+	Param  []natSpecValue
+	Return []natSpecValue
+}
+
+type natSpecValue struct {
+	Name, Description string
+}
+
+func parseNatSpec(txt string) *natSpec {
+	spec := &natSpec{
+		Param:  []natSpecValue{},
+		Return: []natSpecValue{},
+	}
+
+	for _, line := range strings.Split(txt, "\n") {
+		tag, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+		rest = strings.TrimSpace(rest)
+
+		switch tag {
+		case "@notice":
+			spec.Notice = rest
+		case "@param", "@return":
+			name, description, _ := strings.Cut(rest, " ")
+			val := natSpecValue{Name: name, Description: strings.TrimSpace(description)}
+			if tag == "@param" {
+				spec.Param = append(spec.Param, val)
+			} else {
+				spec.Return = append(spec.Return, val)
+			}
+		}
+	}
+	return spec
+}
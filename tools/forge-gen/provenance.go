@@ -0,0 +1,42 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// suaveGethCommitFile is the file (relative to the suave std) with the commit
+// of suave-geth that Suave.sol was synced from. It is written by the
+// suave-lib-sync workflow.
+const suaveGethCommitFile = "src/suavelib/SUAVE_GETH_COMMIT"
+
+// provenance identifies the version of Suave.sol used to generate the files.
+type provenance struct {
+	// SuaveSolHash is the sha256 hash of Suave.sol
+	SuaveSolHash string
+	// SuaveGethCommit is the suave-geth commit Suave.sol was synced from.
+	// It is empty if it is not known.
+	SuaveGethCommit string
+}
+
+// readProvenance computes the provenance of the Suave.sol file compiled in the artifact.
+func readProvenance(suaveStdPath string, artifact *artifact) (*provenance, error) {
+	src, err := os.ReadFile(filepath.Join(suaveStdPath, artifact.Ast.AbsolutePath))
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(src)
+
+	commit, err := os.ReadFile(filepath.Join(suaveStdPath, suaveGethCommitFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return &provenance{
+		SuaveSolHash:    hex.EncodeToString(hash[:]),
+		SuaveGethCommit: strings.TrimSpace(string(commit)),
+	}, nil
+}
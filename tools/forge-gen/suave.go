@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ignoredPrecompiles are the address constants in the precompile range of Suave.sol
+// that must not be etched in forge. The value is the reason why it is ignored.
+var ignoredPrecompiles = map[string]string{
+	"CONFIDENTIAL_INPUTS": "deprecated, the confidential inputs are resolved with the CONTEXT_GET precompile",
+}
+
+// precompileRangeStart is the lowest address considered a Suave precompile. Anything
+// below is an Ethereum precompile.
+var precompileRangeStart = big.NewInt(0x10000)
+
+// precompileRangeEnd is the upper (exclusive) bound of the Suave precompile addresses.
+// Any address above (i.e. Suave.ANYALLOWED) is a regular address.
+var precompileRangeEnd = new(big.Int).Lsh(big.NewInt(1), 32)
+
+type constantKind string
+
+const (
+	// constantKindPrecompile is an address constant in the precompile range
+	constantKindPrecompile constantKind = "precompile"
+	// constantKindAddress is an address constant outside of the precompile range
+	constantKindAddress constantKind = "address"
+	// constantKindOther is a constant of any type other than address
+	constantKindOther constantKind = "other"
+)
+
+// constant is a constant declared in Suave.sol
+type constant struct {
+	ID    int64
+	Name  string
+	Type  string
+	Value string
+	Kind  constantKind
+}
+
+// suaveLib is the model of the Suave.sol library.
+type suaveLib struct {
+	Constants   []*constant
+	Precompiles []*precompile
+	Structs     []*structDef
+	Enums       []*enumDef
+	UserTypes   []*userTypeDef
+
+	// Provenance is the version of Suave.sol. It is not part of the artifact
+	// and it is set by the caller.
+	Provenance *provenance
+}
+
+type structDef struct {
+	Name        string
+	Description string
+	Fields      []*abiArgument
+
+	// FieldDescriptions are the natspec descriptions of the fields by name
+	FieldDescriptions map[string]string
+}
+
+type enumDef struct {
+	Name   string
+	Values []string
+}
+
+// userTypeDef is a user defined value type (i.e. 'type DataId is bytes16')
+type userTypeDef struct {
+	Name       string
+	Underlying string
+}
+
+type precompile struct {
+	Name    string
+	Address string
+
+	// Function is the function in Suave.sol that calls the precompile
+	Function *function
+
+	// Connector is the contract that serves the precompile in forge
+	Connector string
+}
+
+type function struct {
+	Name        string
+	Description string
+	Inputs      []*abiArgument
+	Outputs     []*abiArgument
+
+	// OutputEncoding is how the function decodes the output of the precompile
+	OutputEncoding outputEncoding
+}
+
+type outputEncoding string
+
+const (
+	// outputEncodingABI is an output abi encoded with the types of the function outputs
+	outputEncodingABI outputEncoding = "abi"
+	// outputEncodingRaw is an output returned as is by the function as 'bytes'
+	outputEncodingRaw outputEncoding = "raw"
+)
+
+// parseSuaveLib builds the model of the Suave library from its compiler artifact.
+func parseSuaveLib(artifact *artifact) (*suaveLib, error) {
+	contracts := artifact.Ast.Filter(func(node *astNode) bool {
+		return node.NodeType == "ContractDefinition" && node.Name == "Suave"
+	})
+	if len(contracts) != 1 {
+		return nil, fmt.Errorf("expected one 'Suave' contract in %s but found %d", artifact.Ast.AbsolutePath, len(contracts))
+	}
+	suave := contracts[0]
+
+	lib := &suaveLib{}
+	resolver := newTypeResolver(suave)
+
+	// classify the constants
+	constantsByID := map[int64]*constant{}
+	for _, node := range suave.Nodes {
+		if node.NodeType != "VariableDeclaration" || !node.Constant {
+			continue
+		}
+		c := &constant{
+			ID:   node.ID,
+			Name: node.Name,
+			Type: node.typeString(),
+		}
+		if value, ok := node.literalValue(); ok {
+			c.Value = value
+		}
+
+		c.Kind = constantKindOther
+		if c.Type == "address" {
+			c.Kind = constantKindAddress
+
+			addr, ok := new(big.Int).SetString(strings.TrimPrefix(strings.ToLower(c.Value), "0x"), 16)
+			if !ok {
+				return nil, fmt.Errorf("address constant '%s' does not have a literal value", c.Name)
+			}
+			if addr.Cmp(precompileRangeStart) >= 0 && addr.Cmp(precompileRangeEnd) < 0 {
+				c.Kind = constantKindPrecompile
+			}
+		}
+
+		lib.Constants = append(lib.Constants, c)
+		constantsByID[c.ID] = c
+	}
+
+	// parse the type declarations
+	for _, node := range suave.Nodes {
+		switch node.NodeType {
+		case "StructDefinition":
+			spec := parseNatSpec(node.docs())
+			fields, err := resolver.resolveArguments(node.Members)
+			if err != nil {
+				return nil, fmt.Errorf("struct %s: %v", node.Name, err)
+			}
+			def := &structDef{
+				Name:              node.Name,
+				Description:       spec.Notice,
+				Fields:            fields,
+				FieldDescriptions: map[string]string{},
+			}
+			for _, param := range spec.Param {
+				def.FieldDescriptions[param.Name] = param.Description
+			}
+			lib.Structs = append(lib.Structs, def)
+
+		case "EnumDefinition":
+			def := &enumDef{Name: node.Name}
+			for _, member := range node.Members {
+				def.Values = append(def.Values, member.Name)
+			}
+			lib.Enums = append(lib.Enums, def)
+
+		case "UserDefinedValueTypeDefinition":
+			underlying, err := resolver.resolve(node.UnderlyingType)
+			if err != nil {
+				return nil, fmt.Errorf("user defined type %s: %v", node.Name, err)
+			}
+			lib.UserTypes = append(lib.UserTypes, &userTypeDef{Name: node.Name, Underlying: underlying.Type})
+		}
+	}
+
+	// link each precompile with the function that calls it
+	wrappers := map[int64]*function{}
+	for _, node := range suave.Nodes {
+		if node.NodeType != "FunctionDefinition" {
+			continue
+		}
+		called, err := calledConstants(node)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range called {
+			c, ok := constantsByID[id]
+			if !ok || c.Kind != constantKindPrecompile {
+				continue
+			}
+			if prev, ok := wrappers[id]; ok {
+				return nil, fmt.Errorf("precompile '%s' is called by both '%s' and '%s'", c.Name, prev.Name, node.Name)
+			}
+			fn, err := parseFunction(resolver, node)
+			if err != nil {
+				return nil, err
+			}
+			wrappers[id] = fn
+		}
+	}
+
+	for _, c := range lib.Constants {
+		if c.Kind != constantKindPrecompile {
+			continue
+		}
+		if _, ok := ignoredPrecompiles[c.Name]; ok {
+			continue
+		}
+		fn, ok := wrappers[c.ID]
+		if !ok {
+			return nil, fmt.Errorf("address '%s' is in the precompile range but no function calls it, add it to the ignored precompiles if it is not a precompile", c.Name)
+		}
+		lib.Precompiles = append(lib.Precompiles, &precompile{
+			Name:     c.Name,
+			Address:  c.Value,
+			Function: fn,
+		})
+	}
+
+	for name := range ignoredPrecompiles {
+		if _, ok := lib.findConstant(name); !ok {
+			return nil, fmt.Errorf("ignored precompile '%s' not found in Suave.sol", name)
+		}
+	}
+	return lib, nil
+}
+
+func (s *suaveLib) findConstant(name string) (*constant, bool) {
+	for _, c := range s.Constants {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// calledConstants returns the declarations on which the function body
+// performs a low level '<addr>.call'.
+func calledConstants(node *astNode) ([]int64, error) {
+	ids := []int64{}
+	err := walkRaw(node.Body, func(obj map[string]interface{}) {
+		if obj["nodeType"] != "MemberAccess" || obj["memberName"] != "call" {
+			return
+		}
+		expr, ok := obj["expression"].(map[string]interface{})
+		if !ok || expr["nodeType"] != "Identifier" {
+			return
+		}
+		if ref, ok := expr["referencedDeclaration"].(float64); ok {
+			ids = append(ids, int64(ref))
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk the body of %s: %v", node.Name, err)
+	}
+	return ids, nil
+}
+
+func parseFunction(resolver *typeResolver, node *astNode) (*function, error) {
+	spec := parseNatSpec(node.docs())
+
+	inputs, err := node.inputParameters()
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := node.outputParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	fn := &function{
+		Name:           node.Name,
+		Description:    spec.Notice,
+		OutputEncoding: outputEncodingABI,
+	}
+	if fn.Inputs, err = resolver.resolveArguments(inputs); err != nil {
+		return nil, fmt.Errorf("function %s: %v", node.Name, err)
+	}
+	if fn.Outputs, err = resolver.resolveArguments(outputs); err != nil {
+		return nil, fmt.Errorf("function %s: %v", node.Name, err)
+	}
+
+	// the return values in Suave.sol are not named, use the name in the natspec
+	for indx, output := range fn.Outputs {
+		if output.Name == "" && indx < len(spec.Return) {
+			output.Name = spec.Return[indx].Name
+		}
+	}
+
+	// a function that returns the variable with the output of the call
+	// (instead of 'abi.decode') returns the raw output of the precompile
+	err = walkRaw(node.Body, func(obj map[string]interface{}) {
+		if obj["nodeType"] != "Return" {
+			return
+		}
+		if expr, ok := obj["expression"].(map[string]interface{}); ok && expr["nodeType"] == "Identifier" {
+			fn.OutputEncoding = outputEncodingRaw
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk the body of %s: %v", node.Name, err)
+	}
+	if fn.OutputEncoding == outputEncodingRaw {
+		if len(fn.Outputs) != 1 || fn.Outputs[0].Type != "bytes" {
+			return nil, fmt.Errorf("function %s returns the raw output of the precompile but its output is not 'bytes'", node.Name)
+		}
+	}
+	return fn, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// templatesConfigFile is the file in the templates directory that maps
+// the name of each template to its output path.
+const templatesConfigFile = "templates.json"
+
+// templateFuncs are the functions available in the user templates.
+var templateFuncs = template.FuncMap{
+	"camelCase": toCamelCase,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"solType": func(arg *abiArgument) (string, error) {
+		typ, location, err := solType(arg)
+		if err != nil || location == "" {
+			return typ, err
+		}
+		return typ + " " + location, nil
+	},
+}
+
+// loadUserTemplates loads the '*.tmpl' templates of a directory. Each template
+// must have an output path (relative to the directory) in the templates.json file:
+//
+//	{
+//		"addresses.ts.tmpl": "../src/addresses.ts"
+//	}
+//
+// The templates are executed with the suaveLib model as data.
+func loadUserTemplates(dir string) ([]generatedFile, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, templatesConfigFile))
+	if err != nil {
+		return nil, err
+	}
+	var outputs map[string]string
+	if err := json.Unmarshal(data, &outputs); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", templatesConfigFile, err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	files := []generatedFile{}
+	found := map[string]bool{}
+
+	for _, path := range paths {
+		name := filepath.Base(path)
+
+		output, ok := outputs[name]
+		if !ok {
+			return nil, fmt.Errorf("template '%s' does not have an output path in %s", name, templatesConfigFile)
+		}
+		found[name] = true
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		t, err := template.New(name).Funcs(templateFuncs).Parse(string(content))
+		if err != nil {
+			return nil, err
+		}
+
+		if !filepath.IsAbs(output) {
+			output = filepath.Join(dir, output)
+		}
+		files = append(files, generatedFile{Path: output, Render: renderUserTemplate(t, output)})
+	}
+
+	for name := range outputs {
+		if !found[name] {
+			return nil, fmt.Errorf("output path set for template '%s' which is not found in %s", name, dir)
+		}
+	}
+	return files, nil
+}
+
+// renderUserTemplate executes a user template and formats the output
+// if it is a Solidity or a Go file.
+func renderUserTemplate(t *template.Template, output string) func(lib *suaveLib) (string, error) {
+	return func(lib *suaveLib) (string, error) {
+		var outputRaw bytes.Buffer
+		if err := t.Execute(&outputRaw, lib); err != nil {
+			return "", err
+		}
+
+		switch filepath.Ext(output) {
+		case ".sol":
+			return formatSolidity(outputRaw.String())
+		case ".go":
+			formatted, err := format.Source(outputRaw.Bytes())
+			if err != nil {
+				return "", fmt.Errorf("failed to format the go output: %v", err)
+			}
+			return string(formatted), nil
+		}
+		return outputRaw.String(), nil
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity ^0.8.8;
+
+/// @notice Library to interact with the Suave MEVM precompiles.
+library Suave {
+    error PeekerReverted(address, bytes);
+
+    type DataId is bytes16;
+
+    /// @notice Description of an HTTP request.
+    /// @param url Target url of the request
+    /// @param method HTTP method of the request
+    /// @param headers HTTP Headers
+    /// @param body Body of the request (if Post or Put)
+    /// @param withFlashbotsSignature Whether to include the Flashbots signature
+    /// @param timeout Timeout of the request in milliseconds
+    struct HttpRequest {
+        string url;
+        string method;
+        string[] headers;
+        bytes body;
+        bool withFlashbotsSignature;
+        uint64 timeout;
+    }
+
+    /// @notice Description of an HTTP response.
+    /// @param status HTTP status code of the response
+    /// @param body Body of the response
+    /// @param error Error message if any
+    struct HttpResponse {
+        uint64 status;
+        bytes body;
+        bytes error;
+    }
+
+    address public constant ANYALLOWED = 0xC8df3686b4Afb2BB53e60EAe97EF043FE03Fb829;
+
+    address public constant CONFIDENTIAL_INPUTS = 0x0000000000000000000000000000000042010001;
+
+    address public constant CONFIDENTIAL_RETRIEVE = 0x0000000000000000000000000000000042020001;
+
+    address public constant DO_HTTPREQUEST2 = 0x0000000000000000000000000000000043200003;
+
+    address public constant RANDOM_BYTES = 0x000000000000000000000000000000007770000b;
+
+    /// @notice Provides the confidential inputs associated with a confidential computation request. Outputs are in bytes format.
+    /// @return confindentialData Confidential inputs
+    function confidentialInputs() internal returns (bytes memory) {
+        (bool success, bytes memory data) = CONFIDENTIAL_INPUTS.call(abi.encode());
+        if (!success) {
+            revert PeekerReverted(CONFIDENTIAL_INPUTS, data);
+        }
+
+        return data;
+    }
+
+    /// @notice Retrieves data from the confidential store. Also mandates the caller's presence in the `AllowedPeekers` list.
+    /// @param dataId ID of the data record to retrieve
+    /// @param key Key slot of the data to retrieve
+    /// @return value Value of the data
+    function confidentialRetrieve(DataId dataId, string memory key) internal returns (bytes memory) {
+        (bool success, bytes memory data) = CONFIDENTIAL_RETRIEVE.call(abi.encode(dataId, key));
+        if (!success) {
+            revert PeekerReverted(CONFIDENTIAL_RETRIEVE, data);
+        }
+
+        return data;
+    }
+
+    /// @notice Performs an HTTP request and returns the response. `request` is the request to perform.
+    /// @param request Request to perform
+    /// @return httpResponse Response of the request
+    function doHTTPRequest2(HttpRequest memory request) internal returns (HttpResponse memory) {
+        (bool success, bytes memory data) = DO_HTTPREQUEST2.call(abi.encode(request));
+        if (!success) {
+            revert PeekerReverted(DO_HTTPREQUEST2, data);
+        }
+
+        return abi.decode(data, (HttpResponse));
+    }
+
+    /// @notice Generates a number of random bytes, given by the argument numBytes.
+    /// @param numBytes Number of random bytes to generate
+    /// @return value Randomly-generated bytes
+    function randomBytes(uint8 numBytes) internal returns (bytes memory) {
+        (bool success, bytes memory data) = RANDOM_BYTES.call(abi.encode(numBytes));
+        if (!success) {
+            revert PeekerReverted(RANDOM_BYTES, data);
+        }
+
+        return abi.decode(data, (bytes));
+    }
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Environment variables that take precedence over the trace keys in foundry.toml.
+const (
+	traceBackendEnv = "SUAVE_TRACE_BACKEND"
+	traceEnv        = "SUAVE_TRACE"
+	traceModeEnv    = "SUAVE_TRACE_MODE"
+)
+
+// Modes of the tracer.
+const (
+	// traceModeRecord runs the calls with the backend and writes them in the trace
+	traceModeRecord = "record"
+	// traceModeReplay serves the calls from the trace without the backend
+	traceModeReplay = "replay"
+)
+
+// defaultTrace is the trace file if it is not set, relative to foundry.toml.
+const defaultTrace = "cache/forge-trace.jsonl"
+
+// config is the configuration of the tracer in the [profile.suave] section of foundry.toml.
+type config struct {
+	// Backend is the binary that runs the calls, suave-geth if it is not set
+	Backend string `toml:"trace_backend"`
+	// Trace is the JSONL file with the calls
+	Trace string `toml:"trace"`
+	// Mode is record (the default) or replay
+	Mode string `toml:"trace_mode"`
+}
+
+// loadConfig reads the configuration from foundry.toml and the environment.
+// The file is optional. The path of the trace is resolved relative to foundry.toml.
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+
+	if path != "" {
+		var foundryToml struct {
+			Profile struct {
+				Suave config `toml:"suave"`
+			} `toml:"profile"`
+		}
+		if _, err := toml.DecodeFile(path, &foundryToml); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		cfg = &foundryToml.Profile.Suave
+	}
+
+	if backend, ok := os.LookupEnv(traceBackendEnv); ok {
+		cfg.Backend = backend
+	}
+	if trace, ok := os.LookupEnv(traceEnv); ok {
+		cfg.Trace = trace
+	}
+	if mode, ok := os.LookupEnv(traceModeEnv); ok {
+		cfg.Mode = mode
+	}
+
+	if cfg.Backend == "" {
+		cfg.Backend = suaveGethBinary
+	}
+	if cfg.Trace == "" {
+		cfg.Trace = defaultTrace
+	}
+	if !filepath.IsAbs(cfg.Trace) && path != "" {
+		cfg.Trace = filepath.Join(filepath.Dir(path), cfg.Trace)
+	}
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = traceModeRecord
+	case traceModeRecord, traceModeReplay:
+	default:
+		return nil, fmt.Errorf("unknown trace mode '%s', expected record or replay", cfg.Mode)
+	}
+	return cfg, nil
+}
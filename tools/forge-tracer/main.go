@@ -0,0 +1,255 @@
+// forge-tracer sits between the forge Connector and the backend that runs the
+// precompiles (suave-geth or forge-backend). It is selected as the backend of the
+// Connector and is called with the same arguments:
+//
+//	forge-tracer forge [--local] [--config foundry.toml] [--session id] <address> <calldata>
+//	forge-tracer session [--config foundry.toml]
+//	forge-tracer clock [--config foundry.toml] --session id <op> [time]
+//
+// In record mode, the call runs with the backend set in the 'trace_backend' key of
+// foundry.toml and is written with its decoded inputs and result in a JSONL trace.
+// In replay mode, the recorded results are returned without running the backend.
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// suaveGethBinary is the default backend. It does not support sessions.
+const suaveGethBinary = "suave-geth"
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "forge", "session", "clock":
+	default:
+		usage()
+	}
+
+	exitCode, err := run(os.Args[1], os.Args[2:], os.Stdout, os.Stderr)
+	if err != nil {
+		fmt.Fprint(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	os.Exit(exitCode)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: forge-tracer forge [--local] [--config foundry.toml] [--session id] <address> <calldata>")
+	fmt.Fprintln(os.Stderr, "       forge-tracer session [--config foundry.toml]")
+	fmt.Fprintln(os.Stderr, "       forge-tracer clock [--config foundry.toml] --session id <op> [time]")
+	os.Exit(1)
+}
+
+// invocation is a call of the Connector to the tracer.
+type invocation struct {
+	command   string
+	local     bool
+	config    string
+	session   string
+	arguments []string
+}
+
+func parseInvocation(command string, args []string) (*invocation, error) {
+	inv := &invocation{command: command}
+
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.BoolVar(&inv.local, "local", false, "passed to the backend")
+	fs.StringVar(&inv.config, "config", "", "path to the foundry.toml file")
+	fs.StringVar(&inv.session, "session", "", "id of the session of the test")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	inv.arguments = fs.Args()
+
+	if command == "forge" && len(inv.arguments) != 2 {
+		return nil, fmt.Errorf("expected the precompile address and the calldata but found %d arguments", len(inv.arguments))
+	}
+	return inv, nil
+}
+
+// backendArgs returns the arguments of the call for the backend. The session
+// is not passed to suave-geth, which does not know about the sessions.
+func (inv *invocation) backendArgs(backend string) []string {
+	args := []string{inv.command}
+	if inv.local {
+		args = append(args, "--local")
+	}
+	if inv.config != "" {
+		args = append(args, "--config", inv.config)
+	}
+	if inv.session != "" && supportsSessions(backend) {
+		args = append(args, "--session", inv.session)
+	}
+	return append(args, inv.arguments...)
+}
+
+func supportsSessions(backend string) bool {
+	return filepath.Base(backend) != suaveGethBinary
+}
+
+// entry returns the trace entry of the call, without its result.
+func (inv *invocation) entry() (*traceEntry, error) {
+	entry := &traceEntry{
+		Time:    time.Now().UTC(),
+		Session: inv.session,
+		Command: inv.command,
+	}
+	if inv.command != "forge" {
+		entry.Args = inv.arguments
+		return entry, nil
+	}
+
+	addr, err := hexutil.Decode(inv.arguments[0])
+	if err != nil || len(addr) > common.AddressLength {
+		return nil, fmt.Errorf("invalid precompile address '%s'", inv.arguments[0])
+	}
+	calldata, err := hexutil.Decode(inv.arguments[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid calldata: %v", err)
+	}
+	address := common.BytesToAddress(addr)
+	entry.Address = &address
+	entry.Calldata = calldata
+	return entry, nil
+}
+
+// run runs the command and writes its output as the backend would. It returns
+// the exit code of the backend.
+func run(command string, args []string, stdout, stderr io.Writer) (int, error) {
+	inv, err := parseInvocation(command, args)
+	if err != nil {
+		return 0, err
+	}
+	cfg, err := loadConfig(inv.config)
+	if err != nil {
+		return 0, err
+	}
+
+	if command == "session" {
+		return runSession(cfg, inv, stdout, stderr)
+	}
+
+	call, err := inv.entry()
+	if err != nil {
+		return 0, err
+	}
+
+	if cfg.Mode == traceModeReplay {
+		entry, err := replay(cfg, call, inv.session)
+		if err != nil {
+			return 0, err
+		}
+		return writeResult(entry, stdout, stderr)
+	}
+
+	start := time.Now()
+	output, errOutput, exitCode, err := execBackend(cfg.Backend, inv.backendArgs(cfg.Backend))
+	if err != nil {
+		return 0, err
+	}
+	call.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	call.ExitCode = exitCode
+	call.Error = string(errOutput)
+	if exitCode == 0 {
+		if call.Output, err = hexutil.Decode(strings.TrimSpace(string(output))); err != nil {
+			call.DecodeError = fmt.Sprintf("the output is not hex: %v", err)
+		}
+	}
+	if call.Address != nil {
+		call.decode()
+	}
+	if err := appendEntry(cfg.Trace, call); err != nil {
+		return 0, fmt.Errorf("failed to write the trace: %v", err)
+	}
+
+	stdout.Write(output)
+	stderr.Write(errOutput)
+	return exitCode, nil
+}
+
+var sessionOutputs = abi.Arguments{
+	{Name: "id", Type: mustType("bytes32")},
+	{Name: "seed", Type: mustType("bytes32")},
+}
+
+// runSession creates the session of a test. It is created by the backend if it
+// supports sessions, otherwise (and in replay mode) the tracer returns a random
+// id to group the calls of the test in the trace, with a zero seed.
+func runSession(cfg *config, inv *invocation, stdout, stderr io.Writer) (int, error) {
+	if cfg.Mode == traceModeRecord && supportsSessions(cfg.Backend) {
+		output, errOutput, exitCode, err := execBackend(cfg.Backend, inv.backendArgs(cfg.Backend))
+		if err != nil {
+			return 0, err
+		}
+		stdout.Write(output)
+		stderr.Write(errOutput)
+		return exitCode, nil
+	}
+
+	if cfg.Mode == traceModeReplay {
+		removeOldReplaySessions(replayDir(cfg))
+	}
+	var id common.Hash
+	if _, err := rand.Read(id[:]); err != nil {
+		return 0, err
+	}
+	output, err := sessionOutputs.Pack(id, common.Hash{})
+	if err != nil {
+		return 0, err
+	}
+	fmt.Fprint(stdout, hexutil.Encode(output))
+	return 0, nil
+}
+
+// execBackend runs the backend and returns its output and exit code.
+func execBackend(backend string, args []string) ([]byte, []byte, int, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(backend, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, nil, 0, fmt.Errorf("failed to run the backend %s: %v", backend, err)
+		}
+		return stdout.Bytes(), stderr.Bytes(), exitErr.ExitCode(), nil
+	}
+	return stdout.Bytes(), stderr.Bytes(), 0, nil
+}
+
+// writeResult writes the recorded result of a call as the backend did.
+func writeResult(entry *traceEntry, stdout, stderr io.Writer) (int, error) {
+	if entry.ExitCode != 0 {
+		fmt.Fprint(stderr, entry.Error)
+		return entry.ExitCode, nil
+	}
+	fmt.Fprint(stdout, entry.Output.String())
+	return 0, nil
+}
+
+func mustType(typ string) abi.Type {
+	t, err := abi.NewType(typ, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/suave-std/tools/suavelib"
+)
+
+// fakeBackend writes a backend script that logs its arguments, returns the
+// outputs in order for each forge call and fails for the calls past the outputs.
+func fakeBackend(t *testing.T, name string, outputs ...[]byte) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	argsLog := filepath.Join(dir, "args.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + argsLog + "\n" +
+		"[ \"$1\" = session ] && { printf 0x" + strings.Repeat("11", 32) + strings.Repeat("22", 32) + "; exit 0; }\n" +
+		"n=$(grep -c '^forge' " + argsLog + ")\n" +
+		"case $n in\n"
+	for indx, output := range outputs {
+		script += "  " + string(rune('1'+indx)) + ") printf " + hexutil.Encode(output) + ";;\n"
+	}
+	script += "  *) printf 'no more outputs' >&2; exit 2;;\nesac\n"
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path, argsLog
+}
+
+func runTracer(t *testing.T, command string, args ...string) (string, string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := run(command, args, &stdout, &stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), exitCode
+}
+
+func TestRecordReplay(t *testing.T) {
+	first, _ := suavelib.PackRandomBytesOutputs([]byte{0x1, 0x2})
+	second, _ := suavelib.PackRandomBytesOutputs([]byte{0x3, 0x4})
+	backend, argsLog := fakeBackend(t, "forge-backend", first, second)
+
+	trace := filepath.Join(t.TempDir(), "trace.jsonl")
+	t.Setenv(traceBackendEnv, backend)
+	t.Setenv(traceEnv, trace)
+	t.Setenv(traceModeEnv, "record")
+
+	// the session of the backend is used
+	stdout, _, _ := runTracer(t, "session")
+	values, err := sessionOutputs.Unpack(hexutil.MustDecode(stdout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := common.Hash(values[0].([32]byte)).Hex()
+	if session != "0x"+strings.Repeat("11", 32) {
+		t.Fatalf("expected the session of the backend but found %s", session)
+	}
+
+	input, _ := suavelib.PackRandomBytesInputs(2)
+	call := []string{"--local", "--session", session, suavelib.RandomBytesAddr.Hex(), hexutil.Encode(input)}
+	for _, expected := range [][]byte{first, second} {
+		if stdout, _, exitCode := runTracer(t, "forge", call...); exitCode != 0 || stdout != hexutil.Encode(expected) {
+			t.Fatalf("unexpected output %s (exit code %d)", stdout, exitCode)
+		}
+	}
+	if _, stderr, exitCode := runTracer(t, "forge", call...); exitCode != 2 || stderr != "no more outputs" {
+		t.Fatalf("expected the error of the backend but found %s (exit code %d)", stderr, exitCode)
+	}
+
+	args, _ := os.ReadFile(argsLog)
+	if !strings.Contains(string(args), "forge --local --session "+session) {
+		t.Fatalf("the session is not passed to the backend:\n%s", args)
+	}
+
+	entries, err := loadTrace(trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries but found %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Precompile != "RANDOM_BYTES" || entry.Function != "randomBytes" || entry.Session != session {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if entry.Inputs["numBytes"] != float64(2) || entry.Result["value"] != "0x0102" {
+		t.Fatalf("unexpected decoded inputs %v or result %v", entry.Inputs, entry.Result)
+	}
+	if failed := entries[2]; failed.ExitCode != 2 || failed.Error != "no more outputs" || failed.Result != nil {
+		t.Fatalf("unexpected failed entry %+v", failed)
+	}
+
+	// the recorded results are replayed in order without the backend
+	t.Setenv(traceBackendEnv, filepath.Join(t.TempDir(), "missing"))
+	t.Setenv(traceModeEnv, "replay")
+
+	stdout, _, _ = runTracer(t, "session")
+	values, _ = sessionOutputs.Unpack(hexutil.MustDecode(stdout))
+	replaySession := common.Hash(values[0].([32]byte)).Hex()
+
+	call = []string{"--local", "--session", replaySession, suavelib.RandomBytesAddr.Hex(), hexutil.Encode(input)}
+	for _, expected := range [][]byte{first, second} {
+		if stdout, _, exitCode := runTracer(t, "forge", call...); exitCode != 0 || stdout != hexutil.Encode(expected) {
+			t.Fatalf("unexpected replayed output %s (exit code %d)", stdout, exitCode)
+		}
+	}
+	if _, stderr, exitCode := runTracer(t, "forge", call...); exitCode != 2 || stderr != "no more outputs" {
+		t.Fatalf("expected the replayed error but found %s (exit code %d)", stderr, exitCode)
+	}
+
+	other, _ := suavelib.PackRandomBytesInputs(3)
+	if _, err := run("forge", []string{"--session", replaySession, suavelib.RandomBytesAddr.Hex(), hexutil.Encode(other)}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "RANDOM_BYTES") {
+		t.Fatalf("expected an error for a call that is not in the trace but found %v", err)
+	}
+}
+
+func TestRecord_SuaveGeth(t *testing.T) {
+	output, _ := suavelib.PackDoHTTPRequest2Outputs(suavelib.HttpResponse{Status: 200, Body: []byte(`{"result":"0x1"}`)})
+	backend, argsLog := fakeBackend(t, "suave-geth", output)
+
+	trace := filepath.Join(t.TempDir(), "trace.jsonl")
+	t.Setenv(traceBackendEnv, backend)
+	t.Setenv(traceEnv, trace)
+
+	// suave-geth does not support sessions, the tracer creates them
+	stdout, _, _ := runTracer(t, "session")
+	values, _ := sessionOutputs.Unpack(hexutil.MustDecode(stdout))
+	session := common.Hash(values[0].([32]byte)).Hex()
+
+	input, _ := suavelib.PackDoHTTPRequest2Inputs(suavelib.HttpRequest{Url: "http://localhost:8545", Method: "POST", Headers: []string{}, Body: []byte(`{"method":"eth_blockNumber"}`)})
+	if stdout, _, _ := runTracer(t, "forge", "--session", session, suavelib.DoHTTPRequest2Addr.Hex(), hexutil.Encode(input)); stdout != hexutil.Encode(output) {
+		t.Fatalf("unexpected output %s", stdout)
+	}
+
+	args, _ := os.ReadFile(argsLog)
+	if strings.Contains(string(args), "session") {
+		t.Fatalf("the session is passed to suave-geth:\n%s", args)
+	}
+
+	entries, err := loadTrace(trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := entries[0].Inputs["request"].(map[string]interface{})
+	if request["body"] != `{"method":"eth_blockNumber"}` || request["url"] != "http://localhost:8545" {
+		t.Fatalf("unexpected decoded request %v", request)
+	}
+	response := entries[0].Result["httpResponse"].(map[string]interface{})
+	if response["status"] != float64(200) || response["body"] != `{"result":"0x1"}` {
+		t.Fatalf("unexpected decoded response %v", response)
+	}
+}
+
+func TestBytesValue(t *testing.T) {
+	cases := map[string]string{
+		"hello":     "hello",
+		"\x01\x02":  "0x0102",
+		"":          "0x",
+		"0x1234":    "0x307831323334",
+		"line\nend": "line\nend",
+	}
+	for input, expected := range cases {
+		if found := bytesValue([]byte(input)); found != expected {
+			t.Fatalf("expected %q for %q but found %q", expected, input, found)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// replaySession counts the calls that a test has replayed, so that the n-th
+// identical call of the test gets the n-th recorded entry.
+type replaySession struct {
+	Counters map[string]int `json:"counters"`
+
+	path string
+}
+
+// replayDir returns the directory of the replay sessions, next to the trace.
+func replayDir(cfg *config) string {
+	return filepath.Join(filepath.Dir(cfg.Trace), "forge-tracer")
+}
+
+func loadReplaySession(dir, id string) (*replaySession, error) {
+	if strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid session id '%s'", id)
+	}
+	s := &replaySession{Counters: map[string]int{}, path: filepath.Join(dir, id+".json")}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to decode replay session %s: %v", id, err)
+	}
+	return s, nil
+}
+
+func (s *replaySession) save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// replay returns the recorded entry for the call. The n-th identical call of a
+// session gets the n-th entry of the trace for that call, or the last one if the
+// test makes more calls than were recorded.
+func replay(cfg *config, call *traceEntry, session string) (*traceEntry, error) {
+	entries, err := loadTrace(cfg.Trace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the trace: %v", err)
+	}
+	key := call.key()
+
+	matches := []*traceEntry{}
+	for _, entry := range entries {
+		if entry.key() == key {
+			matches = append(matches, entry)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no entry in the trace %s matches the call %s", cfg.Trace, describeCall(call))
+	}
+	if session == "" {
+		return matches[0], nil
+	}
+
+	s, err := loadReplaySession(replayDir(cfg), session)
+	if err != nil {
+		return nil, err
+	}
+	indx := min(s.Counters[key], len(matches)-1)
+	s.Counters[key]++
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	return matches[indx], nil
+}
+
+// describeCall returns a short description of the call for the errors.
+func describeCall(call *traceEntry) string {
+	if call.Address == nil {
+		return strings.TrimSpace(call.Command + " " + strings.Join(call.Args, " "))
+	}
+	call.decode()
+	return fmt.Sprintf("to %s with calldata %s", call.Precompile, call.Calldata)
+}
+
+// removeOldReplaySessions removes the replay sessions older than a day.
+func removeOldReplaySessions(dir string) {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > 24*time.Hour {
+			os.Remove(path)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/flashbots/suave-std/tools/suavelib"
+)
+
+// traceEntry is a call of the Connector to the backend, written as a line of the trace.
+type traceEntry struct {
+	Time time.Time `json:"time"`
+	// Session is the session of the test that made the call
+	Session string `json:"session,omitempty"`
+	// Command is the command of the backend (forge or clock)
+	Command string `json:"command"`
+
+	// Address, Precompile, Calldata and Output are set for the forge command.
+	// Precompile is the name of the address in Suave.sol.
+	Address    *common.Address `json:"address,omitempty"`
+	Precompile string          `json:"precompile,omitempty"`
+	Function   string          `json:"function,omitempty"`
+	Calldata   hexutil.Bytes   `json:"calldata,omitempty"`
+	// Args are the positional arguments of the other commands
+	Args []string `json:"args,omitempty"`
+
+	// Inputs and Result are the decoded calldata and output
+	Inputs map[string]interface{} `json:"inputs,omitempty"`
+	Result map[string]interface{} `json:"result,omitempty"`
+	// DecodeError is set if the calldata or the output cannot be decoded
+	DecodeError string `json:"decodeError,omitempty"`
+
+	// Output is the output of the backend, hex decoded
+	Output   hexutil.Bytes `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	ExitCode int           `json:"exitCode"`
+	// DurationMs is the time the backend took to run the call
+	DurationMs float64 `json:"durationMs"`
+}
+
+// decode resolves the precompile of a forge call and decodes its calldata and output.
+func (e *traceEntry) decode() {
+	p, ok := suavelib.Precompiles[*e.Address]
+	if !ok {
+		e.Precompile = e.Address.Hex()
+		return
+	}
+	e.Precompile, e.Function = p.Name, p.Function
+
+	var err error
+	if e.Inputs, err = decodeArguments(p.Inputs, e.Calldata); err != nil {
+		e.DecodeError = fmt.Sprintf("failed to decode the calldata: %v", err)
+		return
+	}
+	if e.ExitCode != 0 {
+		return
+	}
+	if p.RawOutput {
+		e.Result = map[string]interface{}{p.Outputs[0].Name: bytesValue(e.Output)}
+	} else if e.Result, err = decodeArguments(p.Outputs, e.Output); err != nil {
+		e.DecodeError = fmt.Sprintf("failed to decode the output: %v", err)
+	}
+}
+
+// decodeArguments decodes abi encoded data into a map by argument name.
+func decodeArguments(args abi.Arguments, data []byte) (map[string]interface{}, error) {
+	values, err := args.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+	decoded := map[string]interface{}{}
+	for indx, arg := range args {
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", indx)
+		}
+		decoded[name] = jsonValue(reflect.ValueOf(values[indx]))
+	}
+	return decoded, nil
+}
+
+var (
+	addressType = reflect.TypeOf(common.Address{})
+	bigIntType  = reflect.TypeOf(&big.Int{})
+)
+
+// jsonValue converts a value decoded by go-ethereum to a readable JSON value:
+// tuples are objects, the addresses and the fixed bytes are in hex and the
+// big numbers are decimal strings.
+func jsonValue(v reflect.Value) interface{} {
+	switch {
+	case v.Type() == addressType:
+		return v.Interface().(common.Address).Hex()
+	case v.Type() == bigIntType:
+		return v.Interface().(*big.Int).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return bytesValue(v.Bytes())
+		}
+		fallthrough
+	case reflect.Array:
+		if v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Encode(b)
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = jsonValue(v.Index(i))
+		}
+		return list
+	case reflect.Struct:
+		// go-ethereum sets the name of the tuple components in the json tags
+		fields := map[string]interface{}{}
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Tag.Get("json")
+			if name == "" {
+				name = v.Type().Field(i).Name
+			}
+			fields[name] = jsonValue(v.Field(i))
+		}
+		return fields
+	}
+	return v.Interface()
+}
+
+// bytesValue returns the bytes as a string if they are printable text
+// (i.e. an http body), in hex with the 0x prefix otherwise.
+func bytesValue(b []byte) string {
+	if len(b) == 0 || !utf8.Valid(b) || bytes.HasPrefix(b, []byte("0x")) {
+		return hexutil.Encode(b)
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return hexutil.Encode(b)
+		}
+	}
+	return string(b)
+}
+
+// key identifies the calls that are replayed with the same entries.
+func (e *traceEntry) key() string {
+	if e.Address != nil {
+		return e.Command + " " + e.Address.Hex() + " " + e.Calldata.String()
+	}
+	return e.Command + " " + strings.Join(e.Args, " ")
+}
+
+// appendEntry writes the entry as a line at the end of the trace. The tests run in
+// parallel, so the line is written with a single write on a file opened in append mode.
+func appendEntry(path string, entry *traceEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// loadTrace reads the entries of the trace.
+func loadTrace(path string) ([]*traceEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []*traceEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		entry := &traceEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid entry: %v", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,31 @@
+// suave-version checks that the installed suave-geth and the Suave.sol file
+// match the provenance recorded in the generated code.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flashbots/suave-std/tools/suavelib"
+)
+
+func main() {
+	var suaveGeth, suaveSol string
+
+	flag.StringVar(&suaveGeth, "suave-geth", "suave-geth", "path to the suave-geth binary")
+	flag.StringVar(&suaveSol, "suave-sol", "", "path to the Suave.sol file to check (optional)")
+	flag.Parse()
+
+	if err := suavelib.CheckSuaveGeth(suaveGeth); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if suaveSol != "" {
+		if err := suavelib.CheckSuaveSol(suaveSol); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	fmt.Println("suave-geth and Suave.sol match the generated code")
+}
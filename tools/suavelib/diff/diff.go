@@ -0,0 +1,53 @@
+// Package diff computes the line diffs shown by forge-gen and forge-backend.
+// It does not depend on the generated bindings so that forge-gen can use it
+// while it regenerates them.
+package diff
+
+// Op is a line of the edit script between two list of lines.
+type Op struct {
+	Kind byte // ' ', '-' or '+'
+	Line string
+}
+
+// Lines computes the edit script between two list of lines using
+// the longest common subsequence.
+func Lines(a, b []string) []Op {
+	// lcs[i][j] is the length of the lcs of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := []Op{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, Op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, Op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, Op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, Op{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, Op{'+', b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,18 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	ops := Lines([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+	expected := []Op{{' ', "a"}, {'-', "b"}, {' ', "c"}, {'+', "d"}}
+	if !reflect.DeepEqual(ops, expected) {
+		t.Fatalf("unexpected diff %q", ops)
+	}
+
+	if ops := Lines(nil, []string{"a"}); !reflect.DeepEqual(ops, []Op{{'+', "a"}}) {
+		t.Fatalf("unexpected diff %q", ops)
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/flashbots/suave-std/tools/suavelib
+
+go 1.21.0
+
+require github.com/ethereum/go-ethereum v1.13.14
+
+require (
+	github.com/btcsuite/btcd/btcec/v2 v2.2.0 // indirect
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
+	github.com/holiman/uint256 v1.2.4 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/sys v0.16.0 // indirect
+)
@@ -0,0 +1,1033 @@
+// Code generated by forge-gen. DO NOT EDIT.
+
+// Package suavelib contains the Go bindings of the Suave.sol library. It includes
+// the types, the address of each precompile and the functions to encode and decode
+// the inputs and outputs of the precompiles with the same ABI encoding as Suave.sol.
+package suavelib
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DataId is the Suave.DataId user defined value type.
+type DataId [16]byte
+
+// CryptoSignature is the Suave.CryptoSignature enum.
+type CryptoSignature uint8
+
+const (
+	CryptoSignatureSECP256 CryptoSignature = 0
+	CryptoSignatureBLS     CryptoSignature = 1
+)
+
+// BuildBlockArgs is the Suave.BuildBlockArgs struct. Arguments to build the block.
+type BuildBlockArgs struct {
+	Slot           uint64         `json:"slot"`           // Slot number of the block
+	ProposerPubkey []byte         `json:"proposerPubkey"` // Public key of the proposer
+	Parent         [32]byte       `json:"parent"`         // Hash of the parent block
+	Timestamp      uint64         `json:"timestamp"`      // Timestamp of the block
+	FeeRecipient   common.Address `json:"feeRecipient"`   // Address of the fee recipient
+	GasLimit       uint64         `json:"gasLimit"`       // Gas limit of the block
+	Random         [32]byte       `json:"random"`         // Randomness of the block
+	Withdrawals    []Withdrawal   `json:"withdrawals"`    // List of withdrawals
+	Extra          []byte         `json:"extra"`          // Extra data of the block
+	BeaconRoot     [32]byte       `json:"beaconRoot"`     // Root of the beacon chain
+	FillPending    bool           `json:"fillPending"`    // Whether to fill the block with pending transactions
+}
+
+// DataRecord is the Suave.DataRecord struct. A record of data stored in the ConfidentialStore.
+type DataRecord struct {
+	Id                  DataId           `json:"id"`                  // ID of the data record
+	Salt                DataId           `json:"salt"`                // Salt used to derive the encryption key
+	DecryptionCondition uint64           `json:"decryptionCondition"` // Up to which block this data record is valid
+	AllowedPeekers      []common.Address `json:"allowedPeekers"`      // Addresses which can get data
+	AllowedStores       []common.Address `json:"allowedStores"`       // Addresses can set data
+	Version             string           `json:"version"`             // Namespace of the data record
+}
+
+// HttpRequest is the Suave.HttpRequest struct. Description of an HTTP request.
+type HttpRequest struct {
+	Url                    string   `json:"url"`                    // Target url of the request
+	Method                 string   `json:"method"`                 // HTTP method of the request
+	Headers                []string `json:"headers"`                // HTTP Headers
+	Body                   []byte   `json:"body"`                   // Body of the request (if Post or Put)
+	WithFlashbotsSignature bool     `json:"withFlashbotsSignature"` // Whether to include the Flashbots signature
+	Timeout                uint64   `json:"timeout"`                // Timeout of the request in milliseconds
+}
+
+// HttpResponse is the Suave.HttpResponse struct. Description of an HTTP response.
+type HttpResponse struct {
+	Status uint64 `json:"status"` // HTTP status code of the response
+	Body   []byte `json:"body"`   // Body of the response
+	Error  []byte `json:"error"`  // Error message if any
+}
+
+// SimulateTransactionResult is the Suave.SimulateTransactionResult struct. Result of a simulated transaction.
+type SimulateTransactionResult struct {
+	Egp     uint64         `json:"egp"`     // Effective Gas Price of the transaction
+	Logs    []SimulatedLog `json:"logs"`    // Logs emitted during the simulation
+	Success bool           `json:"success"` // Whether the transaction was successful or not
+	Error   string         `json:"error"`   // Error message if any
+}
+
+// SimulatedLog is the Suave.SimulatedLog struct. A log emitted during the simulation of a transaction.
+type SimulatedLog struct {
+	Data   []byte         `json:"data"`   // Data of the log
+	Addr   common.Address `json:"addr"`   // Address of the contract that emitted the log
+	Topics [][32]byte     `json:"topics"` // Topics of the log
+}
+
+// Withdrawal is the Suave.Withdrawal struct. A withdrawal from the beacon chain.
+type Withdrawal struct {
+	Index     uint64         `json:"index"`     // Index of the withdrawal
+	Validator uint64         `json:"validator"` // ID of the validator
+	Address   common.Address `json:"Address"`   // Address to withdraw to
+	Amount    uint64         `json:"amount"`    // Amount to be withdrawn
+}
+
+// Provenance of the Suave.sol file used to generate the bindings.
+const (
+	// SuaveSolHash is the sha256 hash of Suave.sol
+	SuaveSolHash = "93768d775163115cbd1d7accba75a415a4a70f150dfe4d2004215ed1c41514d8"
+	// SuaveGethCommit is the suave-geth commit that Suave.sol was synced from (empty if unknown)
+	SuaveGethCommit = ""
+)
+
+// Addresses of the Suave precompiles.
+var (
+	IsConfidentialAddr        = common.HexToAddress("0x0000000000000000000000000000000042010000")
+	AesDecryptAddr            = common.HexToAddress("0x000000000000000000000000000000005670000D")
+	AesEncryptAddr            = common.HexToAddress("0x000000000000000000000000000000005670000e")
+	BuildEthBlockAddr         = common.HexToAddress("0x0000000000000000000000000000000042100001")
+	BuildEthBlockToAddr       = common.HexToAddress("0x0000000000000000000000000000000042100006")
+	ConfidentialRetrieveAddr  = common.HexToAddress("0x0000000000000000000000000000000042020001")
+	ConfidentialStoreAddr     = common.HexToAddress("0x0000000000000000000000000000000042020000")
+	ContextGetAddr            = common.HexToAddress("0x0000000000000000000000000000000053300003")
+	DoHTTPRequestAddr         = common.HexToAddress("0x0000000000000000000000000000000043200002")
+	DoHTTPRequest2Addr        = common.HexToAddress("0x0000000000000000000000000000000043200003")
+	EthcallAddr               = common.HexToAddress("0x0000000000000000000000000000000042100003")
+	ExtractHintAddr           = common.HexToAddress("0x0000000000000000000000000000000042100037")
+	FetchDataRecordsAddr      = common.HexToAddress("0x0000000000000000000000000000000042030001")
+	FillMevShareBundleAddr    = common.HexToAddress("0x0000000000000000000000000000000043200001")
+	GetInsecureTimeAddr       = common.HexToAddress("0x000000000000000000000000000000007770000c")
+	NewBuilderAddr            = common.HexToAddress("0x0000000000000000000000000000000053200001")
+	NewDataRecordAddr         = common.HexToAddress("0x0000000000000000000000000000000042030000")
+	PrivateKeyGenAddr         = common.HexToAddress("0x0000000000000000000000000000000053200003")
+	RandomBytesAddr           = common.HexToAddress("0x000000000000000000000000000000007770000b")
+	SignEthTransactionAddr    = common.HexToAddress("0x0000000000000000000000000000000040100001")
+	SignMessageAddr           = common.HexToAddress("0x0000000000000000000000000000000040100003")
+	SimulateBundleAddr        = common.HexToAddress("0x0000000000000000000000000000000042100000")
+	SimulateTransactionAddr   = common.HexToAddress("0x0000000000000000000000000000000053200002")
+	SubmitBundleJsonRPCAddr   = common.HexToAddress("0x0000000000000000000000000000000043000001")
+	SubmitEthBlockToRelayAddr = common.HexToAddress("0x0000000000000000000000000000000042100002")
+)
+
+// PrecompileNames maps the address of each precompile to its name in Suave.sol.
+var PrecompileNames = map[common.Address]string{
+	IsConfidentialAddr:        "IS_CONFIDENTIAL_ADDR",
+	AesDecryptAddr:            "AES_DECRYPT",
+	AesEncryptAddr:            "AES_ENCRYPT",
+	BuildEthBlockAddr:         "BUILD_ETH_BLOCK",
+	BuildEthBlockToAddr:       "BUILD_ETH_BLOCK_TO",
+	ConfidentialRetrieveAddr:  "CONFIDENTIAL_RETRIEVE",
+	ConfidentialStoreAddr:     "CONFIDENTIAL_STORE",
+	ContextGetAddr:            "CONTEXT_GET",
+	DoHTTPRequestAddr:         "DO_HTTPREQUEST",
+	DoHTTPRequest2Addr:        "DO_HTTPREQUEST2",
+	EthcallAddr:               "ETHCALL",
+	ExtractHintAddr:           "EXTRACT_HINT",
+	FetchDataRecordsAddr:      "FETCH_DATA_RECORDS",
+	FillMevShareBundleAddr:    "FILL_MEV_SHARE_BUNDLE",
+	GetInsecureTimeAddr:       "GET_INSECURE_TIME",
+	NewBuilderAddr:            "NEW_BUILDER",
+	NewDataRecordAddr:         "NEW_DATA_RECORD",
+	PrivateKeyGenAddr:         "PRIVATE_KEY_GEN",
+	RandomBytesAddr:           "RANDOM_BYTES",
+	SignEthTransactionAddr:    "SIGN_ETH_TRANSACTION",
+	SignMessageAddr:           "SIGN_MESSAGE",
+	SimulateBundleAddr:        "SIMULATE_BUNDLE",
+	SimulateTransactionAddr:   "SIMULATE_TRANSACTION",
+	SubmitBundleJsonRPCAddr:   "SUBMIT_BUNDLE_JSON_RPC",
+	SubmitEthBlockToRelayAddr: "SUBMIT_ETH_BLOCK_TO_RELAY",
+}
+
+// Precompile is the ABI of a precompile, to decode its inputs and outputs
+// without knowing the precompile in advance.
+type Precompile struct {
+	// Name is the name of the address in Suave.sol
+	Name string
+	// Function is the function of Suave.sol that calls the precompile
+	Function string
+	Inputs   abi.Arguments
+	Outputs  abi.Arguments
+	// RawOutput is true if the precompile returns the output as is, without abi encoding
+	RawOutput bool
+}
+
+// Precompiles maps the address of each precompile to its ABI.
+var Precompiles = map[common.Address]*Precompile{
+	IsConfidentialAddr:        {Name: "IS_CONFIDENTIAL_ADDR", Function: "isConfidential", Inputs: isConfidentialInputs, Outputs: isConfidentialOutputs},
+	AesDecryptAddr:            {Name: "AES_DECRYPT", Function: "aesDecrypt", Inputs: aesDecryptInputs, Outputs: aesDecryptOutputs},
+	AesEncryptAddr:            {Name: "AES_ENCRYPT", Function: "aesEncrypt", Inputs: aesEncryptInputs, Outputs: aesEncryptOutputs},
+	BuildEthBlockAddr:         {Name: "BUILD_ETH_BLOCK", Function: "buildEthBlock", Inputs: buildEthBlockInputs, Outputs: buildEthBlockOutputs},
+	BuildEthBlockToAddr:       {Name: "BUILD_ETH_BLOCK_TO", Function: "buildEthBlockTo", Inputs: buildEthBlockToInputs, Outputs: buildEthBlockToOutputs},
+	ConfidentialRetrieveAddr:  {Name: "CONFIDENTIAL_RETRIEVE", Function: "confidentialRetrieve", Inputs: confidentialRetrieveInputs, Outputs: confidentialRetrieveOutputs, RawOutput: true},
+	ConfidentialStoreAddr:     {Name: "CONFIDENTIAL_STORE", Function: "confidentialStore", Inputs: confidentialStoreInputs, Outputs: confidentialStoreOutputs},
+	ContextGetAddr:            {Name: "CONTEXT_GET", Function: "contextGet", Inputs: contextGetInputs, Outputs: contextGetOutputs},
+	DoHTTPRequestAddr:         {Name: "DO_HTTPREQUEST", Function: "doHTTPRequest", Inputs: doHTTPRequestInputs, Outputs: doHTTPRequestOutputs},
+	DoHTTPRequest2Addr:        {Name: "DO_HTTPREQUEST2", Function: "doHTTPRequest2", Inputs: doHTTPRequest2Inputs, Outputs: doHTTPRequest2Outputs},
+	EthcallAddr:               {Name: "ETHCALL", Function: "ethcall", Inputs: ethcallInputs, Outputs: ethcallOutputs},
+	ExtractHintAddr:           {Name: "EXTRACT_HINT", Function: "extractHint", Inputs: extractHintInputs, Outputs: extractHintOutputs, RawOutput: true},
+	FetchDataRecordsAddr:      {Name: "FETCH_DATA_RECORDS", Function: "fetchDataRecords", Inputs: fetchDataRecordsInputs, Outputs: fetchDataRecordsOutputs},
+	FillMevShareBundleAddr:    {Name: "FILL_MEV_SHARE_BUNDLE", Function: "fillMevShareBundle", Inputs: fillMevShareBundleInputs, Outputs: fillMevShareBundleOutputs, RawOutput: true},
+	GetInsecureTimeAddr:       {Name: "GET_INSECURE_TIME", Function: "getInsecureTime", Inputs: getInsecureTimeInputs, Outputs: getInsecureTimeOutputs},
+	NewBuilderAddr:            {Name: "NEW_BUILDER", Function: "newBuilder", Inputs: newBuilderInputs, Outputs: newBuilderOutputs},
+	NewDataRecordAddr:         {Name: "NEW_DATA_RECORD", Function: "newDataRecord", Inputs: newDataRecordInputs, Outputs: newDataRecordOutputs},
+	PrivateKeyGenAddr:         {Name: "PRIVATE_KEY_GEN", Function: "privateKeyGen", Inputs: privateKeyGenInputs, Outputs: privateKeyGenOutputs},
+	RandomBytesAddr:           {Name: "RANDOM_BYTES", Function: "randomBytes", Inputs: randomBytesInputs, Outputs: randomBytesOutputs},
+	SignEthTransactionAddr:    {Name: "SIGN_ETH_TRANSACTION", Function: "signEthTransaction", Inputs: signEthTransactionInputs, Outputs: signEthTransactionOutputs},
+	SignMessageAddr:           {Name: "SIGN_MESSAGE", Function: "signMessage", Inputs: signMessageInputs, Outputs: signMessageOutputs},
+	SimulateBundleAddr:        {Name: "SIMULATE_BUNDLE", Function: "simulateBundle", Inputs: simulateBundleInputs, Outputs: simulateBundleOutputs},
+	SimulateTransactionAddr:   {Name: "SIMULATE_TRANSACTION", Function: "simulateTransaction", Inputs: simulateTransactionInputs, Outputs: simulateTransactionOutputs},
+	SubmitBundleJsonRPCAddr:   {Name: "SUBMIT_BUNDLE_JSON_RPC", Function: "submitBundleJsonRPC", Inputs: submitBundleJsonRPCInputs, Outputs: submitBundleJsonRPCOutputs, RawOutput: true},
+	SubmitEthBlockToRelayAddr: {Name: "SUBMIT_ETH_BLOCK_TO_RELAY", Function: "submitEthBlockToRelay", Inputs: submitEthBlockToRelayInputs, Outputs: submitEthBlockToRelayOutputs, RawOutput: true},
+}
+
+var (
+	isConfidentialInputs         = mustArguments(`[]`)
+	isConfidentialOutputs        = mustArguments(`[{"name":"b","type":"bool","internalType":"bool"}]`)
+	aesDecryptInputs             = mustArguments(`[{"name":"key","type":"bytes","internalType":"bytes"},{"name":"ciphertext","type":"bytes","internalType":"bytes"}]`)
+	aesDecryptOutputs            = mustArguments(`[{"name":"message","type":"bytes","internalType":"bytes"}]`)
+	aesEncryptInputs             = mustArguments(`[{"name":"key","type":"bytes","internalType":"bytes"},{"name":"message","type":"bytes","internalType":"bytes"}]`)
+	aesEncryptOutputs            = mustArguments(`[{"name":"ciphertext","type":"bytes","internalType":"bytes"}]`)
+	buildEthBlockInputs          = mustArguments(`[{"name":"blockArgs","type":"tuple","internalType":"struct Suave.BuildBlockArgs","components":[{"name":"slot","type":"uint64","internalType":"uint64"},{"name":"proposerPubkey","type":"bytes","internalType":"bytes"},{"name":"parent","type":"bytes32","internalType":"bytes32"},{"name":"timestamp","type":"uint64","internalType":"uint64"},{"name":"feeRecipient","type":"address","internalType":"address"},{"name":"gasLimit","type":"uint64","internalType":"uint64"},{"name":"random","type":"bytes32","internalType":"bytes32"},{"name":"withdrawals","type":"tuple[]","internalType":"struct Suave.Withdrawal[]","components":[{"name":"index","type":"uint64","internalType":"uint64"},{"name":"validator","type":"uint64","internalType":"uint64"},{"name":"Address","type":"address","internalType":"address"},{"name":"amount","type":"uint64","internalType":"uint64"}]},{"name":"extra","type":"bytes","internalType":"bytes"},{"name":"beaconRoot","type":"bytes32","internalType":"bytes32"},{"name":"fillPending","type":"bool","internalType":"bool"}]},{"name":"dataId","type":"bytes16","internalType":"Suave.DataId"},{"name":"relayUrl","type":"string","internalType":"string"}]`)
+	buildEthBlockOutputs         = mustArguments(`[{"name":"blockBid","type":"bytes","internalType":"bytes"},{"name":"executionPayload","type":"bytes","internalType":"bytes"}]`)
+	buildEthBlockToInputs        = mustArguments(`[{"name":"executionNodeURL","type":"string","internalType":"string"},{"name":"blockArgs","type":"tuple","internalType":"struct Suave.BuildBlockArgs","components":[{"name":"slot","type":"uint64","internalType":"uint64"},{"name":"proposerPubkey","type":"bytes","internalType":"bytes"},{"name":"parent","type":"bytes32","internalType":"bytes32"},{"name":"timestamp","type":"uint64","internalType":"uint64"},{"name":"feeRecipient","type":"address","internalType":"address"},{"name":"gasLimit","type":"uint64","internalType":"uint64"},{"name":"random","type":"bytes32","internalType":"bytes32"},{"name":"withdrawals","type":"tuple[]","internalType":"struct Suave.Withdrawal[]","components":[{"name":"index","type":"uint64","internalType":"uint64"},{"name":"validator","type":"uint64","internalType":"uint64"},{"name":"Address","type":"address","internalType":"address"},{"name":"amount","type":"uint64","internalType":"uint64"}]},{"name":"extra","type":"bytes","internalType":"bytes"},{"name":"beaconRoot","type":"bytes32","internalType":"bytes32"},{"name":"fillPending","type":"bool","internalType":"bool"}]},{"name":"dataId","type":"bytes16","internalType":"Suave.DataId"},{"name":"relayUrl","type":"string","internalType":"string"}]`)
+	buildEthBlockToOutputs       = mustArguments(`[{"name":"blockBid","type":"bytes","internalType":"bytes"},{"name":"executionPayload","type":"bytes","internalType":"bytes"}]`)
+	confidentialRetrieveInputs   = mustArguments(`[{"name":"dataId","type":"bytes16","internalType":"Suave.DataId"},{"name":"key","type":"string","internalType":"string"}]`)
+	confidentialRetrieveOutputs  = mustArguments(`[{"name":"value","type":"bytes","internalType":"bytes"}]`)
+	confidentialStoreInputs      = mustArguments(`[{"name":"dataId","type":"bytes16","internalType":"Suave.DataId"},{"name":"key","type":"string","internalType":"string"},{"name":"value","type":"bytes","internalType":"bytes"}]`)
+	confidentialStoreOutputs     = mustArguments(`[]`)
+	contextGetInputs             = mustArguments(`[{"name":"key","type":"string","internalType":"string"}]`)
+	contextGetOutputs            = mustArguments(`[{"name":"value","type":"bytes","internalType":"bytes"}]`)
+	doHTTPRequestInputs          = mustArguments(`[{"name":"request","type":"tuple","internalType":"struct Suave.HttpRequest","components":[{"name":"url","type":"string","internalType":"string"},{"name":"method","type":"string","internalType":"string"},{"name":"headers","type":"string[]","internalType":"string[]"},{"name":"body","type":"bytes","internalType":"bytes"},{"name":"withFlashbotsSignature","type":"bool","internalType":"bool"},{"name":"timeout","type":"uint64","internalType":"uint64"}]}]`)
+	doHTTPRequestOutputs         = mustArguments(`[{"name":"httpResponse","type":"bytes","internalType":"bytes"}]`)
+	doHTTPRequest2Inputs         = mustArguments(`[{"name":"request","type":"tuple","internalType":"struct Suave.HttpRequest","components":[{"name":"url","type":"string","internalType":"string"},{"name":"method","type":"string","internalType":"string"},{"name":"headers","type":"string[]","internalType":"string[]"},{"name":"body","type":"bytes","internalType":"bytes"},{"name":"withFlashbotsSignature","type":"bool","internalType":"bool"},{"name":"timeout","type":"uint64","internalType":"uint64"}]}]`)
+	doHTTPRequest2Outputs        = mustArguments(`[{"name":"httpResponse","type":"tuple","internalType":"struct Suave.HttpResponse","components":[{"name":"status","type":"uint64","internalType":"uint64"},{"name":"body","type":"bytes","internalType":"bytes"},{"name":"error","type":"bytes","internalType":"bytes"}]}]`)
+	ethcallInputs                = mustArguments(`[{"name":"contractAddr","type":"address","internalType":"address"},{"name":"input1","type":"bytes","internalType":"bytes"}]`)
+	ethcallOutputs               = mustArguments(`[{"name":"callOutput","type":"bytes","internalType":"bytes"}]`)
+	extractHintInputs            = mustArguments(`[{"name":"bundleData","type":"bytes","internalType":"bytes"}]`)
+	extractHintOutputs           = mustArguments(`[{"name":"hints","type":"bytes","internalType":"bytes"}]`)
+	fetchDataRecordsInputs       = mustArguments(`[{"name":"cond","type":"uint64","internalType":"uint64"},{"name":"namespace","type":"string","internalType":"string"}]`)
+	fetchDataRecordsOutputs      = mustArguments(`[{"name":"dataRecords","type":"tuple[]","internalType":"struct Suave.DataRecord[]","components":[{"name":"id","type":"bytes16","internalType":"Suave.DataId"},{"name":"salt","type":"bytes16","internalType":"Suave.DataId"},{"name":"decryptionCondition","type":"uint64","internalType":"uint64"},{"name":"allowedPeekers","type":"address[]","internalType":"address[]"},{"name":"allowedStores","type":"address[]","internalType":"address[]"},{"name":"version","type":"string","internalType":"string"}]}]`)
+	fillMevShareBundleInputs     = mustArguments(`[{"name":"dataId","type":"bytes16","internalType":"Suave.DataId"}]`)
+	fillMevShareBundleOutputs    = mustArguments(`[{"name":"encodedBundle","type":"bytes","internalType":"bytes"}]`)
+	getInsecureTimeInputs        = mustArguments(`[]`)
+	getInsecureTimeOutputs       = mustArguments(`[{"name":"time","type":"uint256","internalType":"uint256"}]`)
+	newBuilderInputs             = mustArguments(`[]`)
+	newBuilderOutputs            = mustArguments(`[{"name":"sessionid","type":"string","internalType":"string"}]`)
+	newDataRecordInputs          = mustArguments(`[{"name":"decryptionCondition","type":"uint64","internalType":"uint64"},{"name":"allowedPeekers","type":"address[]","internalType":"address[]"},{"name":"allowedStores","type":"address[]","internalType":"address[]"},{"name":"dataType","type":"string","internalType":"string"}]`)
+	newDataRecordOutputs         = mustArguments(`[{"name":"dataRecord","type":"tuple","internalType":"struct Suave.DataRecord","components":[{"name":"id","type":"bytes16","internalType":"Suave.DataId"},{"name":"salt","type":"bytes16","internalType":"Suave.DataId"},{"name":"decryptionCondition","type":"uint64","internalType":"uint64"},{"name":"allowedPeekers","type":"address[]","internalType":"address[]"},{"name":"allowedStores","type":"address[]","internalType":"address[]"},{"name":"version","type":"string","internalType":"string"}]}]`)
+	privateKeyGenInputs          = mustArguments(`[{"name":"crypto","type":"uint8","internalType":"enum Suave.CryptoSignature"}]`)
+	privateKeyGenOutputs         = mustArguments(`[{"name":"privateKey","type":"string","internalType":"string"}]`)
+	randomBytesInputs            = mustArguments(`[{"name":"numBytes","type":"uint8","internalType":"uint8"}]`)
+	randomBytesOutputs           = mustArguments(`[{"name":"value","type":"bytes","internalType":"bytes"}]`)
+	signEthTransactionInputs     = mustArguments(`[{"name":"txn","type":"bytes","internalType":"bytes"},{"name":"chainId","type":"string","internalType":"string"},{"name":"signingKey","type":"string","internalType":"string"}]`)
+	signEthTransactionOutputs    = mustArguments(`[{"name":"signedTxn","type":"bytes","internalType":"bytes"}]`)
+	signMessageInputs            = mustArguments(`[{"name":"digest","type":"bytes","internalType":"bytes"},{"name":"crypto","type":"uint8","internalType":"enum Suave.CryptoSignature"},{"name":"signingKey","type":"string","internalType":"string"}]`)
+	signMessageOutputs           = mustArguments(`[{"name":"signature","type":"bytes","internalType":"bytes"}]`)
+	simulateBundleInputs         = mustArguments(`[{"name":"bundleData","type":"bytes","internalType":"bytes"}]`)
+	simulateBundleOutputs        = mustArguments(`[{"name":"effectiveGasPrice","type":"uint64","internalType":"uint64"}]`)
+	simulateTransactionInputs    = mustArguments(`[{"name":"sessionid","type":"string","internalType":"string"},{"name":"txn","type":"bytes","internalType":"bytes"}]`)
+	simulateTransactionOutputs   = mustArguments(`[{"name":"simulationResult","type":"tuple","internalType":"struct Suave.SimulateTransactionResult","components":[{"name":"egp","type":"uint64","internalType":"uint64"},{"name":"logs","type":"tuple[]","internalType":"struct Suave.SimulatedLog[]","components":[{"name":"data","type":"bytes","internalType":"bytes"},{"name":"addr","type":"address","internalType":"address"},{"name":"topics","type":"bytes32[]","internalType":"bytes32[]"}]},{"name":"success","type":"bool","internalType":"bool"},{"name":"error","type":"string","internalType":"string"}]}]`)
+	submitBundleJsonRPCInputs    = mustArguments(`[{"name":"url","type":"string","internalType":"string"},{"name":"method","type":"string","internalType":"string"},{"name":"params","type":"bytes","internalType":"bytes"}]`)
+	submitBundleJsonRPCOutputs   = mustArguments(`[{"name":"errorMessage","type":"bytes","internalType":"bytes"}]`)
+	submitEthBlockToRelayInputs  = mustArguments(`[{"name":"relayUrl","type":"string","internalType":"string"},{"name":"builderBid","type":"bytes","internalType":"bytes"}]`)
+	submitEthBlockToRelayOutputs = mustArguments(`[{"name":"blockBid","type":"bytes","internalType":"bytes"}]`)
+)
+
+// PackIsConfidentialInputs encodes the inputs of the IS_CONFIDENTIAL_ADDR precompile.
+// Returns whether execution is off- or on-chain
+func PackIsConfidentialInputs() ([]byte, error) {
+	return isConfidentialInputs.Pack()
+}
+
+// UnpackIsConfidentialInputs decodes the inputs of the IS_CONFIDENTIAL_ADDR precompile.
+func UnpackIsConfidentialInputs(data []byte) (err error) {
+	_, err = isConfidentialInputs.Unpack(data)
+	return
+}
+
+// PackIsConfidentialOutputs encodes the outputs of the IS_CONFIDENTIAL_ADDR precompile.
+func PackIsConfidentialOutputs(b bool) ([]byte, error) {
+	return isConfidentialOutputs.Pack(b)
+}
+
+// UnpackIsConfidentialOutputs decodes the outputs of the IS_CONFIDENTIAL_ADDR precompile.
+func UnpackIsConfidentialOutputs(data []byte) (b bool, err error) {
+	values, err := isConfidentialOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	b = *abi.ConvertType(values[0], new(bool)).(*bool)
+	return
+}
+
+// PackAesDecryptInputs encodes the inputs of the AES_DECRYPT precompile.
+// Decrypts a message using given bytes as a cipher.
+func PackAesDecryptInputs(key []byte, ciphertext []byte) ([]byte, error) {
+	return aesDecryptInputs.Pack(key, ciphertext)
+}
+
+// UnpackAesDecryptInputs decodes the inputs of the AES_DECRYPT precompile.
+func UnpackAesDecryptInputs(data []byte) (key []byte, ciphertext []byte, err error) {
+	values, err := aesDecryptInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	key = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	ciphertext = *abi.ConvertType(values[1], new([]byte)).(*[]byte)
+	return
+}
+
+// PackAesDecryptOutputs encodes the outputs of the AES_DECRYPT precompile.
+func PackAesDecryptOutputs(message []byte) ([]byte, error) {
+	return aesDecryptOutputs.Pack(message)
+}
+
+// UnpackAesDecryptOutputs decodes the outputs of the AES_DECRYPT precompile.
+func UnpackAesDecryptOutputs(data []byte) (message []byte, err error) {
+	values, err := aesDecryptOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	message = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	return
+}
+
+// PackAesEncryptInputs encodes the inputs of the AES_ENCRYPT precompile.
+// Encrypts a message using given bytes as a cipher.
+func PackAesEncryptInputs(key []byte, message []byte) ([]byte, error) {
+	return aesEncryptInputs.Pack(key, message)
+}
+
+// UnpackAesEncryptInputs decodes the inputs of the AES_ENCRYPT precompile.
+func UnpackAesEncryptInputs(data []byte) (key []byte, message []byte, err error) {
+	values, err := aesEncryptInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	key = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	message = *abi.ConvertType(values[1], new([]byte)).(*[]byte)
+	return
+}
+
+// PackAesEncryptOutputs encodes the outputs of the AES_ENCRYPT precompile.
+func PackAesEncryptOutputs(ciphertext []byte) ([]byte, error) {
+	return aesEncryptOutputs.Pack(ciphertext)
+}
+
+// UnpackAesEncryptOutputs decodes the outputs of the AES_ENCRYPT precompile.
+func UnpackAesEncryptOutputs(data []byte) (ciphertext []byte, err error) {
+	values, err := aesEncryptOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	ciphertext = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	return
+}
+
+// PackBuildEthBlockInputs encodes the inputs of the BUILD_ETH_BLOCK precompile.
+// Constructs an Ethereum block based on the provided data records. No blobs are returned.
+func PackBuildEthBlockInputs(blockArgs BuildBlockArgs, dataId DataId, relayUrl string) ([]byte, error) {
+	return buildEthBlockInputs.Pack(blockArgs, dataId, relayUrl)
+}
+
+// UnpackBuildEthBlockInputs decodes the inputs of the BUILD_ETH_BLOCK precompile.
+func UnpackBuildEthBlockInputs(data []byte) (blockArgs BuildBlockArgs, dataId DataId, relayUrl string, err error) {
+	values, err := buildEthBlockInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	blockArgs = *abi.ConvertType(values[0], new(BuildBlockArgs)).(*BuildBlockArgs)
+	dataId = *abi.ConvertType(values[1], new(DataId)).(*DataId)
+	relayUrl = *abi.ConvertType(values[2], new(string)).(*string)
+	return
+}
+
+// PackBuildEthBlockOutputs encodes the outputs of the BUILD_ETH_BLOCK precompile.
+func PackBuildEthBlockOutputs(blockBid []byte, executionPayload []byte) ([]byte, error) {
+	return buildEthBlockOutputs.Pack(blockBid, executionPayload)
+}
+
+// UnpackBuildEthBlockOutputs decodes the outputs of the BUILD_ETH_BLOCK precompile.
+func UnpackBuildEthBlockOutputs(data []byte) (blockBid []byte, executionPayload []byte, err error) {
+	values, err := buildEthBlockOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	blockBid = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	executionPayload = *abi.ConvertType(values[1], new([]byte)).(*[]byte)
+	return
+}
+
+// PackBuildEthBlockToInputs encodes the inputs of the BUILD_ETH_BLOCK_TO precompile.
+// Constructs an Ethereum block based on the provided data records. No blobs are returned.
+func PackBuildEthBlockToInputs(executionNodeURL string, blockArgs BuildBlockArgs, dataId DataId, relayUrl string) ([]byte, error) {
+	return buildEthBlockToInputs.Pack(executionNodeURL, blockArgs, dataId, relayUrl)
+}
+
+// UnpackBuildEthBlockToInputs decodes the inputs of the BUILD_ETH_BLOCK_TO precompile.
+func UnpackBuildEthBlockToInputs(data []byte) (executionNodeURL string, blockArgs BuildBlockArgs, dataId DataId, relayUrl string, err error) {
+	values, err := buildEthBlockToInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	executionNodeURL = *abi.ConvertType(values[0], new(string)).(*string)
+	blockArgs = *abi.ConvertType(values[1], new(BuildBlockArgs)).(*BuildBlockArgs)
+	dataId = *abi.ConvertType(values[2], new(DataId)).(*DataId)
+	relayUrl = *abi.ConvertType(values[3], new(string)).(*string)
+	return
+}
+
+// PackBuildEthBlockToOutputs encodes the outputs of the BUILD_ETH_BLOCK_TO precompile.
+func PackBuildEthBlockToOutputs(blockBid []byte, executionPayload []byte) ([]byte, error) {
+	return buildEthBlockToOutputs.Pack(blockBid, executionPayload)
+}
+
+// UnpackBuildEthBlockToOutputs decodes the outputs of the BUILD_ETH_BLOCK_TO precompile.
+func UnpackBuildEthBlockToOutputs(data []byte) (blockBid []byte, executionPayload []byte, err error) {
+	values, err := buildEthBlockToOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	blockBid = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	executionPayload = *abi.ConvertType(values[1], new([]byte)).(*[]byte)
+	return
+}
+
+// PackConfidentialRetrieveInputs encodes the inputs of the CONFIDENTIAL_RETRIEVE precompile.
+// Retrieves data from the confidential store. Also mandates the caller's presence in the `AllowedPeekers` list.
+func PackConfidentialRetrieveInputs(dataId DataId, key string) ([]byte, error) {
+	return confidentialRetrieveInputs.Pack(dataId, key)
+}
+
+// UnpackConfidentialRetrieveInputs decodes the inputs of the CONFIDENTIAL_RETRIEVE precompile.
+func UnpackConfidentialRetrieveInputs(data []byte) (dataId DataId, key string, err error) {
+	values, err := confidentialRetrieveInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	dataId = *abi.ConvertType(values[0], new(DataId)).(*DataId)
+	key = *abi.ConvertType(values[1], new(string)).(*string)
+	return
+}
+
+// PackConfidentialRetrieveOutputs encodes the outputs of the CONFIDENTIAL_RETRIEVE precompile.
+// The precompile returns the output as is, without abi encoding.
+func PackConfidentialRetrieveOutputs(value []byte) ([]byte, error) {
+	return value, nil
+}
+
+// UnpackConfidentialRetrieveOutputs decodes the outputs of the CONFIDENTIAL_RETRIEVE precompile.
+// The precompile returns the output as is, without abi encoding.
+func UnpackConfidentialRetrieveOutputs(data []byte) (value []byte, err error) {
+	value = data
+	return
+}
+
+// PackConfidentialStoreInputs encodes the inputs of the CONFIDENTIAL_STORE precompile.
+// Stores data in the confidential store. Requires the caller to be part of the `AllowedPeekers` for the associated data record.
+func PackConfidentialStoreInputs(dataId DataId, key string, value []byte) ([]byte, error) {
+	return confidentialStoreInputs.Pack(dataId, key, value)
+}
+
+// UnpackConfidentialStoreInputs decodes the inputs of the CONFIDENTIAL_STORE precompile.
+func UnpackConfidentialStoreInputs(data []byte) (dataId DataId, key string, value []byte, err error) {
+	values, err := confidentialStoreInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	dataId = *abi.ConvertType(values[0], new(DataId)).(*DataId)
+	key = *abi.ConvertType(values[1], new(string)).(*string)
+	value = *abi.ConvertType(values[2], new([]byte)).(*[]byte)
+	return
+}
+
+// PackConfidentialStoreOutputs encodes the outputs of the CONFIDENTIAL_STORE precompile.
+func PackConfidentialStoreOutputs() ([]byte, error) {
+	return confidentialStoreOutputs.Pack()
+}
+
+// UnpackConfidentialStoreOutputs decodes the outputs of the CONFIDENTIAL_STORE precompile.
+func UnpackConfidentialStoreOutputs(data []byte) (err error) {
+	_, err = confidentialStoreOutputs.Unpack(data)
+	return
+}
+
+// PackContextGetInputs encodes the inputs of the CONTEXT_GET precompile.
+// Retrieves a value from the context
+func PackContextGetInputs(key string) ([]byte, error) {
+	return contextGetInputs.Pack(key)
+}
+
+// UnpackContextGetInputs decodes the inputs of the CONTEXT_GET precompile.
+func UnpackContextGetInputs(data []byte) (key string, err error) {
+	values, err := contextGetInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	key = *abi.ConvertType(values[0], new(string)).(*string)
+	return
+}
+
+// PackContextGetOutputs encodes the outputs of the CONTEXT_GET precompile.
+func PackContextGetOutputs(value []byte) ([]byte, error) {
+	return contextGetOutputs.Pack(value)
+}
+
+// UnpackContextGetOutputs decodes the outputs of the CONTEXT_GET precompile.
+func UnpackContextGetOutputs(data []byte) (value []byte, err error) {
+	values, err := contextGetOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	value = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	return
+}
+
+// PackDoHTTPRequestInputs encodes the inputs of the DO_HTTPREQUEST precompile.
+// Performs an HTTP request and returns the response. `request` is the request to perform.
+func PackDoHTTPRequestInputs(request HttpRequest) ([]byte, error) {
+	return doHTTPRequestInputs.Pack(request)
+}
+
+// UnpackDoHTTPRequestInputs decodes the inputs of the DO_HTTPREQUEST precompile.
+func UnpackDoHTTPRequestInputs(data []byte) (request HttpRequest, err error) {
+	values, err := doHTTPRequestInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	request = *abi.ConvertType(values[0], new(HttpRequest)).(*HttpRequest)
+	return
+}
+
+// PackDoHTTPRequestOutputs encodes the outputs of the DO_HTTPREQUEST precompile.
+func PackDoHTTPRequestOutputs(httpResponse []byte) ([]byte, error) {
+	return doHTTPRequestOutputs.Pack(httpResponse)
+}
+
+// UnpackDoHTTPRequestOutputs decodes the outputs of the DO_HTTPREQUEST precompile.
+func UnpackDoHTTPRequestOutputs(data []byte) (httpResponse []byte, err error) {
+	values, err := doHTTPRequestOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	httpResponse = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	return
+}
+
+// PackDoHTTPRequest2Inputs encodes the inputs of the DO_HTTPREQUEST2 precompile.
+// Performs an HTTP request and returns the response. `request` is the request to perform.
+func PackDoHTTPRequest2Inputs(request HttpRequest) ([]byte, error) {
+	return doHTTPRequest2Inputs.Pack(request)
+}
+
+// UnpackDoHTTPRequest2Inputs decodes the inputs of the DO_HTTPREQUEST2 precompile.
+func UnpackDoHTTPRequest2Inputs(data []byte) (request HttpRequest, err error) {
+	values, err := doHTTPRequest2Inputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	request = *abi.ConvertType(values[0], new(HttpRequest)).(*HttpRequest)
+	return
+}
+
+// PackDoHTTPRequest2Outputs encodes the outputs of the DO_HTTPREQUEST2 precompile.
+func PackDoHTTPRequest2Outputs(httpResponse HttpResponse) ([]byte, error) {
+	return doHTTPRequest2Outputs.Pack(httpResponse)
+}
+
+// UnpackDoHTTPRequest2Outputs decodes the outputs of the DO_HTTPREQUEST2 precompile.
+func UnpackDoHTTPRequest2Outputs(data []byte) (httpResponse HttpResponse, err error) {
+	values, err := doHTTPRequest2Outputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	httpResponse = *abi.ConvertType(values[0], new(HttpResponse)).(*HttpResponse)
+	return
+}
+
+// PackEthcallInputs encodes the inputs of the ETHCALL precompile.
+// Uses the `eth_call` JSON RPC method to let you simulate a function call and return the response.
+func PackEthcallInputs(contractAddr common.Address, input1 []byte) ([]byte, error) {
+	return ethcallInputs.Pack(contractAddr, input1)
+}
+
+// UnpackEthcallInputs decodes the inputs of the ETHCALL precompile.
+func UnpackEthcallInputs(data []byte) (contractAddr common.Address, input1 []byte, err error) {
+	values, err := ethcallInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	contractAddr = *abi.ConvertType(values[0], new(common.Address)).(*common.Address)
+	input1 = *abi.ConvertType(values[1], new([]byte)).(*[]byte)
+	return
+}
+
+// PackEthcallOutputs encodes the outputs of the ETHCALL precompile.
+func PackEthcallOutputs(callOutput []byte) ([]byte, error) {
+	return ethcallOutputs.Pack(callOutput)
+}
+
+// UnpackEthcallOutputs decodes the outputs of the ETHCALL precompile.
+func UnpackEthcallOutputs(data []byte) (callOutput []byte, err error) {
+	values, err := ethcallOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	callOutput = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	return
+}
+
+// PackExtractHintInputs encodes the inputs of the EXTRACT_HINT precompile.
+// Interprets the bundle data and extracts hints, such as the `To` address and calldata.
+func PackExtractHintInputs(bundleData []byte) ([]byte, error) {
+	return extractHintInputs.Pack(bundleData)
+}
+
+// UnpackExtractHintInputs decodes the inputs of the EXTRACT_HINT precompile.
+func UnpackExtractHintInputs(data []byte) (bundleData []byte, err error) {
+	values, err := extractHintInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	bundleData = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	return
+}
+
+// PackExtractHintOutputs encodes the outputs of the EXTRACT_HINT precompile.
+// The precompile returns the output as is, without abi encoding.
+func PackExtractHintOutputs(hints []byte) ([]byte, error) {
+	return hints, nil
+}
+
+// UnpackExtractHintOutputs decodes the outputs of the EXTRACT_HINT precompile.
+// The precompile returns the output as is, without abi encoding.
+func UnpackExtractHintOutputs(data []byte) (hints []byte, err error) {
+	hints = data
+	return
+}
+
+// PackFetchDataRecordsInputs encodes the inputs of the FETCH_DATA_RECORDS precompile.
+// Retrieves all data records correlating with a specified decryption condition and namespace
+func PackFetchDataRecordsInputs(cond uint64, namespace string) ([]byte, error) {
+	return fetchDataRecordsInputs.Pack(cond, namespace)
+}
+
+// UnpackFetchDataRecordsInputs decodes the inputs of the FETCH_DATA_RECORDS precompile.
+func UnpackFetchDataRecordsInputs(data []byte) (cond uint64, namespace string, err error) {
+	values, err := fetchDataRecordsInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	cond = *abi.ConvertType(values[0], new(uint64)).(*uint64)
+	namespace = *abi.ConvertType(values[1], new(string)).(*string)
+	return
+}
+
+// PackFetchDataRecordsOutputs encodes the outputs of the FETCH_DATA_RECORDS precompile.
+func PackFetchDataRecordsOutputs(dataRecords []DataRecord) ([]byte, error) {
+	return fetchDataRecordsOutputs.Pack(dataRecords)
+}
+
+// UnpackFetchDataRecordsOutputs decodes the outputs of the FETCH_DATA_RECORDS precompile.
+func UnpackFetchDataRecordsOutputs(data []byte) (dataRecords []DataRecord, err error) {
+	values, err := fetchDataRecordsOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	dataRecords = *abi.ConvertType(values[0], new([]DataRecord)).(*[]DataRecord)
+	return
+}
+
+// PackFillMevShareBundleInputs encodes the inputs of the FILL_MEV_SHARE_BUNDLE precompile.
+// Joins the user's transaction and with the backrun, and returns encoded mev-share bundle. The bundle is ready to be sent via `SubmitBundleJsonRPC`.
+func PackFillMevShareBundleInputs(dataId DataId) ([]byte, error) {
+	return fillMevShareBundleInputs.Pack(dataId)
+}
+
+// UnpackFillMevShareBundleInputs decodes the inputs of the FILL_MEV_SHARE_BUNDLE precompile.
+func UnpackFillMevShareBundleInputs(data []byte) (dataId DataId, err error) {
+	values, err := fillMevShareBundleInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	dataId = *abi.ConvertType(values[0], new(DataId)).(*DataId)
+	return
+}
+
+// PackFillMevShareBundleOutputs encodes the outputs of the FILL_MEV_SHARE_BUNDLE precompile.
+// The precompile returns the output as is, without abi encoding.
+func PackFillMevShareBundleOutputs(encodedBundle []byte) ([]byte, error) {
+	return encodedBundle, nil
+}
+
+// UnpackFillMevShareBundleOutputs decodes the outputs of the FILL_MEV_SHARE_BUNDLE precompile.
+// The precompile returns the output as is, without abi encoding.
+func UnpackFillMevShareBundleOutputs(data []byte) (encodedBundle []byte, err error) {
+	encodedBundle = data
+	return
+}
+
+// PackGetInsecureTimeInputs encodes the inputs of the GET_INSECURE_TIME precompile.
+// Returns the current Kettle Unix time in milliseconds. Insecure because it assumes trust in Kettle's clock.
+func PackGetInsecureTimeInputs() ([]byte, error) {
+	return getInsecureTimeInputs.Pack()
+}
+
+// UnpackGetInsecureTimeInputs decodes the inputs of the GET_INSECURE_TIME precompile.
+func UnpackGetInsecureTimeInputs(data []byte) (err error) {
+	_, err = getInsecureTimeInputs.Unpack(data)
+	return
+}
+
+// PackGetInsecureTimeOutputs encodes the outputs of the GET_INSECURE_TIME precompile.
+func PackGetInsecureTimeOutputs(time *big.Int) ([]byte, error) {
+	return getInsecureTimeOutputs.Pack(time)
+}
+
+// UnpackGetInsecureTimeOutputs decodes the outputs of the GET_INSECURE_TIME precompile.
+func UnpackGetInsecureTimeOutputs(data []byte) (time *big.Int, err error) {
+	values, err := getInsecureTimeOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	time = *abi.ConvertType(values[0], new(*big.Int)).(**big.Int)
+	return
+}
+
+// PackNewBuilderInputs encodes the inputs of the NEW_BUILDER precompile.
+// Initializes a new remote builder session
+func PackNewBuilderInputs() ([]byte, error) {
+	return newBuilderInputs.Pack()
+}
+
+// UnpackNewBuilderInputs decodes the inputs of the NEW_BUILDER precompile.
+func UnpackNewBuilderInputs(data []byte) (err error) {
+	_, err = newBuilderInputs.Unpack(data)
+	return
+}
+
+// PackNewBuilderOutputs encodes the outputs of the NEW_BUILDER precompile.
+func PackNewBuilderOutputs(sessionid string) ([]byte, error) {
+	return newBuilderOutputs.Pack(sessionid)
+}
+
+// UnpackNewBuilderOutputs decodes the outputs of the NEW_BUILDER precompile.
+func UnpackNewBuilderOutputs(data []byte) (sessionid string, err error) {
+	values, err := newBuilderOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	sessionid = *abi.ConvertType(values[0], new(string)).(*string)
+	return
+}
+
+// PackNewDataRecordInputs encodes the inputs of the NEW_DATA_RECORD precompile.
+// Initializes data records within the ConfidentialStore. Prior to storing data, all data records should undergo initialization via this precompile.
+func PackNewDataRecordInputs(decryptionCondition uint64, allowedPeekers []common.Address, allowedStores []common.Address, dataType string) ([]byte, error) {
+	return newDataRecordInputs.Pack(decryptionCondition, allowedPeekers, allowedStores, dataType)
+}
+
+// UnpackNewDataRecordInputs decodes the inputs of the NEW_DATA_RECORD precompile.
+func UnpackNewDataRecordInputs(data []byte) (decryptionCondition uint64, allowedPeekers []common.Address, allowedStores []common.Address, dataType string, err error) {
+	values, err := newDataRecordInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	decryptionCondition = *abi.ConvertType(values[0], new(uint64)).(*uint64)
+	allowedPeekers = *abi.ConvertType(values[1], new([]common.Address)).(*[]common.Address)
+	allowedStores = *abi.ConvertType(values[2], new([]common.Address)).(*[]common.Address)
+	dataType = *abi.ConvertType(values[3], new(string)).(*string)
+	return
+}
+
+// PackNewDataRecordOutputs encodes the outputs of the NEW_DATA_RECORD precompile.
+func PackNewDataRecordOutputs(dataRecord DataRecord) ([]byte, error) {
+	return newDataRecordOutputs.Pack(dataRecord)
+}
+
+// UnpackNewDataRecordOutputs decodes the outputs of the NEW_DATA_RECORD precompile.
+func UnpackNewDataRecordOutputs(data []byte) (dataRecord DataRecord, err error) {
+	values, err := newDataRecordOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	dataRecord = *abi.ConvertType(values[0], new(DataRecord)).(*DataRecord)
+	return
+}
+
+// PackPrivateKeyGenInputs encodes the inputs of the PRIVATE_KEY_GEN precompile.
+// Generates a private key in ECDA secp256k1 format
+func PackPrivateKeyGenInputs(crypto CryptoSignature) ([]byte, error) {
+	return privateKeyGenInputs.Pack(crypto)
+}
+
+// UnpackPrivateKeyGenInputs decodes the inputs of the PRIVATE_KEY_GEN precompile.
+func UnpackPrivateKeyGenInputs(data []byte) (crypto CryptoSignature, err error) {
+	values, err := privateKeyGenInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	crypto = CryptoSignature(*abi.ConvertType(values[0], new(uint8)).(*uint8))
+	return
+}
+
+// PackPrivateKeyGenOutputs encodes the outputs of the PRIVATE_KEY_GEN precompile.
+func PackPrivateKeyGenOutputs(privateKey string) ([]byte, error) {
+	return privateKeyGenOutputs.Pack(privateKey)
+}
+
+// UnpackPrivateKeyGenOutputs decodes the outputs of the PRIVATE_KEY_GEN precompile.
+func UnpackPrivateKeyGenOutputs(data []byte) (privateKey string, err error) {
+	values, err := privateKeyGenOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	privateKey = *abi.ConvertType(values[0], new(string)).(*string)
+	return
+}
+
+// PackRandomBytesInputs encodes the inputs of the RANDOM_BYTES precompile.
+// Generates a number of random bytes, given by the argument numBytes.
+func PackRandomBytesInputs(numBytes uint8) ([]byte, error) {
+	return randomBytesInputs.Pack(numBytes)
+}
+
+// UnpackRandomBytesInputs decodes the inputs of the RANDOM_BYTES precompile.
+func UnpackRandomBytesInputs(data []byte) (numBytes uint8, err error) {
+	values, err := randomBytesInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	numBytes = *abi.ConvertType(values[0], new(uint8)).(*uint8)
+	return
+}
+
+// PackRandomBytesOutputs encodes the outputs of the RANDOM_BYTES precompile.
+func PackRandomBytesOutputs(value []byte) ([]byte, error) {
+	return randomBytesOutputs.Pack(value)
+}
+
+// UnpackRandomBytesOutputs decodes the outputs of the RANDOM_BYTES precompile.
+func UnpackRandomBytesOutputs(data []byte) (value []byte, err error) {
+	values, err := randomBytesOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	value = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	return
+}
+
+// PackSignEthTransactionInputs encodes the inputs of the SIGN_ETH_TRANSACTION precompile.
+// Signs an Ethereum Transaction, 1559 or Legacy, and returns raw signed transaction bytes. `txn` is binary encoding of the transaction.
+func PackSignEthTransactionInputs(txn []byte, chainId string, signingKey string) ([]byte, error) {
+	return signEthTransactionInputs.Pack(txn, chainId, signingKey)
+}
+
+// UnpackSignEthTransactionInputs decodes the inputs of the SIGN_ETH_TRANSACTION precompile.
+func UnpackSignEthTransactionInputs(data []byte) (txn []byte, chainId string, signingKey string, err error) {
+	values, err := signEthTransactionInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	txn = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	chainId = *abi.ConvertType(values[1], new(string)).(*string)
+	signingKey = *abi.ConvertType(values[2], new(string)).(*string)
+	return
+}
+
+// PackSignEthTransactionOutputs encodes the outputs of the SIGN_ETH_TRANSACTION precompile.
+func PackSignEthTransactionOutputs(signedTxn []byte) ([]byte, error) {
+	return signEthTransactionOutputs.Pack(signedTxn)
+}
+
+// UnpackSignEthTransactionOutputs decodes the outputs of the SIGN_ETH_TRANSACTION precompile.
+func UnpackSignEthTransactionOutputs(data []byte) (signedTxn []byte, err error) {
+	values, err := signEthTransactionOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	signedTxn = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	return
+}
+
+// PackSignMessageInputs encodes the inputs of the SIGN_MESSAGE precompile.
+// Signs a message and returns the signature.
+func PackSignMessageInputs(digest []byte, crypto CryptoSignature, signingKey string) ([]byte, error) {
+	return signMessageInputs.Pack(digest, crypto, signingKey)
+}
+
+// UnpackSignMessageInputs decodes the inputs of the SIGN_MESSAGE precompile.
+func UnpackSignMessageInputs(data []byte) (digest []byte, crypto CryptoSignature, signingKey string, err error) {
+	values, err := signMessageInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	digest = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	crypto = CryptoSignature(*abi.ConvertType(values[1], new(uint8)).(*uint8))
+	signingKey = *abi.ConvertType(values[2], new(string)).(*string)
+	return
+}
+
+// PackSignMessageOutputs encodes the outputs of the SIGN_MESSAGE precompile.
+func PackSignMessageOutputs(signature []byte) ([]byte, error) {
+	return signMessageOutputs.Pack(signature)
+}
+
+// UnpackSignMessageOutputs decodes the outputs of the SIGN_MESSAGE precompile.
+func UnpackSignMessageOutputs(data []byte) (signature []byte, err error) {
+	values, err := signMessageOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	signature = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	return
+}
+
+// PackSimulateBundleInputs encodes the inputs of the SIMULATE_BUNDLE precompile.
+// Performs a simulation of the bundle by building a block that includes it.
+func PackSimulateBundleInputs(bundleData []byte) ([]byte, error) {
+	return simulateBundleInputs.Pack(bundleData)
+}
+
+// UnpackSimulateBundleInputs decodes the inputs of the SIMULATE_BUNDLE precompile.
+func UnpackSimulateBundleInputs(data []byte) (bundleData []byte, err error) {
+	values, err := simulateBundleInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	bundleData = *abi.ConvertType(values[0], new([]byte)).(*[]byte)
+	return
+}
+
+// PackSimulateBundleOutputs encodes the outputs of the SIMULATE_BUNDLE precompile.
+func PackSimulateBundleOutputs(effectiveGasPrice uint64) ([]byte, error) {
+	return simulateBundleOutputs.Pack(effectiveGasPrice)
+}
+
+// UnpackSimulateBundleOutputs decodes the outputs of the SIMULATE_BUNDLE precompile.
+func UnpackSimulateBundleOutputs(data []byte) (effectiveGasPrice uint64, err error) {
+	values, err := simulateBundleOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	effectiveGasPrice = *abi.ConvertType(values[0], new(uint64)).(*uint64)
+	return
+}
+
+// PackSimulateTransactionInputs encodes the inputs of the SIMULATE_TRANSACTION precompile.
+// Simulates a transaction on a remote builder session
+func PackSimulateTransactionInputs(sessionid string, txn []byte) ([]byte, error) {
+	return simulateTransactionInputs.Pack(sessionid, txn)
+}
+
+// UnpackSimulateTransactionInputs decodes the inputs of the SIMULATE_TRANSACTION precompile.
+func UnpackSimulateTransactionInputs(data []byte) (sessionid string, txn []byte, err error) {
+	values, err := simulateTransactionInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	sessionid = *abi.ConvertType(values[0], new(string)).(*string)
+	txn = *abi.ConvertType(values[1], new([]byte)).(*[]byte)
+	return
+}
+
+// PackSimulateTransactionOutputs encodes the outputs of the SIMULATE_TRANSACTION precompile.
+func PackSimulateTransactionOutputs(simulationResult SimulateTransactionResult) ([]byte, error) {
+	return simulateTransactionOutputs.Pack(simulationResult)
+}
+
+// UnpackSimulateTransactionOutputs decodes the outputs of the SIMULATE_TRANSACTION precompile.
+func UnpackSimulateTransactionOutputs(data []byte) (simulationResult SimulateTransactionResult, err error) {
+	values, err := simulateTransactionOutputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	simulationResult = *abi.ConvertType(values[0], new(SimulateTransactionResult)).(*SimulateTransactionResult)
+	return
+}
+
+// PackSubmitBundleJsonRPCInputs encodes the inputs of the SUBMIT_BUNDLE_JSON_RPC precompile.
+// Submits bytes as JSONRPC message to the specified URL with the specified method. As this call is intended for bundles, it also signs the params and adds `X-Flashbots-Signature` header, as usual with bundles. Regular eth bundles don't need any processing to be sent.
+func PackSubmitBundleJsonRPCInputs(url string, method string, params []byte) ([]byte, error) {
+	return submitBundleJsonRPCInputs.Pack(url, method, params)
+}
+
+// UnpackSubmitBundleJsonRPCInputs decodes the inputs of the SUBMIT_BUNDLE_JSON_RPC precompile.
+func UnpackSubmitBundleJsonRPCInputs(data []byte) (url string, method string, params []byte, err error) {
+	values, err := submitBundleJsonRPCInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	url = *abi.ConvertType(values[0], new(string)).(*string)
+	method = *abi.ConvertType(values[1], new(string)).(*string)
+	params = *abi.ConvertType(values[2], new([]byte)).(*[]byte)
+	return
+}
+
+// PackSubmitBundleJsonRPCOutputs encodes the outputs of the SUBMIT_BUNDLE_JSON_RPC precompile.
+// The precompile returns the output as is, without abi encoding.
+func PackSubmitBundleJsonRPCOutputs(errorMessage []byte) ([]byte, error) {
+	return errorMessage, nil
+}
+
+// UnpackSubmitBundleJsonRPCOutputs decodes the outputs of the SUBMIT_BUNDLE_JSON_RPC precompile.
+// The precompile returns the output as is, without abi encoding.
+func UnpackSubmitBundleJsonRPCOutputs(data []byte) (errorMessage []byte, err error) {
+	errorMessage = data
+	return
+}
+
+// PackSubmitEthBlockToRelayInputs encodes the inputs of the SUBMIT_ETH_BLOCK_TO_RELAY precompile.
+// Submits a given builderBid to a mev-boost relay.
+func PackSubmitEthBlockToRelayInputs(relayUrl string, builderBid []byte) ([]byte, error) {
+	return submitEthBlockToRelayInputs.Pack(relayUrl, builderBid)
+}
+
+// UnpackSubmitEthBlockToRelayInputs decodes the inputs of the SUBMIT_ETH_BLOCK_TO_RELAY precompile.
+func UnpackSubmitEthBlockToRelayInputs(data []byte) (relayUrl string, builderBid []byte, err error) {
+	values, err := submitEthBlockToRelayInputs.Unpack(data)
+	if err != nil {
+		return
+	}
+	relayUrl = *abi.ConvertType(values[0], new(string)).(*string)
+	builderBid = *abi.ConvertType(values[1], new([]byte)).(*[]byte)
+	return
+}
+
+// PackSubmitEthBlockToRelayOutputs encodes the outputs of the SUBMIT_ETH_BLOCK_TO_RELAY precompile.
+// The precompile returns the output as is, without abi encoding.
+func PackSubmitEthBlockToRelayOutputs(blockBid []byte) ([]byte, error) {
+	return blockBid, nil
+}
+
+// UnpackSubmitEthBlockToRelayOutputs decodes the outputs of the SUBMIT_ETH_BLOCK_TO_RELAY precompile.
+// The precompile returns the output as is, without abi encoding.
+func UnpackSubmitEthBlockToRelayOutputs(data []byte) (blockBid []byte, err error) {
+	blockBid = data
+	return
+}
+
+func mustArguments(str string) abi.Arguments {
+	var args abi.Arguments
+	if err := json.Unmarshal([]byte(str), &args); err != nil {
+		panic(err)
+	}
+	return args
+}
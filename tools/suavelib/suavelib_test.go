@@ -0,0 +1,155 @@
+package suavelib
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestPackUnpack_Struct(t *testing.T) {
+	args := BuildBlockArgs{
+		Slot:         1,
+		FeeRecipient: common.Address{0x1},
+		Withdrawals: []Withdrawal{
+			{Index: 1, Validator: 2, Address: common.Address{0x2}, Amount: 3},
+		},
+		Extra:      []byte{},
+		BeaconRoot: [32]byte{0x3},
+	}
+	dataID := DataId{0x1, 0x2}
+
+	data, err := PackBuildEthBlockInputs(args, dataID, "relay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args2, dataID2, relayUrl, err := UnpackBuildEthBlockInputs(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args.ProposerPubkey = []byte{}
+	if !reflect.DeepEqual(args, args2) {
+		t.Fatalf("unexpected block args %+v", args2)
+	}
+	if dataID != dataID2 || relayUrl != "relay" {
+		t.Fatal("unexpected data id or relay url")
+	}
+}
+
+func TestPackUnpack_Outputs(t *testing.T) {
+	records := []DataRecord{
+		{Id: DataId{0x1}, AllowedPeekers: []common.Address{{0x1}}, AllowedStores: []common.Address{}, Version: "a"},
+	}
+	data, err := PackFetchDataRecordsOutputs(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records2, err := UnpackFetchDataRecordsOutputs(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(records, records2) {
+		t.Fatalf("unexpected data records %+v", records2)
+	}
+
+	data, err = PackGetInsecureTimeOutputs(big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time, err := UnpackGetInsecureTimeOutputs(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Uint64() != 10 {
+		t.Fatalf("unexpected time %s", time)
+	}
+}
+
+func TestPackUnpack_RawOutput(t *testing.T) {
+	// the confidential retrieve precompile does not abi encode its output
+	data, err := PackConfidentialRetrieveOutputs([]byte{0x1, 0x2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{0x1, 0x2}) {
+		t.Fatalf("unexpected output %x", data)
+	}
+}
+
+func TestPrecompileNames(t *testing.T) {
+	if name := PrecompileNames[common.HexToAddress("0x000000000000000000000000000000007770000b")]; name != "RANDOM_BYTES" {
+		t.Fatalf("unexpected precompile name %s", name)
+	}
+}
+
+func TestParseSuaveGethCommit(t *testing.T) {
+	output := "Suave-geth\nVersion: 0.2.0-stable\nGit Commit: 6a4a7e5d\nArchitecture: amd64\n"
+	if commit := ParseSuaveGethCommit(output); commit != "6a4a7e5d" {
+		t.Fatalf("unexpected commit %s", commit)
+	}
+	if commit := ParseSuaveGethCommit("Version: 0.2.0-stable\n"); commit != "" {
+		t.Fatalf("unexpected commit %s", commit)
+	}
+}
+
+func TestCommitsMatch(t *testing.T) {
+	if !commitsMatch("6a4a7e5d", "6a4a7e5d2f3c") {
+		t.Fatal("abbreviated commit does not match")
+	}
+	if !commitsMatch("6A4A7E5D", "6a4a7e5d2f3c") {
+		t.Fatal("upper case commit does not match")
+	}
+	if commitsMatch("6a4a7e5d", "7a4a7e5d") {
+		t.Fatal("different commits match")
+	}
+}
+
+func TestCheckSuaveSol(t *testing.T) {
+	if err := CheckSuaveSol("../../src/suavelib/Suave.sol"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "Suave.sol")
+	if err := os.WriteFile(path, []byte("library Suave {}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckSuaveSol(path); err == nil {
+		t.Fatal("expected an error for a different Suave.sol")
+	}
+}
+
+func TestPackUnpack_Enum(t *testing.T) {
+	data, err := PackPrivateKeyGenInputs(CryptoSignatureBLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cryptoType, err := UnpackPrivateKeyGenInputs(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cryptoType != CryptoSignatureBLS {
+		t.Fatalf("unexpected crypto signature %d", cryptoType)
+	}
+}
+
+func TestPrecompiles(t *testing.T) {
+	for addr, name := range PrecompileNames {
+		p, ok := Precompiles[addr]
+		if !ok || p.Name != name {
+			t.Fatalf("precompile %s is not in the registry", name)
+		}
+	}
+
+	data, _ := PackRandomBytesInputs(32)
+	values, err := Precompiles[RandomBytesAddr].Inputs.Unpack(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0] != uint8(32) {
+		t.Fatalf("unexpected values %v", values)
+	}
+}
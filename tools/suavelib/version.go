@@ -0,0 +1,60 @@
+package suavelib
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ParseSuaveGethCommit returns the commit in the output of 'suave-geth version'
+// or an empty string if the binary was built without the commit information.
+func ParseSuaveGethCommit(output string) string {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if commit, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "Git Commit:"); ok {
+			return strings.TrimSpace(commit)
+		}
+	}
+	return ""
+}
+
+// CheckSuaveGeth checks that the suave-geth binary was built from the same commit
+// that Suave.sol was synced from. The check is skipped if any of the commits is not known.
+func CheckSuaveGeth(binary string) error {
+	output, err := exec.Command(binary, "version").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to run '%s version': %v, %s", binary, err, string(output))
+	}
+
+	commit := ParseSuaveGethCommit(string(output))
+	if commit == "" || SuaveGethCommit == "" {
+		return nil
+	}
+	if !commitsMatch(commit, SuaveGethCommit) {
+		return fmt.Errorf("suave-geth is built from commit %s but Suave.sol was synced from commit %s", commit, SuaveGethCommit)
+	}
+	return nil
+}
+
+// CheckSuaveSol checks that a Suave.sol file is the one used to generate the bindings.
+func CheckSuaveSol(path string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(src)
+	if found := hex.EncodeToString(hash[:]); found != SuaveSolHash {
+		return fmt.Errorf("%s has hash %s but the generated code is from %s, run forge-gen with the --apply flag", path, found, SuaveSolHash)
+	}
+	return nil
+}
+
+// commitsMatch compares two commits that might be abbreviated.
+func commitsMatch(a, b string) bool {
+	n := min(len(a), len(b))
+	return strings.EqualFold(a[:n], b[:n])
+}